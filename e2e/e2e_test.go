@@ -5,6 +5,7 @@ package e2e
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
@@ -49,6 +50,18 @@ func runRollbar(t *testing.T, args ...string) (string, string, error) {
 	return stdout.String(), stderr.String(), err
 }
 
+// decodeDocument parses a JSON output document and decodes its value under key
+func decodeDocument(stdout, key string, v interface{}) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		return err
+	}
+	if string(doc["schema"]) != `"rollbar-cli/v1"` {
+		return fmt.Errorf("unexpected schema %s", doc["schema"])
+	}
+	return json.Unmarshal(doc[key], v)
+}
+
 func TestE2E_Whoami(t *testing.T) {
 	stdout, stderr, err := runRollbar(t, "whoami")
 	if err != nil {
@@ -69,9 +82,9 @@ func TestE2E_ItemsList(t *testing.T) {
 		t.Fatalf("items failed: %v\nstderr: %s", err, stderr)
 	}
 
-	// Should be valid JSON array
+	// Should be a valid items document
 	var items []map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+	if err := decodeDocument(stdout, "items", &items); err != nil {
 		t.Fatalf("failed to parse JSON output: %v\noutput: %s", err, stdout)
 	}
 
@@ -96,7 +109,7 @@ func TestE2E_ItemsWithFilters(t *testing.T) {
 			}
 
 			var items []map[string]interface{}
-			if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+			if err := decodeDocument(stdout, "items", &items); err != nil {
 				t.Fatalf("failed to parse JSON: %v", err)
 			}
 			t.Logf("Found %d items with filter %s", len(items), tt.name)
@@ -121,7 +134,7 @@ func TestE2E_ItemsTimeFilters(t *testing.T) {
 			}
 
 			var items []map[string]interface{}
-			if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+			if err := decodeDocument(stdout, "items", &items); err != nil {
 				t.Fatalf("failed to parse JSON: %v", err)
 			}
 			t.Logf("Found %d items with %s", len(items), tt.name)
@@ -136,7 +149,7 @@ func TestE2E_ItemsSearch(t *testing.T) {
 	}
 
 	var items []map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+	if err := decodeDocument(stdout, "items", &items); err != nil {
 		t.Fatalf("failed to parse JSON: %v", err)
 	}
 	t.Logf("Found %d items matching 'Error'", len(items))
@@ -161,7 +174,7 @@ func TestE2E_ItemsSorting(t *testing.T) {
 			}
 
 			var items []map[string]interface{}
-			if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+			if err := decodeDocument(stdout, "items", &items); err != nil {
 				t.Fatalf("failed to parse JSON: %v", err)
 			}
 			t.Logf("Sorted by %s: %d items", tt.sort, len(items))
@@ -180,7 +193,7 @@ func TestE2E_ItemDetail(t *testing.T) {
 	}
 
 	var item map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &item); err != nil {
+	if err := decodeDocument(stdout, "item", &item); err != nil {
 		t.Fatalf("failed to parse JSON: %v", err)
 	}
 
@@ -205,7 +218,7 @@ func TestE2E_Occurrences(t *testing.T) {
 	}
 
 	var instances []map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &instances); err != nil {
+	if err := decodeDocument(stdout, "occurrences", &instances); err != nil {
 		t.Fatalf("failed to parse JSON: %v", err)
 	}
 
@@ -306,7 +319,7 @@ func TestE2E_Resolve(t *testing.T) {
 	}
 
 	var item map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &item); err != nil {
+	if err := decodeDocument(stdout, "item", &item); err != nil {
 		t.Fatalf("failed to parse item JSON: %v", err)
 	}
 
@@ -329,7 +342,7 @@ func TestE2E_Resolve(t *testing.T) {
 		t.Fatalf("failed to get item after resolve: %v\nstderr: %s", err, stderr)
 	}
 
-	if err := json.Unmarshal([]byte(stdout), &item); err != nil {
+	if err := decodeDocument(stdout, "item", &item); err != nil {
 		t.Fatalf("failed to parse item JSON: %v", err)
 	}
 
@@ -349,7 +362,7 @@ func TestE2E_ResolveMultiple(t *testing.T) {
 	}
 
 	var items []map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &items); err != nil {
+	if err := decodeDocument(stdout, "items", &items); err != nil {
 		t.Fatalf("failed to parse items JSON: %v", err)
 	}
 
@@ -379,7 +392,7 @@ func TestE2E_ResolveMultiple(t *testing.T) {
 		}
 
 		var item map[string]interface{}
-		if err := json.Unmarshal([]byte(stdout), &item); err != nil {
+		if err := decodeDocument(stdout, "item", &item); err != nil {
 			t.Fatalf("failed to parse item JSON: %v", err)
 		}
 
@@ -423,7 +436,7 @@ func TestE2E_OccurrenceDetail(t *testing.T) {
 	}
 
 	var instances []map[string]interface{}
-	if err := json.Unmarshal([]byte(stdout), &instances); err != nil {
+	if err := decodeDocument(stdout, "occurrences", &instances); err != nil {
 		t.Fatalf("failed to parse JSON: %v", err)
 	}
 
@@ -22,6 +22,10 @@ type Client struct {
 	httpClient  *http.Client
 	accessToken string
 	baseURL     string
+	limiter     *rateLimiter
+
+	// Redact, when set, masks every occurrence the client returns
+	Redact func(*Instance)
 }
 
 // NewClient creates a new Rollbar API client
@@ -32,6 +36,7 @@ func NewClient(accessToken string) *Client {
 		},
 		accessToken: accessToken,
 		baseURL:     BaseURL,
+		limiter:     newRateLimiter(),
 	}
 }
 
@@ -75,11 +80,13 @@ func (c *Client) doRequest(method, path string, query url.Values) ([]byte, error
 	req.Header.Set("X-Rollbar-Access-Token", c.accessToken)
 	req.Header.Set("Accept", "application/json")
 
+	c.limiter.wait()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.limiter.observe(resp.Header)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -130,11 +137,13 @@ func (c *Client) doRequestWithBody(method, path string, query url.Values, payloa
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	c.limiter.wait()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.limiter.observe(resp.Header)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -179,33 +188,79 @@ func (c *Client) UpdateItemStatus(id int64, status string) (*Item, error) {
 	return &resp.Result, nil
 }
 
-// ItemsOptions configures the list items request
+// AssignItem assigns an item to a Rollbar user by user ID (0 unassigns)
+func (c *Client) AssignItem(id int64, userID int64) (*Item, error) {
+	payload := map[string]interface{}{
+		"assigned_user_id": userID,
+	}
+	if userID == 0 {
+		payload["assigned_user_id"] = nil
+	}
+
+	body, err := c.doRequestWithBody("PATCH", fmt.Sprintf("/item/%d", id), nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ItemResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	resp.Result.ComputeFields()
+	return &resp.Result, nil
+}
+
+// Page sizes of the list endpoints. A full page means the next page may have
+// more results.
+const (
+	ItemsPageSize     = 100
+	InstancesPageSize = 20
+)
+
+// ItemsOptions configures the list items request.
+// The json and description tags describe it as a tool input schema (see internal/mcp).
 type ItemsOptions struct {
-	Status      string // active, resolved, muted, any
-	Level       string // debug, info, warning, error, critical (comma-separated)
-	Environment string
-	Query       string    // Text search
-	DateFrom    time.Time // Filter by last_occurrence_timestamp >= date
-	DateTo      time.Time // Filter by last_occurrence_timestamp <= date
-	Page        int
-	Limit       int // Items per page (max 100)
+	Status      string    `json:"status,omitempty" description:"Filter by status" enum:"active,resolved,muted,any"`
+	Level       string    `json:"level,omitempty" description:"Filter by level: debug, info, warning, error, critical (comma-separated)"`
+	Environment string    `json:"environment,omitempty" description:"Filter by environment"`
+	Query       string    `json:"query,omitempty" description:"Text search in item titles"`
+	DateFrom    time.Time `json:"date_from,omitempty" description:"Only items whose last occurrence is at or after this time (RFC 3339)"`
+	DateTo      time.Time `json:"date_to,omitempty" description:"Only items whose last occurrence is at or before this time (RFC 3339)"`
+	Page        int       `json:"page,omitempty" description:"Page number, starting at 1"`
+	Limit       int       `json:"limit,omitempty" description:"Items per page (max 100)"`
 }
 
 // ListItems returns items matching the given options
 func (c *Client) ListItems(opts ItemsOptions) ([]Item, int, error) {
 	// Handle comma-separated levels by making multiple requests
 	if opts.Level != "" && strings.Contains(opts.Level, ",") {
-		return c.listItemsMultiLevel(opts)
+		items, _, err := c.listItemsMultiLevel(opts)
+		return items, 1, err
 	}
 
 	return c.listItemsSingleLevel(opts)
 }
 
-// listItemsMultiLevel handles comma-separated level filters by making multiple API calls
-func (c *Client) listItemsMultiLevel(opts ItemsOptions) ([]Item, int, error) {
+// ListItemsPage is ListItems that also reports whether the next page may have
+// more items. A comma-separated level filter merges one page per level, so it
+// has more when any of the levels returned a full page.
+func (c *Client) ListItemsPage(opts ItemsOptions) ([]Item, bool, error) {
+	if opts.Level != "" && strings.Contains(opts.Level, ",") {
+		return c.listItemsMultiLevel(opts)
+	}
+
+	items, _, err := c.listItemsSingleLevel(opts)
+	return items, len(items) >= ItemsPageSize, err
+}
+
+// listItemsMultiLevel handles comma-separated level filters by making multiple
+// API calls, and reports whether any level returned a full page
+func (c *Client) listItemsMultiLevel(opts ItemsOptions) ([]Item, bool, error) {
 	levels := strings.Split(opts.Level, ",")
 	seen := make(map[int64]bool)
 	var allItems []Item
+	hasMore := false
 
 	for _, level := range levels {
 		levelOpts := opts
@@ -213,8 +268,9 @@ func (c *Client) listItemsMultiLevel(opts ItemsOptions) ([]Item, int, error) {
 
 		items, _, err := c.listItemsSingleLevel(levelOpts)
 		if err != nil {
-			return nil, 0, err
+			return nil, false, err
 		}
+		hasMore = hasMore || len(items) >= ItemsPageSize
 
 		// Deduplicate by item ID
 		for _, item := range items {
@@ -225,7 +281,7 @@ func (c *Client) listItemsMultiLevel(opts ItemsOptions) ([]Item, int, error) {
 		}
 	}
 
-	return allItems, 1, nil
+	return allItems, hasMore, nil
 }
 
 // listItemsSingleLevel makes a single API call for items
@@ -245,10 +301,10 @@ func (c *Client) listItemsSingleLevel(opts ItemsOptions) ([]Item, int, error) {
 		q.Set("query", opts.Query)
 	}
 	if !opts.DateFrom.IsZero() {
-		q.Set("date_from", opts.DateFrom.Format("2006-01-02T15:04:05"))
+		q.Set("date_from", opts.DateFrom.UTC().Format("2006-01-02T15:04:05"))
 	}
 	if !opts.DateTo.IsZero() {
-		q.Set("date_to", opts.DateTo.Format("2006-01-02T15:04:05"))
+		q.Set("date_to", opts.DateTo.UTC().Format("2006-01-02T15:04:05"))
 	}
 	if opts.Page > 0 {
 		q.Set("page", strconv.Itoa(opts.Page))
@@ -306,8 +362,8 @@ func (c *Client) GetItemByCounter(counter int) (*Item, error) {
 
 // InstancesOptions configures the list instances request
 type InstancesOptions struct {
-	ItemID int64 // If set, list instances for this item only
-	Page   int
+	ItemID int64 `json:"item_id,omitempty" description:"Internal item ID; lists occurrences of this item only"`
+	Page   int   `json:"page,omitempty" description:"Page number, starting at 1"`
 }
 
 // ListInstances returns instances (occurrences) for an item or all items
@@ -336,6 +392,9 @@ func (c *Client) ListInstances(opts InstancesOptions) ([]Instance, error) {
 
 	for i := range resp.Result.Instances {
 		resp.Result.Instances[i].ComputeFields()
+		if c.Redact != nil {
+			c.Redact(&resp.Result.Instances[i])
+		}
 	}
 
 	return resp.Result.Instances, nil
@@ -354,9 +413,36 @@ func (c *Client) GetInstance(id int64) (*Instance, error) {
 	}
 
 	resp.Result.ComputeFields()
+	if c.Redact != nil {
+		c.Redact(&resp.Result)
+	}
 	return &resp.Result, nil
 }
 
+// ListDeploys returns a page of deploys, most recent first
+func (c *Client) ListDeploys(page int) ([]Deploy, error) {
+	q := url.Values{}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+
+	body, err := c.doRequest("GET", "/deploys", q)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp DeploysResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	for i := range resp.Result.Deploys {
+		resp.Result.Deploys[i].ComputeFields()
+	}
+
+	return resp.Result.Deploys, nil
+}
+
 // ProjectInfo represents basic project info for whoami
 type ProjectInfo struct {
 	ID   int    `json:"id"`
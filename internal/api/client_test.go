@@ -182,3 +182,155 @@ func TestDoRequestWithBody(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+func TestAssignItem(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID int64
+		want   interface{}
+	}{
+		{"assign to user", 42, float64(42)},
+		{"unassign", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PATCH" {
+					t.Errorf("expected PATCH request, got %s", r.Method)
+				}
+				if r.URL.Path != "/item/123" {
+					t.Errorf("expected path /item/123, got %s", r.URL.Path)
+				}
+
+				var payload map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("failed to decode request body: %v", err)
+				}
+				if got, ok := payload["assigned_user_id"]; !ok || got != tt.want {
+					t.Errorf("expected assigned_user_id %v, got %v", tt.want, got)
+				}
+
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"err": 0,
+					"result": map[string]interface{}{
+						"id":               123,
+						"counter":          7,
+						"assigned_user_id": tt.userID,
+					},
+				})
+			}))
+			defer server.Close()
+
+			client := NewClient("test-token")
+			client.baseURL = server.URL
+
+			item, err := client.AssignItem(123, tt.userID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if item.AssignedUserID != tt.userID {
+				t.Errorf("expected AssignedUserID %d, got %d", tt.userID, item.AssignedUserID)
+			}
+		})
+	}
+}
+
+func TestListDeploys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deploys" {
+			t.Errorf("expected path /deploys, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("page"); got != "2" {
+			t.Errorf("expected page 2, got %q", got)
+		}
+		_, _ = w.Write([]byte(`{"err":0,"result":{"page":2,"deploys":[
+			{"id":9,"environment":"production","revision":"abc1234","start_time":1705312800,"status":"succeeded"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token")
+	client.baseURL = server.URL
+
+	deploys, err := client.ListDeploys(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deploys) != 1 {
+		t.Fatalf("expected 1 deploy, got %d", len(deploys))
+	}
+	if deploys[0].Revision != "abc1234" || deploys[0].Environment != "production" {
+		t.Errorf("unexpected deploy: %+v", deploys[0])
+	}
+	if deploys[0].StartTime.Unix() != 1705312800 {
+		t.Errorf("expected StartTime to be computed, got %v", deploys[0].StartTime)
+	}
+}
+
+func TestListItemsPageHasMore(t *testing.T) {
+	// Items per level; only "error" fills a page
+	counts := map[string]int{"error": ItemsPageSize, "critical": 60, "warning": 50}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level := r.URL.Query().Get("level")
+		items := make([]map[string]interface{}, counts[level])
+		for i := range items {
+			items[i] = map[string]interface{}{"id": len(level)*1000 + i, "level": level}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"err": 0, "result": map[string]interface{}{"page": 1, "items": items}})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token")
+	client.baseURL = server.URL
+
+	tests := []struct {
+		level     string
+		wantItems int
+		wantMore  bool
+	}{
+		{"critical", 60, false},
+		{"error", ItemsPageSize, true},
+		{"critical,warning", 110, false},
+		{"critical,error", 160, true},
+	}
+	for _, tt := range tests {
+		items, hasMore, err := client.ListItemsPage(ItemsOptions{Level: tt.level})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.level, err)
+		}
+		if len(items) != tt.wantItems || hasMore != tt.wantMore {
+			t.Errorf("%s: got %d items, has more %v; want %d, %v", tt.level, len(items), hasMore, tt.wantItems, tt.wantMore)
+		}
+	}
+}
+
+func TestClientRedactsInstances(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instance := `{"id":7,"data":{"request":{"headers":{"Cookie":"session=abc"}}}}`
+		if r.URL.Path == "/instance/7" {
+			_, _ = w.Write([]byte(`{"err":0,"result":` + instance + `}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"err":0,"result":{"instances":[` + instance + `]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token")
+	client.baseURL = server.URL
+	client.Redact = func(inst *Instance) { inst.Data.Request.Headers["Cookie"] = "[REDACTED]" }
+
+	inst, err := client.GetInstance(7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	instances, err := client.ListInstances(InstancesOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, got := range []*Instance{inst, &instances[0]} {
+		if cookie := got.Data.Request.Headers["Cookie"]; cookie != "[REDACTED]" {
+			t.Errorf("expected the Cookie header to be redacted, got %q", cookie)
+		}
+	}
+}
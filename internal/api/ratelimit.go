@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces out requests and pauses when Rollbar reports that the
+// token's rate limit is exhausted. It is shared by every request made through
+// a Client, so concurrent users of one Client share one budget.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Minimum spacing between requests (0 = unlimited)
+	next     time.Time     // Earliest time the next request may start
+
+	remaining int       // From X-Rate-Limit-Remaining (-1 = unknown)
+	reset     time.Time // From X-Rate-Limit-Reset
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{remaining: -1}
+}
+
+// wait blocks until the next request is allowed
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if l.next.After(start) {
+		start = l.next
+	}
+	if l.remaining == 0 && l.reset.After(start) {
+		start = l.reset
+		l.remaining = -1
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := start.Sub(now); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// observe records the rate limit headers from a response
+func (l *rateLimiter) observe(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("X-Rate-Limit-Remaining"))
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remaining = remaining
+	if reset, err := strconv.ParseInt(h.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+		l.reset = time.Unix(reset, 0)
+	}
+}
+
+// SetRateLimit limits the client to perMinute requests per minute (0 = unlimited).
+// Requests always pause when Rollbar reports the token's limit is exhausted.
+func (c *Client) SetRateLimit(perMinute int) {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	if perMinute <= 0 {
+		c.limiter.interval = 0
+		return
+	}
+	c.limiter.interval = time.Minute / time.Duration(perMinute)
+}
+
+// RateLimitRemaining returns the remaining request budget last reported by
+// Rollbar, or -1 if no response has reported it yet
+func (c *Client) RateLimitRemaining() int {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	return c.limiter.remaining
+}
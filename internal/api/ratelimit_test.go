@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSpacing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"err":0,"result":{"id":1,"name":"test"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token")
+	client.baseURL = server.URL
+	client.SetRateLimit(600) // One request every 100ms
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetProjectInfo(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected requests to be spaced out, took %v", elapsed)
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	// Rollbar reports the reset time in whole seconds
+	reset := time.Unix(time.Now().Add(time.Second).Unix(), 0)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		remaining := "0"
+		if calls > 1 {
+			remaining = "4999"
+		}
+		w.Header().Set("X-Rate-Limit-Remaining", remaining)
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = w.Write([]byte(`{"err":0,"result":{"id":1,"name":"test"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-token")
+	client.baseURL = server.URL
+
+	if client.RateLimitRemaining() != -1 {
+		t.Errorf("expected unknown remaining budget before any request")
+	}
+	if _, err := client.GetProjectInfo(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.RateLimitRemaining() != 0 {
+		t.Errorf("expected remaining 0, got %d", client.RateLimitRemaining())
+	}
+
+	// The second request must wait for the reset time
+	if _, err := client.GetProjectInfo(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Now().Before(reset) {
+		t.Error("expected the second request to wait for the rate limit reset")
+	}
+	if client.RateLimitRemaining() != 4999 {
+		t.Errorf("expected remaining 4999, got %d", client.RateLimitRemaining())
+	}
+}
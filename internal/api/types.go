@@ -111,6 +111,8 @@ type Item struct {
 	ProjectID                int       `json:"project_id"`
 	Hash                     string    `json:"hash"`
 	UniqueOccurrences        int       `json:"unique_occurrences"`
+	AssignedUserID           int64     `json:"assigned_user_id"`
+	Owners                   []string  `json:"owners,omitempty"` // From local CODEOWNERS; set by 'rollbar items --owners'
 }
 
 // LevelToString converts numeric level to string
@@ -207,6 +209,20 @@ type InstanceData struct {
 	CodeVersion string                 `json:"code_version"`
 }
 
+// ResolvedCodeVersion returns the code version recorded for an occurrence,
+// falling back to the server and JavaScript client versions
+func (d *InstanceData) ResolvedCodeVersion() string {
+	switch {
+	case d.CodeVersion != "":
+		return d.CodeVersion
+	case d.Server != nil && d.Server.CodeVersion != "":
+		return d.Server.CodeVersion
+	case d.Client != nil && d.Client.JavaScript != nil:
+		return d.Client.JavaScript.CodeVersion
+	}
+	return ""
+}
+
 // Body contains the error details
 type Body struct {
 	Trace       *Trace       `json:"trace"`
@@ -215,6 +231,18 @@ type Body struct {
 	CrashReport *CrashReport `json:"crash_report"`
 }
 
+// Traces returns the exception chain, outermost exception first and innermost
+// cause last. A body with a single trace returns a one-element chain.
+func (b *Body) Traces() []Trace {
+	if len(b.TraceChain) > 0 {
+		return b.TraceChain
+	}
+	if b.Trace != nil {
+		return []Trace{*b.Trace}
+	}
+	return nil
+}
+
 // Trace represents a stack trace
 type Trace struct {
 	Exception Exception `json:"exception"`
@@ -237,6 +265,15 @@ type Frame struct {
 	Code     string       `json:"code"`
 	Context  FrameContext `json:"context"`
 	Argspec  []string     `json:"argspec"`
+	Blame    *FrameBlame  `json:"blame,omitempty"` // Local git blame, not part of the Rollbar payload
+}
+
+// FrameBlame is the last local commit that touched a frame's line
+type FrameBlame struct {
+	Commit  string    `json:"commit"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
 }
 
 // FrameContext contains code context around the error line
@@ -310,6 +347,37 @@ func (j JSONString) String() string {
 	return string(j)
 }
 
+// DeploysResponse is the response from the deploys endpoint
+type DeploysResponse struct {
+	Err    int           `json:"err"`
+	Result DeploysResult `json:"result"`
+}
+
+// DeploysResult is the result object from the deploys endpoint
+type DeploysResult struct {
+	Deploys []Deploy `json:"deploys"`
+	Page    int      `json:"page"`
+}
+
+// Deploy represents a deploy recorded in Rollbar
+type Deploy struct {
+	ID            int64     `json:"id"`
+	Environment   string    `json:"environment"`
+	Revision      string    `json:"revision"`
+	LocalUsername string    `json:"local_username"`
+	Comment       string    `json:"comment"`
+	Status        string    `json:"status"`
+	StartTimeUnix int64     `json:"start_time"`
+	StartTime     time.Time `json:"-"` // Computed
+}
+
+// ComputeFields populates computed fields from raw data
+func (d *Deploy) ComputeFields() {
+	if d.StartTimeUnix > 0 {
+		d.StartTime = time.Unix(d.StartTimeUnix, 0)
+	}
+}
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Err     int    `json:"err"`
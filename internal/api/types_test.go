@@ -118,3 +118,33 @@ func TestInstanceComputeFields(t *testing.T) {
 		t.Errorf("expected Time to be close to now, got %v ago", diff)
 	}
 }
+
+func TestBodyTraces(t *testing.T) {
+	outer := Trace{Exception: Exception{Class: "RuntimeError"}}
+	cause := Trace{Exception: Exception{Class: "KeyError"}}
+
+	tests := []struct {
+		name string
+		body Body
+		want []string
+	}{
+		{"empty", Body{}, nil},
+		{"single trace", Body{Trace: &outer}, []string{"RuntimeError"}},
+		{"chain", Body{TraceChain: []Trace{outer, cause}}, []string{"RuntimeError", "KeyError"}},
+		{"chain wins over trace", Body{Trace: &cause, TraceChain: []Trace{outer, cause}}, []string{"RuntimeError", "KeyError"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traces := tt.body.Traces()
+			if len(traces) != len(tt.want) {
+				t.Fatalf("expected %d traces, got %d", len(tt.want), len(traces))
+			}
+			for i, class := range tt.want {
+				if traces[i].Exception.Class != class {
+					t.Errorf("trace %d: expected %s, got %s", i, class, traces[i].Exception.Class)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// Check exit codes, following the Nagios/Sensu plugin convention
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+// maxCheckPages caps how many occurrence pages are read per item when counting
+// occurrences inside the time window
+const maxCheckPages = 10
+
+var checkStatusNames = map[int]string{
+	checkOK:       "OK",
+	checkWarning:  "WARNING",
+	checkCritical: "CRITICAL",
+	checkUnknown:  "UNKNOWN",
+}
+
+// checkThresholds holds the limits for a check. A negative value disables the limit.
+type checkThresholds struct {
+	WarnItems       int `json:"warn_items"`
+	MaxItems        int `json:"max_items"`
+	WarnOccurrences int `json:"warn_occurrences"`
+	MaxOccurrences  int `json:"max_occurrences"`
+}
+
+// checkItem is an item that contributed to the check result
+type checkItem struct {
+	Counter     int    `json:"counter"`
+	Title       string `json:"title"`
+	Level       string `json:"level"`
+	Environment string `json:"environment"`
+	Occurrences int    `json:"occurrences"`
+}
+
+// checkResult is the outcome of a check, also written by --json-out
+type checkResult struct {
+	Status      string `json:"status"`
+	Code        int    `json:"code"`
+	Summary     string `json:"summary"`
+	Items       int    `json:"items"`
+	Occurrences int    `json:"occurrences"`
+	// Truncated lists the items whose window count stopped at maxCheckPages,
+	// making Occurrences a lower bound
+	Truncated  []int           `json:"truncated"`
+	Thresholds checkThresholds `json:"thresholds"`
+	Offending  []checkItem     `json:"offending"`
+	CheckedAt  time.Time       `json:"checked_at"`
+}
+
+// evaluateCheck compares the matched items against the thresholds.
+// counts holds the number of occurrences per item ID inside the check window,
+// and truncated the item IDs whose count is only a lower bound. An occurrence
+// threshold that a lower bound does not exceed cannot be evaluated, and the
+// result is UNKNOWN unless a critical threshold is already exceeded.
+func evaluateCheck(items []api.Item, counts map[int64]int, truncated map[int64]bool, th checkThresholds) checkResult {
+	res := checkResult{
+		Items:      len(items),
+		Truncated:  []int{},
+		Thresholds: th,
+		Offending:  []checkItem{},
+		CheckedAt:  time.Now().UTC(),
+	}
+
+	for _, item := range items {
+		n := counts[item.ID.Int64()]
+		res.Occurrences += n
+		if truncated[item.ID.Int64()] {
+			res.Truncated = append(res.Truncated, item.Counter)
+		}
+		res.Offending = append(res.Offending, checkItem{
+			Counter:     item.Counter,
+			Title:       item.Title,
+			Level:       item.LevelString,
+			Environment: item.Environment,
+			Occurrences: n,
+		})
+	}
+	sort.SliceStable(res.Offending, func(i, j int) bool {
+		return res.Offending[i].Occurrences > res.Offending[j].Occurrences
+	})
+
+	exceeds := func(value, limit int) bool {
+		return limit >= 0 && value > limit
+	}
+
+	more := ""
+	if len(res.Truncated) > 0 {
+		more = "+"
+	}
+
+	var reasons []string
+	switch {
+	case exceeds(res.Items, th.MaxItems) || exceeds(res.Occurrences, th.MaxOccurrences):
+		res.Code = checkCritical
+	case exceeds(res.Items, th.WarnItems) || exceeds(res.Occurrences, th.WarnOccurrences):
+		res.Code = checkWarning
+	default:
+		res.Code = checkOK
+	}
+
+	if exceeds(res.Items, th.MaxItems) {
+		reasons = append(reasons, fmt.Sprintf("items %d > %d", res.Items, th.MaxItems))
+	} else if exceeds(res.Items, th.WarnItems) {
+		reasons = append(reasons, fmt.Sprintf("items %d > %d", res.Items, th.WarnItems))
+	}
+	if exceeds(res.Occurrences, th.MaxOccurrences) {
+		reasons = append(reasons, fmt.Sprintf("occurrences %d%s > %d", res.Occurrences, more, th.MaxOccurrences))
+	} else if exceeds(res.Occurrences, th.WarnOccurrences) {
+		reasons = append(reasons, fmt.Sprintf("occurrences %d%s > %d", res.Occurrences, more, th.WarnOccurrences))
+	}
+
+	// A higher count could still cross an occurrence threshold
+	if len(res.Truncated) > 0 && res.Code != checkCritical &&
+		(th.MaxOccurrences >= 0 || (th.WarnOccurrences >= 0 && res.Code == checkOK)) {
+		res.Code = checkUnknown
+		counters := make([]string, len(res.Truncated))
+		for i, c := range res.Truncated {
+			counters[i] = fmt.Sprintf("#%d", c)
+		}
+		reasons = append(reasons, fmt.Sprintf("occurrences of %s counted only up to %d each",
+			strings.Join(counters, ", "), maxCheckPages*api.InstancesPageSize))
+	}
+
+	if res.Code == checkOK {
+		// Nothing is offending when the check passes
+		res.Offending = []checkItem{}
+	}
+
+	res.Status = checkStatusNames[res.Code]
+	summary := fmt.Sprintf("%s - %d items, %d%s occurrences", res.Status, res.Items, res.Occurrences, more)
+	if len(reasons) > 0 {
+		summary += " (" + strings.Join(reasons, ", ") + ")"
+	}
+	res.Summary = summary + " | " + perfData(res)
+
+	return res
+}
+
+// perfData renders Nagios performance data: label=value;warn;crit
+func perfData(res checkResult) string {
+	limit := func(v int) string {
+		if v < 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d", v)
+	}
+	return fmt.Sprintf("items=%d;%s;%s occurrences=%d;%s;%s",
+		res.Items, limit(res.Thresholds.WarnItems), limit(res.Thresholds.MaxItems),
+		res.Occurrences, limit(res.Thresholds.WarnOccurrences), limit(res.Thresholds.MaxOccurrences))
+}
+
+// countOccurrencesInWindow counts an item's occurrences between from and to
+// (either may be zero) by paging through its instances, newest first. It
+// reports whether it stopped at maxCheckPages before reaching from.
+func countOccurrencesInWindow(client *api.Client, itemID int64, from, to time.Time) (int, bool, error) {
+	count := 0
+	for page := 1; page <= maxCheckPages; page++ {
+		instances, err := client.ListInstances(api.InstancesOptions{ItemID: itemID, Page: page})
+		if err != nil {
+			return 0, false, err
+		}
+		for _, inst := range instances {
+			if !from.IsZero() && inst.Time.Before(from) {
+				return count, false, nil
+			}
+			if !to.IsZero() && inst.Time.After(to) {
+				continue
+			}
+			count++
+		}
+		if len(instances) < api.InstancesPageSize {
+			return count, false, nil
+		}
+	}
+	return count, true, nil
+}
+
+// isMachineFormat reports whether format is meant for parsing rather than reading
+func isMachineFormat(format output.Format) bool {
+	switch format {
+	case output.FormatTable, output.FormatCompact, output.FormatMarkdown:
+		return false
+	}
+	return true
+}
+
+func newCheckCmd() *cobra.Command {
+	var (
+		filters    itemFilters
+		thresholds checkThresholds
+		jsonOut    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check item thresholds and exit with a monitoring status code",
+		Long: `Check Rollbar state against thresholds, for CI pipelines and monitoring cron jobs.
+
+Prints a one-line summary (with Nagios performance data) followed by the
+offending items, and exits with a Nagios/Sensu-style status code. With -o json,
+ndjson, csv, tsv or a template the summary goes to stderr instead, so stdout
+stays parseable:
+
+  0  OK        no threshold exceeded
+  1  WARNING   a --warn-* threshold exceeded
+  2  CRITICAL  a --max-* threshold exceeded
+  3  UNKNOWN   the check could not be performed (e.g. API error)
+
+Every page of matching items is checked, so --page is not accepted. When
+--since, --from or --to is set, occurrences are counted inside that window;
+otherwise each item's total occurrence count is used. Window counts read at
+most 200 occurrences per item; when that leaves an occurrence threshold
+undecided the result is UNKNOWN and the items are listed under "truncated" in
+--json-out. Thresholds of -1 are disabled.
+
+Examples:
+  rollbar check --level critical --env production --since 15m --max-items 0
+  rollbar check --since 1h --warn-occurrences 20 --max-occurrences 50
+  rollbar check --since 15m --max-items 0 --json-out result.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return &ExitError{Code: checkUnknown, Err: err}
+			}
+			if cmd.Flags().Changed("page") {
+				return &ExitError{Code: checkUnknown, Err: fmt.Errorf("--page is not supported; check reads every page of items")}
+			}
+
+			opts, err := filters.options()
+			if err != nil {
+				return &ExitError{Code: checkUnknown, Err: err}
+			}
+
+			client := newClient(false)
+			items, err := listWindowItems(client, opts)
+			if err != nil {
+				return &ExitError{Code: checkUnknown, Err: err}
+			}
+
+			counts := make(map[int64]int, len(items))
+			truncated := make(map[int64]bool)
+			windowed := !opts.DateFrom.IsZero() || !opts.DateTo.IsZero()
+			for _, item := range items {
+				if !windowed {
+					counts[item.ID.Int64()] = item.TotalOccurrences
+					continue
+				}
+				n, more, countErr := countOccurrencesInWindow(client, item.ID.Int64(), opts.DateFrom, opts.DateTo)
+				if countErr != nil {
+					return &ExitError{Code: checkUnknown, Err: fmt.Errorf("counting occurrences for #%d: %w", item.Counter, countErr)}
+				}
+				counts[item.ID.Int64()] = n
+				truncated[item.ID.Int64()] = more
+			}
+
+			res := evaluateCheck(items, counts, truncated, thresholds)
+
+			// Machine-readable output stays parseable: the summary goes to stderr
+			// and the document is written even when nothing is offending
+			machine := isMachineFormat(output.Format(outputFormat))
+			if machine {
+				fmt.Fprintln(os.Stderr, res.Summary)
+			} else {
+				fmt.Fprintln(os.Stdout, res.Summary)
+			}
+			if len(res.Offending) > 0 || machine {
+				offending := make([]api.Item, 0, len(res.Offending))
+				byCounter := make(map[int]api.Item, len(items))
+				for _, item := range items {
+					byCounter[item.Counter] = item
+				}
+				for _, o := range res.Offending {
+					offending = append(offending, byCounter[o.Counter])
+				}
+				formatter := getFormatter()
+				if err := formatter.FormatItems(os.Stdout, offending); err != nil {
+					return &ExitError{Code: checkUnknown, Err: err}
+				}
+			}
+
+			if jsonOut != "" {
+				data, err := json.MarshalIndent(res, "", "  ")
+				if err != nil {
+					return &ExitError{Code: checkUnknown, Err: err}
+				}
+				if err := os.WriteFile(jsonOut, append(data, '\n'), 0644); err != nil {
+					return &ExitError{Code: checkUnknown, Err: fmt.Errorf("writing result file: %w", err)}
+				}
+			}
+
+			if res.Code != checkOK {
+				return &ExitError{Code: res.Code}
+			}
+			return nil
+		},
+	}
+
+	filters.addFlags(cmd)
+	cmd.Flags().IntVar(&thresholds.WarnItems, "warn-items", -1, "warn when more than N items match (-1 = disabled)")
+	cmd.Flags().IntVar(&thresholds.MaxItems, "max-items", -1, "critical when more than N items match (-1 = disabled)")
+	cmd.Flags().IntVar(&thresholds.WarnOccurrences, "warn-occurrences", -1, "warn when more than N occurrences match (-1 = disabled)")
+	cmd.Flags().IntVar(&thresholds.MaxOccurrences, "max-occurrences", -1, "critical when more than N occurrences match (-1 = disabled)")
+	cmd.Flags().StringVar(&jsonOut, "json-out", "", "write the check result as JSON to this file")
+
+	return cmd
+}
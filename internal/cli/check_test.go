@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+func TestEvaluateCheck(t *testing.T) {
+	items := []api.Item{
+		{ID: api.JSONInt64(1), Counter: 10, Title: "A", LevelString: "critical"},
+		{ID: api.JSONInt64(2), Counter: 20, Title: "B", LevelString: "critical"},
+	}
+	counts := map[int64]int{1: 5, 2: 30}
+	truncated := map[int64]bool{2: true}
+
+	tests := []struct {
+		name       string
+		items      []api.Item
+		truncated  map[int64]bool
+		thresholds checkThresholds
+		wantCode   int
+		wantReason string
+	}{
+		{"no thresholds", items, nil, checkThresholds{-1, -1, -1, -1}, checkOK, ""},
+		{"no items", nil, nil, checkThresholds{-1, 0, -1, 0}, checkOK, ""},
+		{"max items exceeded", items, nil, checkThresholds{-1, 0, -1, -1}, checkCritical, "items 2 > 0"},
+		{"warn occurrences exceeded", items, nil, checkThresholds{-1, -1, 20, 50}, checkWarning, "occurrences 35 > 20"},
+		{"max occurrences exceeded", items, nil, checkThresholds{-1, -1, 20, 30}, checkCritical, "occurrences 35 > 30"},
+		{"within limits", items, nil, checkThresholds{5, 10, 100, 200}, checkOK, ""},
+		{"truncated below max", items, truncated, checkThresholds{-1, -1, -1, 500}, checkUnknown, "occurrences of #20 counted only up to 200"},
+		{"truncated past warn", items, truncated, checkThresholds{-1, -1, 20, 500}, checkUnknown, "occurrences 35+ > 20"},
+		{"truncated past max", items, truncated, checkThresholds{-1, -1, -1, 30}, checkCritical, "occurrences 35+ > 30"},
+		{"truncated without occurrence thresholds", items, truncated, checkThresholds{-1, 5, -1, -1}, checkOK, "35+ occurrences"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := evaluateCheck(tt.items, counts, tt.truncated, tt.thresholds)
+			if res.Code != tt.wantCode {
+				t.Errorf("expected code %d, got %d (%s)", tt.wantCode, res.Code, res.Summary)
+			}
+			if !strings.HasPrefix(res.Summary, checkStatusNames[tt.wantCode]+" - ") {
+				t.Errorf("expected summary to start with status, got %q", res.Summary)
+			}
+			if tt.wantReason != "" && !strings.Contains(res.Summary, tt.wantReason) {
+				t.Errorf("expected summary to contain %q, got %q", tt.wantReason, res.Summary)
+			}
+			if tt.wantCode == checkOK && len(res.Offending) != 0 {
+				t.Errorf("expected no offending items for OK, got %d", len(res.Offending))
+			}
+			if tt.wantCode != checkOK && res.Offending[0].Counter != 20 {
+				t.Errorf("expected offending items sorted by occurrences, got %+v", res.Offending)
+			}
+		})
+	}
+}
+
+func TestPerfData(t *testing.T) {
+	res := checkResult{
+		Items:       2,
+		Occurrences: 35,
+		Thresholds:  checkThresholds{WarnItems: -1, MaxItems: 0, WarnOccurrences: 20, MaxOccurrences: 50},
+	}
+	want := "items=2;;0 occurrences=35;20;50"
+	if got := perfData(res); got != want {
+		t.Errorf("perfData() = %q, want %q", got, want)
+	}
+}
+
+func TestIsMachineFormat(t *testing.T) {
+	for format, want := range map[output.Format]bool{
+		output.FormatTable:    false,
+		output.FormatCompact:  false,
+		output.FormatMarkdown: false,
+		output.FormatJSON:     true,
+		output.FormatNDJSON:   true,
+		output.FormatCSV:      true,
+		output.FormatTSV:      true,
+		"template={{.Title}}": true,
+	} {
+		if got := isMachineFormat(format); got != want {
+			t.Errorf("isMachineFormat(%q) = %v, want %v", format, got, want)
+		}
+	}
+}
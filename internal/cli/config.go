@@ -2,11 +2,16 @@ package cli
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/config"
+	"github.com/robzolkos/rollbar-cli/internal/output"
 )
 
 func newConfigCmd() *cobra.Command {
@@ -57,14 +62,52 @@ func newConfigShowCmd() *cobra.Command {
 				fmt.Fprintf(os.Stdout, "default_environment: %s\n", cfg.DefaultEnvironment)
 			}
 
+			if cfg.ProjectURL != "" {
+				fmt.Fprintf(os.Stdout, "project_url: %s\n", cfg.ProjectURL)
+			}
+
+			if cfg.Timezone != "" {
+				fmt.Fprintf(os.Stdout, "timezone: %s\n", cfg.Timezone)
+			}
+
 			fmt.Fprintf(os.Stdout, "output.format: %s\n", cfg.Output.Format)
 			fmt.Fprintf(os.Stdout, "output.color: %s\n", cfg.Output.Color)
+			fmt.Fprintf(os.Stdout, "mcp.allow_writes: %t\n", cfg.MCP.AllowWrites)
+			if cfg.Source.ContextLines != nil {
+				fmt.Fprintf(os.Stdout, "source.context_lines: %d\n", *cfg.Source.ContextLines)
+			}
+			prefixes := make([]string, 0, len(cfg.Source.PathMap))
+			for prefix := range cfg.Source.PathMap {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			for _, prefix := range prefixes {
+				fmt.Fprintf(os.Stdout, "source.path_map.%s: %s\n", prefix, cfg.Source.PathMap[prefix])
+			}
+			fmt.Fprintf(os.Stdout, "redact.disabled: %t\n", cfg.Redact.Disabled)
+			if len(cfg.Redact.Keys) > 0 {
+				fmt.Fprintf(os.Stdout, "redact.keys: %s\n", strings.Join(cfg.Redact.Keys, ","))
+			}
+			if len(cfg.Redact.Allow) > 0 {
+				fmt.Fprintf(os.Stdout, "redact.allow: %s\n", strings.Join(cfg.Redact.Allow, ","))
+			}
 
 			return nil
 		},
 	}
 }
 
+// splitList splits a comma-separated config value, dropping empty entries
+func splitList(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func getConfigSource() string {
 	if cfgFile != "" {
 		return cfgFile
@@ -99,7 +142,17 @@ func newConfigSetCmd() *cobra.Command {
 		Short: "Set a configuration value",
 		Long: `Set a configuration value in the local .rollbar.yaml file.
 
-Keys: access_token, project_id, default_environment, output.format, output.color`,
+Keys: access_token, project_id, default_environment, project_url, timezone, output.format, output.color,
+mcp.allow_writes, source.context_lines, source.path_map.<prefix>,
+redact.disabled, redact.keys, redact.allow (comma-separated lists)
+
+Examples:
+  rollbar config set source.path_map./app/ ./   # Map /app/... frames to the working tree
+  rollbar config set source.context_lines 5
+  rollbar config set output.format compact       # Used when --output isn't given
+  rollbar config set timezone UTC                # Show times in UTC instead of the local zone
+  rollbar config set project_url https://rollbar.com/acme/web  # Link tickets to Rollbar
+  rollbar config set redact.keys x-tenant-key,ssn  # Also mask these keys`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
@@ -127,12 +180,58 @@ Keys: access_token, project_id, default_environment, output.format, output.color
 				localCfg.ProjectID = id
 			case "default_environment":
 				localCfg.DefaultEnvironment = value
+			case "project_url":
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("invalid project_url: %s (expected e.g. https://rollbar.com/acme/web)", value)
+				}
+				localCfg.ProjectURL = value
+			case "timezone":
+				if _, err := config.ParseTimezone(value); err != nil {
+					return fmt.Errorf("invalid timezone: %w", err)
+				}
+				localCfg.Timezone = value
 			case "output.format":
+				if !output.Format(value).Valid() {
+					return fmt.Errorf("invalid output.format: %s (expected table, json, ndjson, csv, tsv, compact, markdown or template=TEMPLATE)", value)
+				}
 				localCfg.Output.Format = value
 			case "output.color":
+				if !validColorMode(value) {
+					return fmt.Errorf("invalid output.color: %s (expected always, never or auto)", value)
+				}
 				localCfg.Output.Color = value
+			case "mcp.allow_writes":
+				allow, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("invalid mcp.allow_writes: %s", value)
+				}
+				localCfg.MCP.AllowWrites = allow
+			case "source.context_lines":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					return fmt.Errorf("invalid source.context_lines: %s", value)
+				}
+				localCfg.Source.ContextLines = &n
+			case "redact.disabled":
+				disabled, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("invalid redact.disabled: %s", value)
+				}
+				localCfg.Redact.Disabled = disabled
+			case "redact.keys":
+				localCfg.Redact.Keys = splitList(value)
+			case "redact.allow":
+				localCfg.Redact.Allow = splitList(value)
 			default:
-				return fmt.Errorf("unknown config key: %s", key)
+				prefix, ok := strings.CutPrefix(key, "source.path_map.")
+				if !ok || prefix == "" {
+					return fmt.Errorf("unknown config key: %s", key)
+				}
+				if localCfg.Source.PathMap == nil {
+					localCfg.Source.PathMap = map[string]string{}
+				}
+				localCfg.Source.PathMap[prefix] = value
 			}
 
 			if err := localCfg.Save(config.ConfigPath()); err != nil {
@@ -2,81 +2,175 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
 	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/source"
 )
 
+// contextFormatter renders one item's context or a bundle of several
+type contextFormatter interface {
+	FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error
+	output.BundleFormatter
+}
+
 func newContextCmd() *cobra.Command {
 	var (
 		occurrences int
 		outFile     string
+		maxTokens   int
+		sourceLines int
+		sourceDir   string
+		pathMap     []string
+		blame       bool
+		details     detailFlags
+		where       bool
+		filters     itemFilters
+		top         int
 	)
 
 	cmd := &cobra.Command{
-		Use:   "context <counter>",
+		Use:   "context <counter>... | --where [filters]",
 		Short: "Generate AI context file for a bug",
 		Long: `Generate a comprehensive context file with all information needed to fix a bug.
 This is the primary command for AI agents.
 
+With several counters, or --where and the item filter flags, related items
+(e.g. everything firing during an incident) are bundled into one document: a
+shared summary with a common timeline and the hosts, code versions and people
+the items have in common, followed by each item's context. Vendor frames
+already shown for an earlier item are collapsed into a reference.
+
 Examples:
   rollbar context 123                          # Output to stdout
   rollbar context 123 --out bug-context.md     # Write to file
   rollbar context 123 --occurrences 5          # Include 5 recent occurrences
+  rollbar context 123 --max-tokens 4000        # Fit the most detail into ~4000 tokens
+  rollbar context 123 --path-map /app/=./      # Read source for /app/... frames from here
+  rollbar context 123 --blame                  # Show the last commit touching each app frame
+  rollbar context 123 --show-request           # Include headers, params and body (redacted)
+  rollbar context 101 102 103                  # Bundle related items
+  rollbar context --where --env production --since 1h --top 5
   rollbar context 123 | pbcopy                 # Copy to clipboard (macOS)`,
-		Args: cobra.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if where && len(args) > 0 {
+				return fmt.Errorf("--where selects items with the filter flags; don't also pass counters")
+			}
+			if !where && len(args) == 0 {
+				return fmt.Errorf("requires an item counter, or --where with item filters")
+			}
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cfg.Validate(); err != nil {
 				return err
 			}
-
-			counter, err := strconv.Atoi(args[0])
-			if err != nil {
-				return fmt.Errorf("invalid counter: %w", err)
+			if !where {
+				for _, name := range []string{"status", "level", "env", "query", "since", "from", "to", "page", "top"} {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s requires --where", name)
+					}
+				}
+			}
+			if maxTokens < 0 {
+				return fmt.Errorf("--max-tokens must be positive")
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(details.noRedact)
 
-			// Get item details
-			item, err := client.GetItemByCounter(counter)
-			if err != nil {
-				return err
+			var items []*api.Item
+			if where {
+				matched, err := topItems(client, &filters, top)
+				if err != nil {
+					return err
+				}
+				items = matched
+			} else {
+				for _, arg := range args {
+					counter, err := strconv.Atoi(arg)
+					if err != nil {
+						return fmt.Errorf("invalid counter: %w", err)
+					}
+					item, err := client.GetItemByCounter(counter)
+					if err != nil {
+						return err
+					}
+					items = append(items, item)
+				}
 			}
 
-			// Get recent occurrences
-			instances, err := client.ListInstances(api.InstancesOptions{
-				ItemID: item.ID.Int64(),
-			})
+			// With a token budget, keep the whole page of occurrences unless
+			// --occurrences was given and let the budget decide what fits
+			if maxTokens > 0 && !cmd.Flags().Changed("occurrences") {
+				occurrences = unlimitedOccurrences
+			}
+
+			// Fill in source lines Rollbar didn't capture from the local tree
+			lines := defaultSourceLines
+			if cfg.Source.ContextLines != nil {
+				lines = *cfg.Source.ContextLines
+			}
+			if cmd.Flags().Changed("source-lines") {
+				lines = sourceLines
+			}
+			mapping, err := parsePathMap(cfg.Source.PathMap, pathMap)
 			if err != nil {
 				return err
 			}
+			src := &contextSource{
+				client:      client,
+				occurrences: occurrences,
+				lines:       lines,
+				dir:         sourceDir,
+				resolver:    source.NewResolver(sourceDir, mapping),
+			}
+			// Annotate the latest occurrence's app frames with git blame
+			if blame {
+				repo, err := source.OpenRepo(sourceDir)
+				if err != nil {
+					return fmt.Errorf("--blame: %w", err)
+				}
+				src.repo = repo
+			}
 
-			// Limit occurrences
-			if occurrences > 0 && len(instances) > occurrences {
-				instances = instances[:occurrences]
-			} else if occurrences == 0 && len(instances) > 3 {
-				// Default to 3 occurrences
-				instances = instances[:3]
+			entries := make([]output.ContextEntry, 0, len(items))
+			for _, item := range items {
+				instances, err := src.instances(item)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, output.ContextEntry{Item: item, Instances: instances})
 			}
 
 			// Use markdown formatter for context (or JSON if specified)
-			var formatter output.Formatter
+			var formatter contextFormatter
 			switch output.Format(outputFormat) {
 			case output.FormatJSON:
-				formatter = &output.JSONFormatter{}
+				formatter = jsonFormatter()
 			case output.FormatCompact:
-				formatter = &output.CompactFormatter{}
+				formatter = &output.CompactFormatter{MaxTokens: maxTokens, Details: details.details()}
+			case output.FormatCSV, output.FormatTSV, output.FormatNDJSON:
+				formatter = newFormatter(output.Format(outputFormat)).(contextFormatter)
 			default:
-				formatter = &output.MarkdownFormatter{}
+				if tf, ok := newFormatter(output.Format(outputFormat)).(*output.TemplateFormatter); ok {
+					formatter = tf
+				} else {
+					formatter = &output.MarkdownFormatter{MaxTokens: maxTokens, Details: details.details()}
+				}
 			}
 
-			// Write to file or stdout
-			writer := os.Stdout
-			if outFile != "" {
+			// Write to file or stdout; only stdout is paged
+			var writer *os.File
+			if outFile == "" {
+				startPager(output.Format(outputFormat))
+				writer = os.Stdout
+			} else {
 				f, createErr := os.Create(outFile)
 				if createErr != nil {
 					return fmt.Errorf("creating output file: %w", createErr)
@@ -89,12 +183,141 @@ Examples:
 				}
 			}
 
-			return formatter.FormatContext(writer, item, instances)
+			if len(entries) == 1 && !where {
+				return formatter.FormatContext(writer, entries[0].Item, entries[0].Instances)
+			}
+			return formatter.FormatBundle(writer, entries)
 		},
 	}
 
 	cmd.Flags().IntVar(&occurrences, "occurrences", 3, "number of recent occurrences to include")
 	cmd.Flags().StringVar(&outFile, "out", "", "output file path")
+	cmd.Flags().IntVar(&sourceLines, "source-lines", defaultSourceLines, "lines of local source around each frame (0 disables; default from source.context_lines)")
+	cmd.Flags().StringVar(&sourceDir, "source-dir", ".", "local working tree to read source from")
+	cmd.Flags().StringArrayVar(&pathMap, "path-map", nil, "rewrite a frame path prefix to a local path, as FROM=TO (repeatable)")
+	cmd.Flags().BoolVar(&blame, "blame", false, "annotate app frames with the last local commit touching each line")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "approximate token budget; drops lower-priority detail to fit (markdown/compact)")
+	details.addFlags(cmd)
+	cmd.Flags().BoolVar(&where, "where", false, "bundle the items matching the filter flags instead of the given counters")
+	cmd.Flags().IntVar(&top, "top", 5, "with --where, bundle the N items with the most occurrences (0 = all)")
+	filters.addFlags(cmd)
 
 	return cmd
 }
+
+// unlimitedOccurrences keeps every occurrence the API returns
+const unlimitedOccurrences = -1
+
+// contextSource fetches the occurrences included in an item's context and
+// enriches them with local source and blame
+type contextSource struct {
+	client      *api.Client
+	occurrences int // Occurrences to keep (unlimitedOccurrences = all)
+	lines       int
+	dir         string
+	resolver    *source.Resolver
+	repo        *source.Repo // Set for --blame
+	warned      bool
+}
+
+func (s *contextSource) instances(item *api.Item) ([]api.Instance, error) {
+	// Get recent occurrences
+	instances, err := s.client.ListInstances(api.InstancesOptions{
+		ItemID: item.ID.Int64(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit occurrences
+	if s.occurrences > 0 && len(instances) > s.occurrences {
+		instances = instances[:s.occurrences]
+	} else if s.occurrences == 0 && len(instances) > 3 {
+		// Default to 3 occurrences
+		instances = instances[:3]
+	}
+
+	if s.lines > 0 && len(instances) > 0 {
+		enriched := 0
+		for i := range instances {
+			enriched += s.resolver.Enrich(&instances[i], s.lines)
+		}
+		if enriched > 0 && !quiet && !s.warned {
+			warnCodeVersion(s.dir, &instances[0].Data)
+			s.warned = true
+		}
+	}
+
+	if s.repo != nil && len(instances) > 0 {
+		blameFrames(s.repo, s.resolver, &instances[0])
+	}
+
+	return instances, nil
+}
+
+// topItems lists the items matching filters with the most occurrences first,
+// keeping at most top (0 = all)
+func topItems(client *api.Client, filters *itemFilters, top int) ([]*api.Item, error) {
+	opts, err := filters.options()
+	if err != nil {
+		return nil, err
+	}
+	items, _, err := client.ListItems(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items match the filters")
+	}
+
+	items = sortItems(items, "occurrences")
+	if top > 0 && len(items) > top {
+		items = items[:top]
+	}
+	matched := make([]*api.Item, len(items))
+	for i := range items {
+		matched[i] = &items[i]
+	}
+	return matched, nil
+}
+
+// defaultSourceLines is the local source context shown around each frame
+const defaultSourceLines = 3
+
+// parsePathMap merges configured path prefix rewrites with FROM=TO flag values
+func parsePathMap(configured map[string]string, flags []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(configured)+len(flags))
+	for from, to := range configured {
+		mapping[from] = to
+	}
+	for _, f := range flags {
+		from, to, ok := strings.Cut(f, "=")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid --path-map %q: expected FROM=TO", f)
+		}
+		mapping[from] = to
+	}
+	return mapping, nil
+}
+
+// warnCodeVersion warns when the local checkout is not the code version the
+// occurrence was reported from, since inlined source lines may not match
+func warnCodeVersion(dir string, data *api.InstanceData) {
+	version := data.ResolvedCodeVersion()
+	if version == "" {
+		return
+	}
+	head, matches, err := source.CheckVersion(dir, version)
+	if err != nil || matches {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: local checkout %s differs from the occurrence's code version %s; inlined source may not match\n",
+		shortSHA(head), version)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/sarif"
+	"github.com/robzolkos/rollbar-cli/internal/source"
+	"github.com/robzolkos/rollbar-cli/internal/version"
+)
+
+func newExportCmd() *cobra.Command {
+	var (
+		filters   itemFilters
+		format    string
+		sourceDir string
+		pathMap   []string
+		outFile   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export items for code scanning tools",
+		Long: `Export items as a SARIF 2.1.0 log for code scanning dashboards.
+
+Each item becomes a result located at the top app frame of its latest
+occurrence, with its level mapped to a SARIF level (critical and error to
+error, warning to warning, info and debug to note). Frames are mapped to
+repository-relative paths like 'rollbar suspects' does; frames that don't map
+keep their reported path. Items without an app frame are skipped. This makes
+one request per item.
+
+Examples:
+  rollbar export --format sarif --env production --since 7d > rollbar.sarif
+  rollbar export --format sarif --path-map /app/=./ --out rollbar.sarif
+  rollbar export --format sarif --level error,critical`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			if format != "sarif" {
+				return fmt.Errorf("unknown export format %q (available: sarif)", format)
+			}
+
+			opts, err := filters.options()
+			if err != nil {
+				return err
+			}
+			mapping, err := parsePathMap(cfg.Source.PathMap, pathMap)
+			if err != nil {
+				return err
+			}
+			// Outside a repository, paths are made relative to --source-dir
+			repo, _ := source.OpenRepo(sourceDir)
+			resolver := source.NewResolver(sourceDir, mapping)
+
+			client := newClient(false)
+			items, _, err := client.ListItems(opts)
+			if err != nil {
+				return err
+			}
+			items = sortItems(items, "occurrences")
+
+			var findings []sarif.Finding
+			for i := range items {
+				item := &items[i]
+				instances, err := client.ListInstances(api.InstancesOptions{ItemID: item.ID.Int64()})
+				if err != nil {
+					return err
+				}
+				if len(instances) == 0 {
+					continue
+				}
+				finding, ok := locateItem(repo, resolver, item, &instances[0])
+				if !ok {
+					if !quiet {
+						fmt.Fprintf(os.Stderr, "Skipping #%d: no app frame in its latest occurrence\n", item.Counter)
+					}
+					continue
+				}
+				findings = append(findings, finding)
+			}
+
+			w := io.Writer(os.Stdout)
+			if outFile != "" {
+				f, err := os.Create(outFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(sarif.New(findings, version.Version)); err != nil {
+				return err
+			}
+			if outFile != "" && !quiet {
+				fmt.Fprintf(os.Stderr, "Wrote %d results to %s\n", len(findings), outFile)
+			}
+			return nil
+		},
+	}
+
+	filters.addFlags(cmd)
+	cmd.Flags().StringVar(&format, "format", "sarif", "export format: sarif")
+	cmd.Flags().StringVar(&sourceDir, "source-dir", ".", "local repository that frame paths are made relative to")
+	cmd.Flags().StringArrayVar(&pathMap, "path-map", nil, "rewrite a frame path prefix to a local path, as FROM=TO (repeatable)")
+	cmd.Flags().StringVar(&outFile, "out", "", "output file path")
+
+	return cmd
+}
+
+// locateItem places an item at the top app frame of an occurrence, as a path
+// relative to the repository when the frame maps to one of its files
+func locateItem(repo *source.Repo, resolver *source.Resolver, item *api.Item, inst *api.Instance) (sarif.Finding, bool) {
+	body := &inst.Data.Body
+	frame := output.TopAppFrame(body)
+	if frame == nil {
+		return sarif.Finding{}, false
+	}
+
+	finding := sarif.Finding{
+		Item:   item,
+		Path:   frame.Filename,
+		Line:   frame.Lineno,
+		Column: frame.Colno,
+		Code:   frame.Code,
+	}
+	if traces := body.Traces(); len(traces) > 0 {
+		finding.Class = traces[0].Exception.Class
+	}
+
+	root := ""
+	if inst.Data.Server != nil {
+		root = inst.Data.Server.Root
+	}
+	if path, ok := resolver.Resolve(frame.Filename, root); ok && repo != nil {
+		if rel, err := repo.Rel(path); err == nil {
+			finding.Path, finding.Relative = rel, true
+			return finding, true
+		}
+	}
+	// Browser frames are URLs, not paths
+	if strings.Contains(frame.Filename, "://") {
+		return finding, true
+	}
+	if rel, ok := resolver.Rewrite(frame.Filename, root); ok && !filepath.IsAbs(rel) {
+		finding.Path, finding.Relative = filepath.ToSlash(rel), true
+	}
+	return finding, true
+}
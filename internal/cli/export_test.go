@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/source"
+)
+
+func TestLocateItem(t *testing.T) {
+	resolver := source.NewResolver(t.TempDir(), map[string]string{"/srv/app/": "./"})
+	item := &api.Item{Counter: 7}
+
+	tests := []struct {
+		name     string
+		frames   []api.Frame
+		root     string
+		ok       bool
+		path     string
+		relative bool
+	}{
+		{
+			name: "path map",
+			frames: []api.Frame{
+				{Filename: "/usr/local/bundle/gems/rack-3.0/lib/rack.rb", Lineno: 3},
+				{Filename: "/srv/app/app/models/user.rb", Lineno: 42},
+			},
+			ok: true, path: "app/models/user.rb", relative: true,
+		},
+		{
+			name:   "server root",
+			frames: []api.Frame{{Filename: "/deploy/current/lib/billing.rb", Lineno: 5}},
+			root:   "/deploy/current",
+			ok:     true, path: "lib/billing.rb", relative: true,
+		},
+		{
+			name:   "unmapped absolute path",
+			frames: []api.Frame{{Filename: "/opt/src/worker.go", Lineno: 9}},
+			ok:     true, path: "/opt/src/worker.go",
+		},
+		{
+			name:   "browser frame",
+			frames: []api.Frame{{Filename: "https://example.com/src/app.js", Lineno: 1}},
+			ok:     true, path: "https://example.com/src/app.js",
+		},
+		{
+			name:   "vendor frames only",
+			frames: []api.Frame{{Filename: "/usr/local/bundle/gems/rack-3.0/lib/rack.rb", Lineno: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := api.Instance{Data: api.InstanceData{
+				Body:   api.Body{Trace: &api.Trace{Exception: api.Exception{Class: "NoMethodError"}, Frames: tt.frames}},
+				Server: &api.Server{Root: tt.root},
+			}}
+			finding, ok := locateItem(nil, resolver, item, &inst)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if finding.Path != tt.path || finding.Relative != tt.relative {
+				t.Errorf("got %q (relative %v), want %q (relative %v)", finding.Path, finding.Relative, tt.path, tt.relative)
+			}
+			if finding.Class != "NoMethodError" || finding.Item != item {
+				t.Errorf("unexpected finding %+v", finding)
+			}
+		})
+	}
+}
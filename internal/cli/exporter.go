@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/exporter"
+)
+
+func newExporterCmd() *cobra.Command {
+	var (
+		listen    string
+		interval  time.Duration
+		opts      exporter.Options
+		rateLimit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exporter",
+		Short: "Serve Prometheus metrics for Rollbar items",
+		Long: `Run a Prometheus exporter that periodically polls active items and serves
+metrics on /metrics. Scrapes are answered from the last poll, so the Rollbar
+API is only called once per --interval regardless of scrape frequency.
+
+Metrics:
+  rollbar_active_items{level,env}               active items by level and environment
+  rollbar_item_occurrences_total{counter,level} occurrences of the --top items
+  rollbar_exporter_scrape_*                     poll health (success, duration, errors)
+  rollbar_exporter_rate_limit_remaining         remaining Rollbar API budget
+
+Examples:
+  rollbar exporter --listen :9464
+  rollbar exporter --env production --interval 2m --top 50
+  rollbar exporter --rate-limit 60           # At most 60 API requests per minute`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			// Use default environment from config if not specified
+			if opts.Environment == "" && cfg.DefaultEnvironment != "" {
+				opts.Environment = cfg.DefaultEnvironment
+			}
+
+			client := newClient(false)
+			client.SetRateLimit(rateLimit)
+
+			exp := exporter.New(client, opts, client.RateLimitRemaining)
+			stop := make(chan struct{})
+			defer close(stop)
+			go exp.Run(interval, stop, func(err error) {
+				fmt.Fprintf(os.Stderr, "Error: polling Rollbar: %v\n", err)
+			})
+
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", exp)
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" {
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprintln(w, `<html><body><h1>Rollbar Exporter</h1><p><a href="/metrics">Metrics</a></p></body></html>`)
+			})
+
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics\n", listen)
+			}
+			server := &http.Server{
+				Addr:              listen,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			return server.ListenAndServe()
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", ":9464", "address to serve metrics on")
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "how often to poll the Rollbar API")
+	cmd.Flags().StringVar(&opts.Environment, "env", "", "only export items from this environment")
+	cmd.Flags().StringVar(&opts.Level, "level", "", "only export items with these levels (comma-separated)")
+	cmd.Flags().IntVar(&opts.Pages, "pages", 10, "most item pages (100 items each) to read per poll")
+	cmd.Flags().IntVar(&opts.TopN, "top", 20, "number of items exported with per-item series")
+	cmd.Flags().IntVar(&rateLimit, "rate-limit", 0, "maximum API requests per minute (0 = only Rollbar's limit)")
+
+	return cmd
+}
@@ -38,7 +38,7 @@ Examples:
 				return err
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(false)
 
 			var item *api.Item
 			var err error
@@ -9,20 +9,81 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
 )
 
+// itemFilters holds the item filter flags shared by commands that list items
+type itemFilters struct {
+	status string
+	level  string
+	env    string
+	query  string
+	since  string
+	from   string
+	to     string
+	page   int
+}
+
+// addFlags registers the filter flags on cmd
+func (f *itemFilters) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.status, "status", "active", "filter by status: active, resolved, muted, any")
+	cmd.Flags().StringVar(&f.level, "level", "", "filter by level: debug, info, warning, error, critical (comma-separated)")
+	cmd.Flags().StringVar(&f.env, "env", "", "filter by environment")
+	cmd.Flags().StringVar(&f.query, "query", "", "text search in item titles")
+	cmd.Flags().StringVar(&f.since, "since", "", "filter items with occurrences since duration (e.g., '8 hours ago', '24h', '7 days')")
+	cmd.Flags().StringVar(&f.from, "from", "", "filter items from datetime (ISO 8601)")
+	cmd.Flags().StringVar(&f.to, "to", "", "filter items until datetime (ISO 8601)")
+	cmd.Flags().IntVar(&f.page, "page", 1, "page number")
+}
+
+// options converts the filter flags into API options
+func (f *itemFilters) options() (api.ItemsOptions, error) {
+	opts := api.ItemsOptions{
+		Status:      f.status,
+		Level:       f.level,
+		Environment: f.env,
+		Query:       f.query,
+		Page:        f.page,
+	}
+
+	// Parse time filters
+	if f.since != "" {
+		t, err := parseDuration(f.since)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --since value: %w", err)
+		}
+		opts.DateFrom = t
+	}
+	if f.from != "" {
+		t, err := parseTimeArg(f.from)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --from value: %w", err)
+		}
+		opts.DateFrom = t
+	}
+	if f.to != "" {
+		t, err := parseTimeArg(f.to)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --to value: %w", err)
+		}
+		opts.DateTo = t
+	}
+
+	// Use default environment from config if not specified
+	if opts.Environment == "" && cfg.DefaultEnvironment != "" {
+		opts.Environment = cfg.DefaultEnvironment
+	}
+
+	return opts, nil
+}
+
 func newItemsCmd() *cobra.Command {
 	var (
-		status string
-		level  string
-		env    string
-		query  string
-		since  string
-		from   string
-		to     string
-		sortBy string
-		page   int
-		limit  int
+		filters itemFilters
+		sortBy  string
+		limit   int
+		owner   string
+		showOwn bool
 	)
 
 	cmd := &cobra.Command{
@@ -39,82 +100,93 @@ Examples:
   rollbar items --since 24h                  # Items from last 24 hours
   rollbar items --query "TypeError"          # Search by title
   rollbar items --sort occurrences           # Sort by occurrence count
+  rollbar items --owners                     # Add an owner column from CODEOWNERS
+  rollbar items --owner @acme/billing        # Only items owned by a team
   rollbar items --ai                         # Token-efficient output for AI`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cfg.Validate(); err != nil {
 				return err
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(false)
 
-			opts := api.ItemsOptions{
-				Status:      status,
-				Level:       level,
-				Environment: env,
-				Query:       query,
-				Page:        page,
-			}
-
-			// Parse time filters
-			if since != "" {
-				t, err := parseDuration(since)
-				if err != nil {
-					return fmt.Errorf("invalid --since value: %w", err)
-				}
-				opts.DateFrom = t
-			}
-			if from != "" {
-				t, err := parseTimeArg(from)
-				if err != nil {
-					return fmt.Errorf("invalid --from value: %w", err)
-				}
-				opts.DateFrom = t
-			}
-			if to != "" {
-				t, err := parseTimeArg(to)
-				if err != nil {
-					return fmt.Errorf("invalid --to value: %w", err)
-				}
-				opts.DateTo = t
-			}
-
-			// Use default environment from config if not specified
-			if opts.Environment == "" && cfg.DefaultEnvironment != "" {
-				opts.Environment = cfg.DefaultEnvironment
+			opts, err := filters.options()
+			if err != nil {
+				return err
 			}
 
-			items, _, err := client.ListItems(opts)
+			items, hasMore, err := client.ListItemsPage(opts)
 			if err != nil {
 				return err
 			}
+			page := output.Page{Number: opts.Page, HasMore: hasMore}
 
 			// Apply sorting
 			items = sortItems(items, sortBy)
 
+			// Resolve owners before limiting when filtering by them
+			if owner != "" {
+				items, err = filterByOwner(client, items, owner)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Apply limit
 			if limit > 0 && len(items) > limit {
 				items = items[:limit]
 			}
 
-			formatter := getFormatter()
+			if showOwn && owner == "" {
+				if err := setOwners(client, items); err != nil {
+					return err
+				}
+			}
+
+			formatter := output.WithPage(getFormatter(), page)
 			return formatter.FormatItems(os.Stdout, items)
 		},
 	}
 
-	cmd.Flags().StringVar(&status, "status", "active", "filter by status: active, resolved, muted, any")
-	cmd.Flags().StringVar(&level, "level", "", "filter by level: debug, info, warning, error, critical (comma-separated)")
-	cmd.Flags().StringVar(&env, "env", "", "filter by environment")
-	cmd.Flags().StringVar(&query, "query", "", "text search in item titles")
-	cmd.Flags().StringVar(&since, "since", "", "filter items with occurrences since duration (e.g., '8 hours ago', '24h', '7 days')")
-	cmd.Flags().StringVar(&from, "from", "", "filter items from datetime (ISO 8601)")
-	cmd.Flags().StringVar(&to, "to", "", "filter items until datetime (ISO 8601)")
+	filters.addFlags(cmd)
 	cmd.Flags().StringVar(&sortBy, "sort", "recent", "sort by: recent, occurrences, first-seen, level")
-	cmd.Flags().IntVar(&page, "page", 1, "page number")
 	cmd.Flags().IntVar(&limit, "limit", 0, "limit number of results (0 = no limit)")
+	cmd.Flags().BoolVar(&showOwn, "owners", false, "show owners from the local CODEOWNERS file (one extra request per item)")
+	cmd.Flags().StringVar(&owner, "owner", "", "only items owned by this CODEOWNERS owner ('none' for unowned); implies --owners")
 
 	return cmd
 }
 
+// setOwners sets Owners on each item from the top app frame of its latest occurrence
+func setOwners(client *api.Client, items []api.Item) error {
+	resolver, err := newOwnerResolver(".", nil)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		own, err := resolver.latestOwnership(client, &items[i])
+		if err != nil {
+			return err
+		}
+		items[i].Owners = own.Match.Owners
+	}
+	return nil
+}
+
+// filterByOwner sets owners on items and keeps those owned by owner
+func filterByOwner(client *api.Client, items []api.Item, owner string) ([]api.Item, error) {
+	if err := setOwners(client, items); err != nil {
+		return nil, err
+	}
+	owned := []api.Item{}
+	for _, item := range items {
+		if ownerMatches(item.Owners, owner) {
+			owned = append(owned, item)
+		}
+	}
+	return owned, nil
+}
+
 func sortItems(items []api.Item, sortBy string) []api.Item {
 	switch strings.ToLower(sortBy) {
 	case "occurrences":
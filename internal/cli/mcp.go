@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/mcp"
+)
+
+func newMcpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Run a Model Context Protocol server over stdio",
+		Long: `Run a Model Context Protocol (MCP) server on stdin/stdout, exposing Rollbar
+as tools for AI agents: list_items, get_item, get_context, list_occurrences
+and get_occurrence.
+
+The resolve_item tool modifies Rollbar and is only exposed when enabled in
+the config:
+
+  rollbar config set mcp.allow_writes true
+
+Example MCP client configuration:
+
+  {"mcpServers": {"rollbar": {"command": "rollbar", "args": ["mcp"]}}}`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			client := newClient(false)
+			server := mcp.NewServer(client, cfg.MCP.AllowWrites)
+
+			if !quiet {
+				fmt.Fprintln(os.Stderr, "Rollbar MCP server listening on stdio")
+			}
+			return server.Serve(os.Stdin, os.Stdout)
+		},
+	}
+
+	return cmd
+}
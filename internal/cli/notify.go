@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/notify"
+)
+
+func newNotifyCmd() *cobra.Command {
+	var (
+		filters   itemFilters
+		webhook   string
+		format    string
+		statePath string
+		spikeRate float64
+		interval  time.Duration
+		headers   []string
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Post webhook notifications for new or spiking items",
+		Long: `Compare current items against a stored snapshot and POST a notification to a
+webhook when new items appear or an item's occurrence rate crosses a threshold.
+
+The first run only records a baseline snapshot. Each later run reports items not
+in the snapshot as new, and items gaining at least --spike-rate occurrences per
+minute since the snapshot as spiking. The snapshot is only updated after a
+successful delivery, so failed notifications are retried on the next run.
+Every page of matching items is read, and items that stop matching stay in the
+snapshot, so one that comes back is not reported as new.
+
+Use --interval to keep running and check periodically instead of exiting.
+
+Examples:
+  rollbar notify --webhook http://localhost:9000/hook
+  rollbar notify --webhook $SLACK_WEBHOOK_URL --format slack --env production
+  rollbar notify --webhook $URL --interval 5m --spike-rate 20
+  rollbar notify --webhook $URL --header "Authorization: Bearer $TOKEN"
+  rollbar notify --dry-run                     # Print the payload instead of posting`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("page") {
+				return fmt.Errorf("--page is not supported; notify reads every page of items")
+			}
+			if webhook == "" && !dryRun {
+				return fmt.Errorf("--webhook is required (or use --dry-run)")
+			}
+			if format != notify.FormatJSON && format != notify.FormatSlack {
+				return fmt.Errorf("unknown --format %q (use json or slack)", format)
+			}
+
+			hook := &notify.Webhook{URL: webhook, Headers: map[string]string{}}
+			for _, h := range headers {
+				name, value, ok := strings.Cut(h, ":")
+				if !ok {
+					return fmt.Errorf("invalid --header %q (use 'Name: value')", h)
+				}
+				hook.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+
+			client := newClient(false)
+			run := func() error {
+				return runNotify(client, &filters, hook, format, statePath, spikeRate, dryRun)
+			}
+
+			if interval <= 0 {
+				return run()
+			}
+
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				if err := run(); err != nil {
+					// Keep the loop alive; the next tick retries
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				select {
+				case <-stop:
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	filters.addFlags(cmd)
+	cmd.Flags().StringVar(&webhook, "webhook", "", "webhook URL to POST notifications to")
+	cmd.Flags().StringVar(&format, "format", notify.FormatJSON, "payload format: json, slack")
+	cmd.Flags().StringVar(&statePath, "state", ".rollbar-notify.json", "snapshot file used to compare runs")
+	cmd.Flags().Float64Var(&spikeRate, "spike-rate", 10, "notify when an item gains at least this many occurrences per minute (0 = disabled)")
+	cmd.Flags().DurationVar(&interval, "interval", 0, "keep running and check at this interval (e.g. 5m)")
+	cmd.Flags().StringArrayVar(&headers, "header", nil, "extra webhook request header 'Name: value' (repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the payload to stdout instead of posting it, leaving the state file unchanged")
+
+	return cmd
+}
+
+// runNotify performs one snapshot comparison and delivers any events
+func runNotify(client *api.Client, filters *itemFilters, hook *notify.Webhook, format, statePath string, spikeRate float64, dryRun bool) error {
+	opts, err := filters.options()
+	if err != nil {
+		return err
+	}
+
+	items, err := listWindowItems(client, opts)
+	if err != nil {
+		return err
+	}
+
+	return notifyItems(items, hook, format, statePath, spikeRate, dryRun, time.Now())
+}
+
+// notifyItems compares items with the snapshot at statePath, delivers any
+// events and saves the new snapshot. Items of the old snapshot that are no
+// longer listed are kept, so they are not reported as new if they come back. A
+// dry run prints the payload and leaves
+// the snapshot alone, so the next real run still sees the same events.
+func notifyItems(items []api.Item, hook *notify.Webhook, format, statePath string, spikeRate float64, dryRun bool, now time.Time) error {
+	prev, err := notify.LoadSnapshot(statePath)
+	if err != nil {
+		return err
+	}
+
+	events := notify.Detect(prev, items, now, spikeRate)
+
+	if len(events) > 0 {
+		payload, err := notify.BuildPayload(format, events, now)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			fmt.Fprintln(os.Stdout, string(payload))
+			return nil
+		}
+		if err := hook.Send(payload); err != nil {
+			return err
+		}
+
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "Sent %d notification(s)\n", len(events))
+		}
+	}
+	if dryRun {
+		return nil
+	}
+
+	if prev == nil && !quiet {
+		fmt.Fprintf(os.Stderr, "Recorded baseline of %d items in %s\n", len(items), statePath)
+	}
+	snap := notify.NewSnapshot(items, now)
+	snap.Keep(prev)
+	return snap.Save(statePath)
+}
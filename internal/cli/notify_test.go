@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/notify"
+)
+
+func TestNotifyDryRunKeepsState(t *testing.T) {
+	defer func(stdout *os.File, q bool) { os.Stdout, quiet = stdout, q }(os.Stdout, quiet)
+	quiet = true
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	os.Stdout = out
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	old := api.Item{ID: 1, Counter: 10, Title: "Old", TotalOccurrences: 5}
+	if err := notify.NewSnapshot([]api.Item{old}, start).Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(statePath)
+
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { sent++ }))
+	defer server.Close()
+	hook := &notify.Webhook{URL: server.URL}
+
+	items := []api.Item{old, {ID: 2, Counter: 20, Title: "New", TotalOccurrences: 1}}
+	if err := notifyItems(items, hook, notify.FormatJSON, statePath, 0, true, start.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(statePath); !bytes.Equal(before, after) {
+		t.Errorf("dry run changed the state file:\n%s", after)
+	}
+	if sent != 0 {
+		t.Errorf("dry run posted %d notification(s)", sent)
+	}
+	if printed, _ := os.ReadFile(out.Name()); !bytes.Contains(printed, []byte(`"counter":20`)) {
+		t.Errorf("expected the payload on stdout, got %s", printed)
+	}
+
+	// The next real run still reports the new item
+	if err := notifyItems(items, hook, notify.FormatJSON, statePath, 0, false, start.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 {
+		t.Errorf("expected the real run to post once, got %d", sent)
+	}
+}
+
+func TestNotifyItemThatLeavesAndReturns(t *testing.T) {
+	defer func(q bool) { quiet = q }(quiet)
+	quiet = true
+
+	var posted [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = append(posted, body)
+	}))
+	defer server.Close()
+	hook := &notify.Webhook{URL: server.URL}
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	a := api.Item{ID: 1, Counter: 10, Title: "A", TotalOccurrences: 5}
+	b := api.Item{ID: 2, Counter: 20, Title: "B", TotalOccurrences: 3}
+
+	runs := [][]api.Item{
+		{a, b}, // Baseline
+		{a},    // B drops out of the list
+		{a, b}, // and comes back
+	}
+	for i, items := range runs {
+		if err := notifyItems(items, hook, notify.FormatJSON, statePath, 0, false, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(posted) != 0 {
+		t.Errorf("expected no notifications, got %s", bytes.Join(posted, []byte("\n")))
+	}
+
+	snap, err := notify.LoadSnapshot(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Items) != 2 {
+		t.Errorf("expected both items in the snapshot, got %+v", snap.Items)
+	}
+}
@@ -8,9 +8,52 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/diff"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/redact"
 )
 
+// detailFlags holds the optional occurrence data flags shared by occurrence and context
+type detailFlags struct {
+	request  bool
+	custom   bool
+	noRedact bool
+}
+
+// addFlags registers the detail flags on cmd
+func (d *detailFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&d.request, "show-request", false, "show request headers, params, POST data, body and query string")
+	cmd.Flags().BoolVar(&d.custom, "show-custom", false, "show the occurrence's custom data")
+	cmd.Flags().BoolVar(&d.noRedact, "no-redact", false, "don't mask cookies, authorization headers, emails, tokens, card numbers and passwords")
+}
+
+func (d *detailFlags) details() output.Details {
+	return output.Details{Request: d.request, Custom: d.custom}
+}
+
+// newClient creates an API client that masks request and custom data in every
+// occurrence it fetches, unless noRedact or redact.disabled is set. Commands
+// create their client here so that no occurrence is shown unredacted.
+func newClient(noRedact bool) *api.Client {
+	client := api.NewClient(cfg.AccessToken)
+	if policy := redactPolicy(noRedact); policy != nil {
+		client.Redact = policy.Instance
+	}
+	return client
+}
+
+// redactPolicy returns the configured redaction policy, or nil when disabled
+// with --no-redact or redact.disabled
+func redactPolicy(noRedact bool) *redact.Policy {
+	if noRedact || cfg.Redact.Disabled {
+		return nil
+	}
+	return redact.Default(cfg.Redact.Keys, cfg.Redact.Allow)
+}
+
 func newOccurrenceCmd() *cobra.Command {
+	var details detailFlags
+
 	cmd := &cobra.Command{
 		Use:   "occurrence <id>",
 		Short: "Get single occurrence details",
@@ -18,7 +61,9 @@ func newOccurrenceCmd() *cobra.Command {
 
 Examples:
   rollbar occurrence 123456789          # Get occurrence details
-  rollbar occurrence 123456789 --ai     # AI-friendly output`,
+  rollbar occurrence 123456789 --ai     # AI-friendly output
+  rollbar occurrence 123456789 --show-request --show-custom
+  rollbar occurrence diff 123 456       # Compare two occurrences`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := cfg.Validate(); err != nil {
@@ -30,16 +75,87 @@ Examples:
 				return fmt.Errorf("invalid occurrence ID: %w", err)
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(details.noRedact)
 			instance, err := client.GetInstance(id)
 			if err != nil {
 				return err
 			}
 
-			formatter := getFormatter()
+			formatter := output.WithDetails(getFormatter(), details.details())
 			return formatter.FormatInstance(os.Stdout, instance)
 		},
 	}
 
+	details.addFlags(cmd)
+
+	cmd.AddCommand(newOccurrenceDiffCmd())
+
+	return cmd
+}
+
+func newOccurrenceDiffCmd() *cobra.Command {
+	var noRedact bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <id1> <id2>",
+		Short: "Compare two occurrences",
+		Long: `Compare two occurrences (instances) field by field: exception, stack frames,
+request headers and params, person, server, custom data and code version.
+
+Frames are aligned by filename and method, so a moved line shows up as a change
+to that frame. Timestamps and occurrence IDs are not compared.
+
+The default output is a unified diff, colored on a terminal. With -o json the
+differences are printed under "patch" as a JSON Patch (RFC 6902) that turns the
+first occurrence into the second; each operation also carries the "old" value.
+
+Examples:
+  rollbar occurrence diff 123456789 123456790
+  rollbar occurrence diff 123456789 123456790 -o json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			var ids [2]int64
+			for i, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid occurrence ID %q: %w", arg, err)
+				}
+				ids[i] = id
+			}
+
+			client := newClient(noRedact)
+			from, err := client.GetInstance(ids[0])
+			if err != nil {
+				return err
+			}
+			to, err := client.GetInstance(ids[1])
+			if err != nil {
+				return err
+			}
+
+			result := diff.Instances(from, to)
+
+			switch output.Format(outputFormat) {
+			case output.FormatJSON:
+				return jsonFormatter().FormatValue(os.Stdout, "patch", result.Patch)
+			case output.FormatMarkdown:
+				fmt.Println("```diff")
+				result.WriteUnified(os.Stdout, false)
+				fmt.Println("```")
+			case output.FormatCompact:
+				result.WriteUnified(os.Stdout, false)
+			default:
+				result.WriteUnified(os.Stdout, useColor())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&noRedact, "no-redact", false, "don't mask cookies, authorization headers, emails, tokens, card numbers and passwords")
+
 	return cmd
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
 )
 
 func newOccurrencesCmd() *cobra.Command {
@@ -16,6 +17,7 @@ func newOccurrencesCmd() *cobra.Command {
 		since       string
 		limit       int
 		page        int
+		noRedact    bool
 	)
 
 	cmd := &cobra.Command{
@@ -36,7 +38,7 @@ Examples:
 				return fmt.Errorf("specify --item <counter> or --all")
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(noRedact)
 
 			opts := api.InstancesOptions{
 				Page: page,
@@ -55,6 +57,7 @@ Examples:
 			if err != nil {
 				return err
 			}
+			page := output.Page{Number: opts.Page, HasMore: len(instances) >= api.InstancesPageSize}
 
 			// Filter by time if --since specified
 			if since != "" {
@@ -77,7 +80,7 @@ Examples:
 				instances = instances[:limit]
 			}
 
-			formatter := getFormatter()
+			formatter := output.WithPage(getFormatter(), page)
 			return formatter.FormatInstances(os.Stdout, instances)
 		},
 	}
@@ -87,6 +90,7 @@ Examples:
 	cmd.Flags().StringVar(&since, "since", "", "filter occurrences since duration")
 	cmd.Flags().IntVar(&limit, "limit", 0, "limit number of results")
 	cmd.Flags().IntVar(&page, "page", 1, "page number")
+	cmd.Flags().BoolVar(&noRedact, "no-redact", false, "don't mask cookies, authorization headers, emails, tokens, card numbers and passwords")
 
 	return cmd
 }
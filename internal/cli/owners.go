@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/owners"
+	"github.com/robzolkos/rollbar-cli/internal/source"
+)
+
+// ownerResolver maps an occurrence's top app frame to CODEOWNERS owners
+type ownerResolver struct {
+	root     string // Repository root that CODEOWNERS paths are relative to
+	resolver *source.Resolver
+	file     *owners.File
+}
+
+// ownership is the owners of an occurrence and how they were found
+type ownership struct {
+	Frame *api.Frame   `json:"frame,omitempty"`
+	Path  string       `json:"path,omitempty"` // Frame file relative to the repository root
+	Match owners.Match `json:"match"`
+}
+
+// itemOwnership is the output of 'rollbar owners'
+type itemOwnership struct {
+	Counter int    `json:"counter"`
+	Title   string `json:"title"`
+	ownership
+}
+
+// newOwnerResolver loads CODEOWNERS from the repository containing dir (or dir
+// itself outside git), using the configured and flag path prefix rewrites
+func newOwnerResolver(dir string, pathMapFlags []string) (*ownerResolver, error) {
+	mapping, err := parsePathMap(cfg.Source.PathMap, pathMapFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	root := dir
+	if repo, err := source.OpenRepo(dir); err == nil {
+		root = repo.Root
+	}
+	path, ok := owners.Find(root)
+	if !ok {
+		return nil, fmt.Errorf("no CODEOWNERS file found in %s (looked in %s)", root, strings.Join(owners.Locations, ", "))
+	}
+	file, err := owners.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ownerResolver{root: root, resolver: source.NewResolver(dir, mapping), file: file}, nil
+}
+
+// forInstance finds the owners of the top app frame of an occurrence. The
+// result has no frame when none of its app frames map to a local file.
+func (o *ownerResolver) forInstance(inst *api.Instance) ownership {
+	res := ownership{Match: owners.Match{Owners: []string{}, Rules: []owners.Rule{}}}
+	root := ""
+	if inst.Data.Server != nil {
+		root = inst.Data.Server.Root
+	}
+
+	for _, trace := range inst.Data.Body.Traces() {
+		for i := range trace.Frames {
+			frame := trace.Frames[i]
+			if !output.IsAppFrame(frame) {
+				continue
+			}
+			path, ok := o.resolver.Resolve(frame.Filename, root)
+			if !ok {
+				continue
+			}
+			rel, err := o.relPath(path)
+			if err != nil {
+				continue
+			}
+			res.Frame = &frame
+			res.Path = rel
+			res.Match = o.file.Match(rel)
+			return res
+		}
+	}
+	return res
+}
+
+func (o *ownerResolver) relPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	root := o.root
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside %s", path, o.root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// latestOwnership looks up the owners of an item's latest occurrence
+func (o *ownerResolver) latestOwnership(client *api.Client, item *api.Item) (ownership, error) {
+	instances, err := client.ListInstances(api.InstancesOptions{ItemID: item.ID.Int64()})
+	if err != nil {
+		return ownership{}, err
+	}
+	if len(instances) == 0 {
+		return ownership{Match: owners.Match{Owners: []string{}, Rules: []owners.Rule{}}}, nil
+	}
+	return o.forInstance(&instances[0]), nil
+}
+
+// ownerMatches reports whether owners include want. The leading "@" is
+// optional and case is ignored; "none" matches items without owners.
+func ownerMatches(itemOwners []string, want string) bool {
+	want = strings.ToLower(strings.TrimPrefix(want, "@"))
+	if want == "none" {
+		return len(itemOwners) == 0
+	}
+	for _, owner := range itemOwners {
+		if strings.ToLower(strings.TrimPrefix(owner, "@")) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func newOwnersCmd() *cobra.Command {
+	var (
+		sourceDir string
+		pathMap   []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "owners <counter>",
+		Short: "Show which team owns an item, from CODEOWNERS",
+		Long: `Show the owners of an item according to the local CODEOWNERS file.
+
+The top app frame of the item's latest occurrence is mapped to a file in the
+working tree (using source.path_map from .rollbar.yaml and --path-map) and
+matched against CODEOWNERS in .github/, .gitlab/, the repository root or docs/.
+GitHub and GitLab syntax, including GitLab sections, are supported.
+
+Examples:
+  rollbar owners 123
+  rollbar owners 123 --path-map /app/=./
+  rollbar owners 123 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			counter, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid counter: %w", err)
+			}
+
+			resolver, err := newOwnerResolver(sourceDir, pathMap)
+			if err != nil {
+				return err
+			}
+
+			client := newClient(false)
+			item, err := client.GetItemByCounter(counter)
+			if err != nil {
+				return err
+			}
+			own, err := resolver.latestOwnership(client, item)
+			if err != nil {
+				return err
+			}
+
+			if output.Format(outputFormat) == output.FormatJSON {
+				return jsonFormatter().FormatValue(os.Stdout, "ownership", itemOwnership{item.Counter, item.Title, own})
+			}
+
+			fmt.Printf("#%d %s\n", item.Counter, item.Title)
+			if own.Frame == nil {
+				fmt.Println("Owners: (unknown - no app frame maps to a file in this repository; try --path-map)")
+				return nil
+			}
+			if len(own.Match.Owners) == 0 {
+				fmt.Println("Owners: (none)")
+			} else {
+				fmt.Printf("Owners: %s\n", strings.Join(own.Match.Owners, " "))
+			}
+			fmt.Printf("Frame:  %s:%d in %s()\n", own.Path, own.Frame.Lineno, own.Frame.Method)
+			rulesFile, _ := filepath.Rel(resolver.root, resolver.file.Path)
+			for _, rule := range own.Match.Rules {
+				section := ""
+				if rule.Section != "" {
+					section = fmt.Sprintf(" [%s]", rule.Section)
+				}
+				fmt.Printf("Rule:   %s%s (%s:%d)\n", rule.Pattern, section, rulesFile, rule.Line)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sourceDir, "source-dir", ".", "local working tree containing CODEOWNERS")
+	cmd.Flags().StringArrayVar(&pathMap, "path-map", nil, "rewrite a frame path prefix to a local path, as FROM=TO (repeatable)")
+
+	return cmd
+}
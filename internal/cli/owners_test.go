@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/owners"
+	"github.com/robzolkos/rollbar-cli/internal/source"
+)
+
+func TestOwnerResolverForInstance(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"app/models/billing/invoice.rb", "app/controllers/invoices_controller.rb"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# code\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := owners.Parse(strings.NewReader("* @acme/platform\n/app/models/billing/ @acme/billing\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := &ownerResolver{
+		root:     root,
+		resolver: source.NewResolver(root, map[string]string{"/srv/app/": "./"}),
+		file:     file,
+	}
+
+	inst := api.Instance{Data: api.InstanceData{Body: api.Body{Trace: &api.Trace{Frames: []api.Frame{
+		{Filename: "/usr/local/bundle/gems/activerecord-7.1.0/lib/active_record/base.rb", Lineno: 10},
+		{Filename: "/srv/app/app/models/billing/invoice.rb", Lineno: 1, Method: "total"},
+		{Filename: "/srv/app/app/controllers/invoices_controller.rb", Lineno: 1},
+	}}}}}
+
+	own := o.forInstance(&inst)
+	if own.Frame == nil || own.Frame.Method != "total" {
+		t.Fatalf("expected the top app frame, got %+v", own.Frame)
+	}
+	if own.Path != "app/models/billing/invoice.rb" {
+		t.Errorf("unexpected path %q", own.Path)
+	}
+	if got := strings.Join(own.Match.Owners, ","); got != "@acme/billing" {
+		t.Errorf("unexpected owners %q", got)
+	}
+
+	unmapped := api.Instance{Data: api.InstanceData{Body: api.Body{Trace: &api.Trace{Frames: []api.Frame{
+		{Filename: "/elsewhere/app/models/user.rb", Lineno: 1},
+	}}}}}
+	if own := o.forInstance(&unmapped); own.Frame != nil || len(own.Match.Owners) != 0 {
+		t.Errorf("expected no ownership for unmapped frames, got %+v", own)
+	}
+}
+
+func TestOwnerMatches(t *testing.T) {
+	tests := []struct {
+		owners []string
+		want   string
+		match  bool
+	}{
+		{[]string{"@acme/billing", "@alice"}, "@acme/billing", true},
+		{[]string{"@acme/billing"}, "acme/Billing", true},
+		{[]string{"@acme/billing"}, "@acme/data", false},
+		{[]string{}, "none", true},
+		{nil, "none", true},
+		{[]string{"@alice"}, "none", false},
+	}
+	for _, tt := range tests {
+		if got := ownerMatches(tt.owners, tt.want); got != tt.match {
+			t.Errorf("ownerMatches(%v, %q) = %v, want %v", tt.owners, tt.want, got, tt.match)
+		}
+	}
+}
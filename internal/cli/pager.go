@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// pager is the running $PAGER that stdout is piped into, if any
+var pager struct {
+	cmd    *exec.Cmd
+	stdout *os.File // The terminal, restored when the pager exits
+}
+
+// pagerCommand returns the pager to use for format, or nil when output should
+// go straight to stdout. Only table and markdown output on a terminal is paged.
+func pagerCommand(format output.Format) []string {
+	if noPager || !isTerminal() {
+		return nil
+	}
+	if format != output.FormatTable && format != output.FormatMarkdown {
+		return nil
+	}
+	value, ok := os.LookupEnv("ROLLBAR_PAGER")
+	if !ok {
+		value, ok = os.LookupEnv("PAGER")
+	}
+	if !ok {
+		value = "less"
+	}
+	args := strings.Fields(value)
+	if len(args) == 0 || args[0] == "cat" {
+		return nil
+	}
+	return args
+}
+
+// startPager pipes the rest of stdout through the pager for format. less is
+// started with -FRX so that output that fits on one screen is printed as is.
+// Errors are ignored and output goes to the terminal.
+func startPager(format output.Format) {
+	args := pagerCommand(format)
+	if args == nil || pager.cmd != nil {
+		return
+	}
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return
+	}
+
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if _, ok := os.LookupEnv("LV"); !ok {
+		cmd.Env = append(cmd.Env, "LV=-c")
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r, os.Stdout, os.Stderr
+	err = cmd.Start()
+	r.Close()
+	if err != nil {
+		w.Close()
+		return
+	}
+
+	pager.cmd, pager.stdout = cmd, os.Stdout
+	os.Stdout = w
+}
+
+// stopPager closes the pager's input and waits for the user to quit it. An
+// error from writing after the pager was quit early is dropped.
+func stopPager(err error) error {
+	if pager.cmd == nil {
+		return err
+	}
+	os.Stdout.Close()
+	_ = pager.cmd.Wait()
+	os.Stdout = pager.stdout
+	pager.cmd, pager.stdout = nil, nil
+
+	if errors.Is(err, syscall.EPIPE) {
+		return nil
+	}
+	return err
+}
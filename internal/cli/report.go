@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/report"
+)
+
+// Caps on the requests made for a report
+const (
+	maxReportItemPages     = 10
+	maxReportInstancePages = 5
+)
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate reliability reports",
+		Long: `Generate reports on the items seen in a time window.
+
+Examples:
+  rollbar report html --since 7d --out report.html`,
+	}
+
+	cmd.AddCommand(newReportHTMLCmd())
+
+	return cmd
+}
+
+func newReportHTMLCmd() *cobra.Command {
+	var (
+		since   string
+		env     string
+		level   string
+		title   string
+		top     int
+		outFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "html",
+		Short: "Write a self-contained HTML report",
+		Long: `Write a single-file HTML report of the items with occurrences in a window:
+new and resolved counts, breakdowns by level and environment, and the top items
+by occurrences with a sparkline of their recent occurrences and their latest
+stack trace, expandable.
+
+The page has no scripts or external assets, so it can be mailed or attached to
+a review as is. Items are read from the item list, and their occurrences in the
+window are counted from each item's occurrence list, not from lifetime totals.
+Up to 100 occurrences are read per item; counts marked "+" are lower bounds.
+
+Examples:
+  rollbar report html --since 7d --out report.html
+  rollbar report html --since 24h --env production --top 20 > today.html`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			from, err := parseDuration(since)
+			if err != nil {
+				return fmt.Errorf("invalid --since value: %w", err)
+			}
+			if env == "" {
+				env = cfg.DefaultEnvironment
+			}
+			now := time.Now()
+
+			client := newClient(false)
+			items, err := listWindowItems(client, api.ItemsOptions{
+				Status:      "any",
+				Level:       level,
+				Environment: env,
+				DateFrom:    from,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Every item's occurrences are read back to the start of the
+			// window, since the report counts those rather than lifetime totals
+			entries := make([]report.Entry, len(items))
+			for i := range items {
+				entries[i].Item = &items[i]
+				entries[i].Instances, entries[i].Truncated, err = recentInstances(client, items[i].ID.Int64(), from)
+				if err != nil {
+					return err
+				}
+			}
+
+			r := report.Build(entries, report.Options{
+				Title:       title,
+				Environment: env,
+				Since:       from,
+				Until:       now,
+				Top:         top,
+			})
+
+			w := io.Writer(os.Stdout)
+			if outFile != "" {
+				f, err := os.Create(outFile)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			if err := report.WriteHTML(w, r); err != nil {
+				return err
+			}
+			if outFile != "" && !quiet {
+				fmt.Fprintf(os.Stderr, "Wrote report of %d items to %s\n", len(items), outFile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "7d", "report window (e.g., '24h', '7 days')")
+	cmd.Flags().StringVar(&env, "env", "", "filter by environment")
+	cmd.Flags().StringVar(&level, "level", "", "filter by level: debug, info, warning, error, critical (comma-separated)")
+	cmd.Flags().StringVar(&title, "title", "Rollbar reliability report", "report heading")
+	cmd.Flags().IntVar(&top, "top", 10, "items listed in detail (0 = all)")
+	cmd.Flags().StringVar(&outFile, "out", "", "output file path")
+
+	return cmd
+}
+
+// listWindowItems reads item pages until a short page, up to maxReportItemPages
+func listWindowItems(client *api.Client, opts api.ItemsOptions) ([]api.Item, error) {
+	var all []api.Item
+	for page := 1; page <= maxReportItemPages; page++ {
+		opts.Page = page
+		items, _, err := client.ListItems(opts)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < api.ItemsPageSize {
+			break
+		}
+	}
+	return all, nil
+}
+
+// recentInstances reads an item's occurrences back to since, newest first, up
+// to maxReportInstancePages. It reports whether it stopped at the page limit
+// before reaching since.
+func recentInstances(client *api.Client, itemID int64, since time.Time) ([]api.Instance, bool, error) {
+	var all []api.Instance
+	for page := 1; page <= maxReportInstancePages; page++ {
+		instances, err := client.ListInstances(api.InstancesOptions{ItemID: itemID, Page: page})
+		if err != nil {
+			return nil, false, err
+		}
+		all = append(all, instances...)
+		if len(instances) < api.InstancesPageSize || instances[len(instances)-1].Time.Before(since) {
+			return all, false, nil
+		}
+	}
+	return all, true, nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/repro"
+)
+
+func newReproCmd() *cobra.Command {
+	var (
+		format   string
+		baseURL  string
+		noRedact bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "repro <occurrence-id>",
+		Short: "Generate a script that replays an occurrence's request",
+		Long: `Generate a ready-to-run reproduction of the HTTP request behind an occurrence:
+a curl or HTTPie command, a Go test, or an RSpec request spec.
+
+Method, URL, headers, query params and the POST body are replayed. Values the
+redaction policy masks (cookies, authorization headers, tokens, passwords,
+emails, card numbers) become environment variables named after their key,
+e.g. $AUTHORIZATION or $COOKIE, so the script runs once they are set.
+
+Examples:
+  rollbar repro 453568801204                                  # curl command
+  rollbar repro 453568801204 --base-url http://localhost:3000 # Replay against a dev server
+  rollbar repro 453568801204 --format httpie
+  rollbar repro 453568801204 --format go-test > repro_test.go
+  rollbar repro 453568801204 --format rspec > spec/requests/repro_spec.rb`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid occurrence ID: %w", err)
+			}
+
+			client := newClient(noRedact)
+			instance, err := client.GetInstance(id)
+			if err != nil {
+				return err
+			}
+
+			req, err := repro.FromInstance(instance, repro.Options{BaseURL: baseURL})
+			if err != nil {
+				return err
+			}
+			out, err := repro.Render(req, format)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.WriteString(out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "curl", "reproduction format: "+strings.Join(repro.Formats, ", "))
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "replace the request's scheme and host, e.g. http://localhost:3000")
+	cmd.Flags().BoolVar(&noRedact, "no-redact", false, "replay the original values of redacted headers and params")
+
+	return cmd
+}
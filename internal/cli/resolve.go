@@ -6,8 +6,6 @@ import (
 	"strconv"
 
 	"github.com/spf13/cobra"
-
-	"github.com/robzolkos/rollbar-cli/internal/api"
 )
 
 func newResolveCmd() *cobra.Command {
@@ -40,7 +38,7 @@ Note: This command requires a project access token with write scope.`,
 				return err
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(false)
 
 			// Handle UUID mode (single item by internal ID)
 			if uuid != "" {
@@ -1,23 +1,42 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/robzolkos/rollbar-cli/internal/config"
+	"github.com/robzolkos/rollbar-cli/internal/jq"
 	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/term"
 	"github.com/robzolkos/rollbar-cli/internal/version"
 )
 
 var (
 	cfgFile      string
 	outputFormat string
+	templateFile string
+	jsonFields   []string
+	jqExpr       string
+	jsonIndent   int
+	csvColumns   []string
 	aiMode       bool
 	noColor      bool
+	colorMode    string
+	noPager      bool
+	wide         bool
+	timeZone     string
+	timeFormat   string
 	quiet        bool
 
-	cfg *config.Config
+	cfg     *config.Config
+	jqQuery *jq.Query
+
+	// displayZone is the zone from --tz or the timezone config key. Times
+	// without an offset in --from, --to and --since are read in it.
+	displayZone = time.Local
 )
 
 // rootCmd represents the base command
@@ -44,26 +63,74 @@ Use 'rollbar items' to list errors, 'rollbar item <counter>' to get details,
 			return err
 		}
 
+		// Config commands still run with a broken output section so it can be fixed
+		if cmd.Parent().Name() != "config" {
+			if err := applyOutputConfig(cmd); err != nil {
+				return err
+			}
+		}
+
 		// Apply --ai flag shortcuts
 		if aiMode {
 			outputFormat = "compact"
 			noColor = true
 		}
 
-		return nil
+		if templateFile != "" {
+			if cmd.Flags().Changed("output") && outputFormat != string(output.FormatTemplate) {
+				return fmt.Errorf("--template-file can't be combined with --output %s", outputFormat)
+			}
+			text, err := os.ReadFile(templateFile)
+			if err != nil {
+				return fmt.Errorf("reading template file: %w", err)
+			}
+			outputFormat = string(output.FormatTemplate) + "=" + string(text)
+		}
+		if err := output.CheckTemplate(output.Format(outputFormat)); err != nil {
+			return err
+		}
+		return applyFormatFlags(cmd)
 	},
 }
 
+// ExitError is returned by commands that need a specific process exit code.
+// Err is printed to stderr when set.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 // Execute runs the root command
 func Execute() error {
-	return rootCmd.Execute()
+	return stopPager(rootCmd.Execute())
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: .rollbar.yaml)")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json, compact, markdown")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json, ndjson, csv, tsv, compact, markdown, template=TEMPLATE")
+	rootCmd.PersistentFlags().StringVar(&templateFile, "template-file", "", "render output with the Go text/template in this file")
+	rootCmd.PersistentFlags().StringSliceVar(&jsonFields, "fields", nil, "JSON/NDJSON output: keep only these comma-separated fields, e.g. counter,title")
+	rootCmd.PersistentFlags().StringVar(&jqExpr, "jq", "", "JSON/NDJSON output: filter with a jq expression")
+	rootCmd.PersistentFlags().IntVar(&jsonIndent, "json-indent", 0, "JSON output: indent every document by N spaces, 0 for compact")
+	rootCmd.PersistentFlags().StringSliceVar(&csvColumns, "columns", nil, "CSV/TSV output: comma-separated columns, e.g. counter,title,level")
 	rootCmd.PersistentFlags().BoolVar(&aiMode, "ai", false, "AI mode: shorthand for --output=compact --no-color")
-	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (same as --color=never)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "colored output: always, never or auto (on a terminal, unless NO_COLOR is set)")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "don't page long table/markdown output through $PAGER")
+	rootCmd.PersistentFlags().BoolVar(&wide, "wide", false, "table/markdown output: show whole titles and every column instead of fitting the terminal")
+	rootCmd.PersistentFlags().StringVar(&timeZone, "tz", "", "time zone for shown times and for --from/--to without an offset, e.g. UTC or Europe/Berlin (default: timezone config, then local)")
+	rootCmd.PersistentFlags().StringVar(&timeFormat, "time-format", string(output.TimeRelative), "times in table, compact and markdown output: relative, iso or unix")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress non-essential output")
 
 	// Add subcommands
@@ -78,19 +145,170 @@ func init() {
 	rootCmd.AddCommand(newCompletionCmd())
 	rootCmd.AddCommand(newInitCmd())
 	rootCmd.AddCommand(newResolveCmd())
+	rootCmd.AddCommand(newTuiCmd())
+	rootCmd.AddCommand(newCheckCmd())
+	rootCmd.AddCommand(newNotifyCmd())
+	rootCmd.AddCommand(newExporterCmd())
+	rootCmd.AddCommand(newMcpCmd())
+	rootCmd.AddCommand(newSuspectsCmd())
+	rootCmd.AddCommand(newOwnersCmd())
+	rootCmd.AddCommand(newReproCmd())
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newTicketCmd())
+	rootCmd.AddCommand(newSchemaCmd())
+}
+
+// applyOutputConfig falls back to output.format, output.color and timezone
+// from the config when --output, --color and --tz aren't given
+func applyOutputConfig(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	if !flags.Changed("output") && cfg.Output.Format != "" {
+		if !output.Format(cfg.Output.Format).Valid() {
+			return fmt.Errorf("invalid output.format in config: %q", cfg.Output.Format)
+		}
+		outputFormat = cfg.Output.Format
+	}
+	if flags.Changed("color") {
+		if !validColorMode(colorMode) {
+			return fmt.Errorf("invalid --color value %q (expected always, never or auto)", colorMode)
+		}
+	} else if cfg.Output.Color != "" {
+		if !validColorMode(cfg.Output.Color) {
+			return fmt.Errorf("invalid output.color in config: %q (expected always, never or auto)", cfg.Output.Color)
+		}
+		colorMode = cfg.Output.Color
+	}
+
+	zone := cfg.Timezone
+	if flags.Changed("tz") {
+		zone = timeZone
+	}
+	loc, err := config.ParseTimezone(zone)
+	if err != nil {
+		if flags.Changed("tz") {
+			return fmt.Errorf("invalid --tz: %w", err)
+		}
+		return fmt.Errorf("invalid timezone in config: %w", err)
+	}
+	if err := output.SetTimeDisplay(loc, output.TimeFormat(timeFormat)); err != nil {
+		return fmt.Errorf("invalid --time-format: %w", err)
+	}
+	displayZone = loc
+	return nil
+}
+
+// applyFormatFlags validates --columns, and --fields, --jq and --json-indent,
+// which select JSON output unless another format was asked for
+func applyFormatFlags(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	format := output.Format(outputFormat)
+	if len(csvColumns) > 0 && format != output.FormatCSV && format != output.FormatTSV {
+		return fmt.Errorf("--columns requires --output csv or tsv")
+	}
+	if len(jsonFields) == 0 && jqExpr == "" && !flags.Changed("json-indent") {
+		return nil
+	}
+	if !flags.Changed("output") && !aiMode && templateFile == "" {
+		format = output.FormatJSON
+		outputFormat = string(format)
+	}
+	if flags.Changed("json-indent") && format != output.FormatJSON {
+		return fmt.Errorf("--json-indent requires --output json")
+	}
+	if format != output.FormatJSON && format != output.FormatNDJSON {
+		return fmt.Errorf("--fields and --jq require --output json or ndjson")
+	}
+	if jsonIndent < 0 {
+		return fmt.Errorf("--json-indent must be 0 or more")
+	}
+	if jqExpr != "" {
+		q, err := jq.Parse(jqExpr)
+		if err != nil {
+			return err
+		}
+		jqQuery = q
+	}
+	return nil
 }
 
 // getFormatter returns the appropriate formatter based on flags
 func getFormatter() output.Formatter {
 	format := output.Format(outputFormat)
-	useColor := !noColor && isTerminal()
-	return output.New(format, useColor)
+	f := newFormatter(format)
+	startPager(format)
+	return f
+}
+
+// newFormatter creates the formatter for format with the output flags applied
+func newFormatter(format output.Format) output.Formatter {
+	switch f := output.New(format, useColor()).(type) {
+	case *output.JSONFormatter:
+		return jsonFormatter()
+	case *output.NDJSONFormatter:
+		f.Fields, f.Query = jsonFields, jqQuery
+		return f
+	case *output.CSVFormatter:
+		f.Columns = csvColumns
+		return f
+	case *output.TableFormatter:
+		// Fit list rows to the terminal; piped output keeps the default widths
+		f.Wide = wide
+		if isTerminal() {
+			f.Width, _ = term.Size()
+		}
+		return f
+	case *output.MarkdownFormatter:
+		f.Wide = wide
+		return f
+	default:
+		return f
+	}
 }
 
-// isTerminal checks if stdout is a terminal
+// jsonFormatter returns a JSON formatter with the --fields, --jq and
+// --json-indent options
+func jsonFormatter() *output.JSONFormatter {
+	f := &output.JSONFormatter{Fields: jsonFields, Query: jqQuery}
+	if rootCmd.PersistentFlags().Changed("json-indent") {
+		f.Indent = jsonIndent
+		if jsonIndent == 0 {
+			f.Indent = output.CompactJSON
+		}
+	}
+	return f
+}
+
+// isTerminal checks if stdout is a terminal, or a pager running on one
 func isTerminal() bool {
-	fileInfo, _ := os.Stdout.Stat()
-	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+	return pager.cmd != nil || term.IsTerminal(os.Stdout)
+}
+
+// validColorMode reports whether mode is a --color value
+func validColorMode(mode string) bool {
+	return mode == "always" || mode == "never" || mode == "auto"
+}
+
+// useColor reports whether output should be colored. --no-color and
+// --color=never turn color off and --color=always turns it on; otherwise
+// NO_COLOR turns it off, CLICOLOR_FORCE turns it on, and it's on for terminals.
+func useColor() bool {
+	if noColor {
+		return false
+	}
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return isTerminal()
 }
 
 // newVersionCmd creates the version command
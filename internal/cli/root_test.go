@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/config"
+)
+
+func TestUseColor(t *testing.T) {
+	defer func(c bool, m string) { noColor, colorMode = c, m }(noColor, colorMode)
+
+	tests := []struct {
+		name    string
+		noColor bool
+		mode    string
+		env     map[string]string
+		want    bool
+	}{
+		{"auto when piped", false, "auto", nil, false},
+		{"always", false, "always", map[string]string{"NO_COLOR": "1"}, true},
+		{"never", false, "never", map[string]string{"CLICOLOR_FORCE": "1"}, false},
+		{"no-color beats always", true, "always", nil, false},
+		{"CLICOLOR_FORCE", false, "auto", map[string]string{"CLICOLOR_FORCE": "1"}, true},
+		{"CLICOLOR_FORCE=0", false, "auto", map[string]string{"CLICOLOR_FORCE": "0"}, false},
+		{"NO_COLOR beats CLICOLOR_FORCE", false, "auto", map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("CLICOLOR_FORCE", "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			noColor, colorMode = tt.noColor, tt.mode
+			if got := useColor(); got != tt.want {
+				t.Errorf("useColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOutputConfig(t *testing.T) {
+	defer func(c *config.Config, f, m string) { cfg, outputFormat, colorMode = c, f, m }(cfg, outputFormat, colorMode)
+
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "")
+		cmd.Flags().StringVar(&colorMode, "color", "auto", "")
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+
+	cfg = &config.Config{Output: config.OutputConfig{Format: "compact", Color: "never"}}
+	if err := applyOutputConfig(newCmd()); err != nil {
+		t.Fatal(err)
+	}
+	if outputFormat != "compact" || colorMode != "never" {
+		t.Errorf("config not applied: format %q, color %q", outputFormat, colorMode)
+	}
+
+	if err := applyOutputConfig(newCmd("-o", "json", "--color", "always")); err != nil {
+		t.Fatal(err)
+	}
+	if outputFormat != "json" || colorMode != "always" {
+		t.Errorf("flags should win: format %q, color %q", outputFormat, colorMode)
+	}
+
+	if err := applyOutputConfig(newCmd("--color", "sometimes")); err == nil {
+		t.Error("expected an error for --color=sometimes")
+	}
+	cfg.Output.Format = "yaml"
+	if err := applyOutputConfig(newCmd()); err == nil {
+		t.Error("expected an error for output.format yaml")
+	}
+}
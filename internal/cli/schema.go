@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/mcp"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// patchOp is the JSON form of a diff.Op, which marshals itself
+type patchOp struct {
+	Op    string      `json:"op" enum:"add,remove,replace" required:"true"`
+	Path  string      `json:"path" description:"JSON Pointer into the first occurrence's normalized document" required:"true"`
+	Value interface{} `json:"value,omitempty" description:"New value, for add and replace"`
+	Old   interface{} `json:"old,omitempty" description:"Replaced or removed value, for replace and remove"`
+}
+
+// reportDocument describes the {"schema": ..., key: report} documents of
+// JSONFormatter.FormatValue
+func reportDocument(key string, report map[string]interface{}) map[string]interface{} {
+	schema := mcp.Schema(struct {
+		Schema string `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	}{})
+	schema["properties"].(map[string]interface{})[key] = report
+	return schema
+}
+
+// outputSchemas returns the JSON Schema of each command's -o json output
+func outputSchemas() map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"items":           mcp.Schema(output.ItemsDocument{}),
+		"item":            mcp.Schema(output.ItemDocument{}),
+		"occurrences":     mcp.Schema(output.OccurrencesDocument{}),
+		"occurrence":      mcp.Schema(output.OccurrenceDocument{}),
+		"occurrence-diff": reportDocument("patch", map[string]interface{}{"type": "array", "items": mcp.Schema(patchOp{})}),
+		"context":         mcp.Schema(output.ContextDocument{}),
+		"context-bundle":  mcp.Schema(output.BundleDocument{}),
+		"whoami":          mcp.Schema(output.ProjectDocument{}),
+		"owners":          reportDocument("ownership", mcp.Schema(itemOwnership{})),
+		"suspects":        reportDocument("suspects", mcp.Schema(suspectsReport{})),
+		"ticket":          reportDocument("ticket", mcp.Schema(output.Ticket{})),
+	}
+}
+
+func newSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [name]",
+		Short: "Print the JSON Schema of JSON output",
+		Long: `Print the JSON Schema (draft 2020-12) of each command's -o json output, or of
+one of them. Every document has "schema": "rollbar-cli/v1"; fields may be added
+within a version, and removing or changing one bumps it.
+
+Names: items (also used by check), item, occurrences, occurrence,
+occurrence-diff, context (also item --context), context-bundle (context with
+several items or --where), whoami, owners, suspects and ticket. NDJSON lines
+are the records of the items, occurrences or context-bundle documents.
+
+Examples:
+  rollbar schema             # All schemas, by name
+  rollbar schema items       # The schema of 'rollbar items -o json'`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemas := outputSchemas()
+			for name, schema := range schemas {
+				schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+				schema["$id"] = output.SchemaVersion + "/" + name
+			}
+
+			var doc interface{} = schemas
+			if len(args) == 1 {
+				schema, ok := schemas[args[0]]
+				if !ok {
+					names := make([]string, 0, len(schemas))
+					for name := range schemas {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					return fmt.Errorf("unknown schema %q (available: %s)", args[0], strings.Join(names, ", "))
+				}
+				doc = schema
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(doc)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,22 @@
+package cli
+
+import "testing"
+
+func TestOutputSchemas(t *testing.T) {
+	for name, schema := range outputSchemas() {
+		props, ok := schema["properties"].(map[string]interface{})
+		if !ok || props["schema"] == nil {
+			t.Errorf("%s: expected a schema property, got %v", name, schema)
+		}
+		if len(props) < 2 {
+			t.Errorf("%s: expected properties besides the schema, got %v", name, props)
+		}
+	}
+
+	items := outputSchemas()["items"]["properties"].(map[string]interface{})
+	for _, name := range []string{"items", "page", "has_more"} {
+		if items[name] == nil {
+			t.Errorf("expected %q in the items schema", name)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/source"
+)
+
+// maxDeployPages caps how far back deploys are searched for the previous deploy
+const maxDeployPages = 3
+
+// suspectRange is the span of history searched for suspect commits
+type suspectRange struct {
+	From        string    `json:"from,omitempty"` // Previous deploy revision; empty when using the window
+	FromLabel   string    `json:"from_label,omitempty"`
+	To          string    `json:"to"`
+	ToLabel     string    `json:"to_label"`
+	Since       time.Time `json:"since,omitempty"` // Window start when no previous deploy is known
+	FirstSeen   time.Time `json:"first_seen"`
+	Environment string    `json:"environment,omitempty"`
+}
+
+// suspectFrame is an app frame with its blame, for reporting
+type suspectFrame struct {
+	Path  string         `json:"path"`
+	Line  int            `json:"line"`
+	Blame *source.Commit `json:"blame,omitempty"`
+}
+
+// suspectsReport is the output of 'rollbar suspects'
+type suspectsReport struct {
+	Counter  int              `json:"counter"`
+	Title    string           `json:"title"`
+	Range    suspectRange     `json:"range"`
+	Frames   []suspectFrame   `json:"frames"`
+	Suspects []source.Suspect `json:"suspects"`
+}
+
+func newSuspectsCmd() *cobra.Command {
+	var (
+		sourceDir string
+		pathMap   []string
+		window    time.Duration
+		limit     int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "suspects <counter>",
+		Short: "Rank commits likely to have introduced an error",
+		Long: `Rank the local commits most likely to have introduced an error.
+
+The app frames of the latest occurrence are mapped to files in the local
+repository and blamed. Commits that landed between the previous deploy (or,
+without deploys, a time window) and the occurrence's code version, before the
+item was first seen, are ranked by whether they last changed a frame's line or
+touched a frame's file, favouring frames near the top of the stack.
+
+Examples:
+  rollbar suspects 123
+  rollbar suspects 123 --path-map /app/=./
+  rollbar suspects 123 --window 72h --limit 5
+  rollbar suspects 123 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			counter, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid counter: %w", err)
+			}
+
+			mapping, err := parsePathMap(cfg.Source.PathMap, pathMap)
+			if err != nil {
+				return err
+			}
+			repo, err := source.OpenRepo(sourceDir)
+			if err != nil {
+				return err
+			}
+
+			client := newClient(false)
+
+			item, err := client.GetItemByCounter(counter)
+			if err != nil {
+				return err
+			}
+			instances, err := client.ListInstances(api.InstancesOptions{ItemID: item.ID.Int64()})
+			if err != nil {
+				return err
+			}
+			if len(instances) == 0 {
+				return fmt.Errorf("item #%d has no occurrences", counter)
+			}
+			latest := &instances[0]
+
+			resolver := source.NewResolver(sourceDir, mapping)
+			frames := blameFrames(repo, resolver, latest)
+			if len(frames) == 0 && !quiet {
+				fmt.Fprintln(os.Stderr, "Warning: no app frames map to files in this repository; try --path-map")
+			}
+
+			rng := findSuspectRange(client, repo, item, latest, window)
+			commits, err := repo.Log(source.LogOptions{
+				From:   rng.From,
+				To:     rng.To,
+				Since:  rng.Since,
+				Before: rng.FirstSeen,
+			})
+			if err != nil {
+				return err
+			}
+
+			suspects := source.RankSuspects(commits, frames)
+			if limit > 0 && len(suspects) > limit {
+				suspects = suspects[:limit]
+			}
+
+			report := suspectsReport{
+				Counter:  item.Counter,
+				Title:    item.Title,
+				Range:    rng,
+				Frames:   []suspectFrame{},
+				Suspects: suspects,
+			}
+			for _, f := range frames {
+				report.Frames = append(report.Frames, suspectFrame{Path: f.Path, Line: f.Line, Blame: f.Blame})
+			}
+
+			if output.Format(outputFormat) == output.FormatJSON {
+				return jsonFormatter().FormatValue(os.Stdout, "suspects", report)
+			}
+			writeSuspects(os.Stdout, &report)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sourceDir, "source-dir", ".", "local git repository")
+	cmd.Flags().StringArrayVar(&pathMap, "path-map", nil, "rewrite a frame path prefix to a local path, as FROM=TO (repeatable)")
+	cmd.Flags().DurationVar(&window, "window", 7*24*time.Hour, "history searched before the first occurrence when no previous deploy is known")
+	cmd.Flags().IntVar(&limit, "limit", 10, "maximum number of suspect commits")
+
+	return cmd
+}
+
+// blameFrames blames the app frames of an occurrence in the local repository,
+// setting Frame.Blame and returning the frames that map to repository files,
+// top of stack first. Lines are blamed as of the occurrence's code version
+// when it exists locally, so line numbers match the code that ran.
+func blameFrames(repo *source.Repo, resolver *source.Resolver, inst *api.Instance) []source.FrameRef {
+	root := ""
+	if inst.Data.Server != nil {
+		root = inst.Data.Server.Root
+	}
+	rev, _ := repo.Revision(inst.Data.ResolvedCodeVersion())
+
+	var refs []source.FrameRef
+	seen := map[string]bool{}
+	blameTrace := func(frames []api.Frame) {
+		for i := range frames {
+			frame := &frames[i]
+			if !output.IsAppFrame(*frame) || frame.Lineno <= 0 {
+				continue
+			}
+			path, ok := resolver.Resolve(frame.Filename, root)
+			if !ok {
+				continue
+			}
+			rel, err := repo.Rel(path)
+			if err != nil {
+				continue
+			}
+			commit, err := repo.Blame(rel, frame.Lineno, rev)
+			if err != nil {
+				continue
+			}
+			frame.Blame = &api.FrameBlame{
+				Commit:  commit.SHA,
+				Author:  commit.Author,
+				Time:    commit.Time,
+				Summary: commit.Summary,
+			}
+
+			key := fmt.Sprintf("%s:%d", rel, frame.Lineno)
+			if !seen[key] {
+				seen[key] = true
+				refs = append(refs, source.FrameRef{Path: rel, Line: frame.Lineno, Blame: commit})
+			}
+		}
+	}
+
+	body := &inst.Data.Body
+	if body.Trace != nil {
+		blameTrace(body.Trace.Frames)
+	}
+	for i := range body.TraceChain {
+		blameTrace(body.TraceChain[i].Frames)
+	}
+	return refs
+}
+
+// findSuspectRange picks the history to search: from the deploy before the one
+// that was live when the item was first seen, to the occurrence's code version
+// (or HEAD). Without a usable deploy it falls back to the window before the
+// first occurrence.
+func findSuspectRange(client *api.Client, repo *source.Repo, item *api.Item, inst *api.Instance, window time.Duration) suspectRange {
+	rng := suspectRange{
+		To:          "HEAD",
+		ToLabel:     "HEAD",
+		FirstSeen:   item.FirstOccurrenceTime,
+		Environment: item.Environment,
+	}
+	if version := inst.Data.ResolvedCodeVersion(); version != "" {
+		if sha, ok := repo.Revision(version); ok {
+			rng.To = sha
+			rng.ToLabel = "code version " + version
+		}
+	}
+	if rng.FirstSeen.IsZero() {
+		rng.FirstSeen = inst.Time
+	}
+
+	scan := deployScan{environment: item.Environment, firstSeen: rng.FirstSeen, revision: repo.Revision}
+	for page := 1; page <= maxDeployPages && rng.From == ""; page++ {
+		deploys, err := client.ListDeploys(page)
+		if err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Warning: listing deploys: %v; using a %s window\n", err, window)
+			}
+			break
+		}
+		if len(deploys) == 0 {
+			break
+		}
+		for _, d := range deploys {
+			if sha, ok := scan.previous(d); ok {
+				rng.From = sha
+				rng.FromLabel = fmt.Sprintf("deploy %s (%s)", d.Revision, d.StartTime.UTC().Format("2006-01-02 15:04"))
+				break
+			}
+		}
+	}
+
+	if rng.From == "" {
+		rng.Since = rng.FirstSeen.Add(-window)
+	}
+	return rng
+}
+
+// deployScan walks deploys, most recent first, looking for the deploy before
+// the one that was live when an item was first seen. The live deploy usually
+// shipped the failing code, so the range has to start before it.
+type deployScan struct {
+	environment string
+	firstSeen   time.Time
+	revision    func(string) (string, bool) // Resolves a deploy revision to a commit
+
+	live    bool   // The live deploy has been passed
+	liveSHA string // Its commit, if it resolves
+}
+
+// previous reports whether d is the deploy before the live one, with its commit
+func (s *deployScan) previous(d api.Deploy) (string, bool) {
+	if s.environment != "" && d.Environment != s.environment {
+		return "", false
+	}
+	if d.StartTime.IsZero() || !d.StartTime.Before(s.firstSeen) {
+		return "", false
+	}
+	sha, ok := s.revision(d.Revision)
+	if !s.live {
+		s.live, s.liveSHA = true, sha
+		return "", false
+	}
+	// Redeploys of the live commit don't start the range
+	if !ok || sha == s.liveSHA {
+		return "", false
+	}
+	return sha, true
+}
+
+func writeSuspects(w io.Writer, r *suspectsReport) {
+	fmt.Fprintf(w, "Suspects for #%d: %s\n", r.Counter, r.Title)
+	fmt.Fprintf(w, "First seen: %s", r.Range.FirstSeen.UTC().Format(time.RFC3339))
+	if r.Range.Environment != "" {
+		fmt.Fprintf(w, " (%s)", r.Range.Environment)
+	}
+	fmt.Fprintln(w)
+	if r.Range.From != "" {
+		fmt.Fprintf(w, "Range: %s .. %s\n", r.Range.FromLabel, r.Range.ToLabel)
+	} else {
+		fmt.Fprintf(w, "Range: %s .. %s (no previous deploy found)\n",
+			r.Range.Since.UTC().Format(time.RFC3339), r.Range.ToLabel)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Frame blame:")
+	if len(r.Frames) == 0 {
+		fmt.Fprintln(w, "  (no app frames found in this repository)")
+	}
+	for _, f := range r.Frames {
+		fmt.Fprintf(w, "  %s:%d  %s\n", f.Path, f.Line, commitLine(f.Blame))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Suspect commits:")
+	if len(r.Suspects) == 0 {
+		fmt.Fprintln(w, "  (no commits in range)")
+	}
+	for i, s := range r.Suspects {
+		fmt.Fprintf(w, "  %d. [%d] %s\n", i+1, s.Score, commitLine(&s.Commit))
+		for _, reason := range s.Reasons {
+			fmt.Fprintf(w, "       %s\n", reason)
+		}
+	}
+}
+
+func commitLine(c *source.Commit) string {
+	if c == nil {
+		return "?"
+	}
+	return fmt.Sprintf("%s  %s  %s  %s", c.Short(), c.Time.UTC().Format("2006-01-02"), c.Author, c.Summary)
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func TestDeployScan(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 2, 1, hour, 0, 0, 0, time.UTC) }
+	revision := func(rev string) (string, bool) {
+		if rev == "unknown" {
+			return "", false
+		}
+		return "sha-" + rev, true
+	}
+	// Most recent first, as the API lists them
+	deploys := []api.Deploy{
+		{Environment: "production", Revision: "c", StartTime: at(12)},      // After the item was first seen
+		{Environment: "production", Revision: "b", StartTime: at(10)},      // Shipped the bug
+		{Environment: "staging", Revision: "x", StartTime: at(9)},          // Other environment
+		{Environment: "production", Revision: "b", StartTime: at(8)},       // Redeploy of the buggy commit
+		{Environment: "production", Revision: "unknown", StartTime: at(7)}, // Not in this repository
+		{Environment: "production", Revision: "a", StartTime: at(6)},       // Last good deploy
+		{Environment: "production", Revision: "previous", StartTime: at(5)},
+	}
+
+	scan := deployScan{environment: "production", firstSeen: at(11), revision: revision}
+	got := ""
+	for _, d := range deploys {
+		if sha, ok := scan.previous(d); ok {
+			got = sha
+			break
+		}
+	}
+	if got != "sha-a" {
+		t.Errorf("range starts at %q, want the deploy before the one that shipped the bug (sha-a)", got)
+	}
+
+	// The only deploy before the first occurrence is the live one
+	scan = deployScan{environment: "production", firstSeen: at(11), revision: revision}
+	for _, d := range deploys[:2] {
+		if sha, ok := scan.previous(d); ok {
+			t.Errorf("expected no previous deploy, got %s", sha)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+func newTicketCmd() *cobra.Command {
+	var (
+		opts     output.TicketOptions
+		payload  bool
+		noRedact bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "ticket <counter>",
+		Short: "Write an issue-tracker ticket for an item",
+		Long: `Write a ticket for an item: a title, labels and a body in the tracker's markup
+(Markdown for GitHub and GitLab, wiki markup for Jira).
+
+The body summarizes the item, links to it on Rollbar when project_url is set,
+and shows the latest occurrence's exceptions with their app frames and what
+varies across recent occurrences. Labels are rollbar, level:<level> and
+env:<environment>. Request URLs are masked by the redaction policy.
+
+With --payload the tracker's create-issue JSON is printed instead, for a script
+to POST: GitHub's /repos/{owner}/{repo}/issues, GitLab's /projects/{id}/issues
+or Jira's /rest/api/2/issue (set --jira-project).
+
+Examples:
+  rollbar config set project_url https://rollbar.com/acme/web
+  rollbar ticket 123
+  rollbar ticket 123 --format jira
+  rollbar ticket 123 --payload | gh api repos/acme/web/issues --input -
+  rollbar ticket 123 --format jira --jira-project WEB --payload`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			counter, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid counter: %w", err)
+			}
+			if !slices.Contains(output.TicketFormats, opts.Format) {
+				return fmt.Errorf("unknown ticket format %q (available: %s)", opts.Format, strings.Join(output.TicketFormats, ", "))
+			}
+			if payload && opts.Format == "jira" && opts.JiraProject == "" {
+				return fmt.Errorf("--payload with --format jira requires --jira-project")
+			}
+
+			client := newClient(noRedact)
+			item, err := client.GetItemByCounter(counter)
+			if err != nil {
+				return err
+			}
+			instances, err := client.ListInstances(api.InstancesOptions{ItemID: item.ID.Int64()})
+			if err != nil {
+				return err
+			}
+
+			opts.Link = cfg.ItemURL(item.Counter)
+			if opts.Link == "" && !quiet {
+				fmt.Fprintln(os.Stderr, "Note: set project_url to link the ticket to Rollbar (rollbar config set project_url https://rollbar.com/ACCOUNT/PROJECT)")
+			}
+			ticket, err := output.NewTicket(item, instances, opts)
+			if err != nil {
+				return err
+			}
+
+			if payload {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(ticket.Payload(opts))
+			}
+			if output.Format(outputFormat) == output.FormatJSON {
+				return jsonFormatter().FormatValue(os.Stdout, "ticket", ticket)
+			}
+			fmt.Fprintf(os.Stdout, "Title: %s\n", ticket.Title)
+			fmt.Fprintf(os.Stdout, "Labels: %s\n\n", strings.Join(ticket.Labels, ", "))
+			_, err = os.Stdout.WriteString(ticket.Body)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Format, "format", "github", "tracker: "+strings.Join(output.TicketFormats, ", "))
+	cmd.Flags().BoolVar(&payload, "payload", false, "print the tracker's create-issue JSON")
+	cmd.Flags().StringVar(&opts.JiraProject, "jira-project", "", "Jira project key, for --payload")
+	cmd.Flags().StringVar(&opts.JiraType, "jira-issue-type", "Bug", "Jira issue type, for --payload")
+	cmd.Flags().BoolVar(&noRedact, "no-redact", false, "don't mask cookies, authorization headers, emails, tokens, card numbers and passwords")
+
+	return cmd
+}
@@ -10,23 +10,18 @@ import (
 
 // parseDuration parses human-friendly duration strings like "8 hours ago", "24h", "7 days ago"
 func parseDuration(s string) (time.Time, error) {
-	s = strings.TrimSpace(strings.ToLower(s))
-	s = strings.TrimSuffix(s, " ago")
-
-	// Try standard Go duration first (24h, 30m, etc.)
-	if d, err := time.ParseDuration(s); err == nil {
-		return time.Now().Add(-d), nil
-	}
+	s = strings.TrimSpace(s)
 
-	// Try ISO 8601 date/datetime
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return t, nil
-	}
-	if t, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
+	// Try ISO 8601 date/datetime before lowercasing, which would hide the T and Z
+	if t, err := parseTimeArg(s); err == nil {
 		return t, nil
 	}
-	if t, err := time.Parse("2006-01-02", s); err == nil {
-		return t, nil
+
+	s = strings.TrimSuffix(strings.ToLower(s), " ago")
+
+	// Try standard Go duration (24h, 30m, etc.)
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
 	}
 
 	// Parse human-friendly formats like "8 hours", "7 days", "2 weeks"
@@ -58,7 +53,8 @@ func parseDuration(s string) (time.Time, error) {
 	return time.Now().Add(-d), nil
 }
 
-// parseTimeArg parses a --from or --to argument (ISO 8601 format)
+// parseTimeArg parses a --from or --to argument (ISO 8601 format). Times
+// without an offset are in the --tz zone.
 func parseTimeArg(s string) (time.Time, error) {
 	s = strings.TrimSpace(s)
 
@@ -71,7 +67,7 @@ func parseTimeArg(s string) (time.Time, error) {
 	}
 
 	for _, format := range formats {
-		if t, err := time.Parse(format, s); err == nil {
+		if t, err := time.ParseInLocation(format, s, displayZone); err == nil {
 			return t, nil
 		}
 	}
@@ -89,3 +89,39 @@ func TestParseTimeArg(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTimeArgZone(t *testing.T) {
+	defer func(z *time.Location) { displayZone = z }(displayZone)
+	displayZone = time.FixedZone("CET", 3600)
+
+	got, err := parseTimeArg("2026-01-31T10:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 9, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseTimeArg without an offset = %v, want %v", got, want)
+	}
+
+	// An explicit offset wins over --tz
+	got, err = parseTimeArg("2026-01-31T10:30:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 31, 10, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseTimeArg with Z = %v, want %v", got, want)
+	}
+
+	// --since takes the same datetimes
+	for input, want := range map[string]time.Time{
+		"2026-01-31T10:00:00":  time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC),
+		"2026-01-31T10:00:00Z": time.Date(2026, 1, 31, 10, 0, 0, 0, time.UTC),
+		"2026-01-31":           time.Date(2026, 1, 30, 23, 0, 0, 0, time.UTC),
+	} {
+		got, err := parseDuration(input)
+		if err != nil {
+			t.Errorf("parseDuration(%q): %v", input, err)
+		} else if !got.Equal(want) {
+			t.Errorf("parseDuration(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
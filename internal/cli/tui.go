@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/robzolkos/rollbar-cli/internal/tui"
+)
+
+func newTuiCmd() *cobra.Command {
+	var filters itemFilters
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Interactive terminal UI for triage",
+		Long: `Open a full-screen triage view: a list of items matching the filters, with a
+detail pane showing the selected item's latest occurrence.
+
+Keys:
+  j/k, arrows    move through items
+  n/p            page through occurrences of the selected item
+  c, enter       open full context
+  r / m          resolve / mute the selected item
+  a              assign the selected item to a user ID
+  [ / ]          previous / next page of items
+  ?              help, q to quit
+
+Examples:
+  rollbar tui                                # Active items
+  rollbar tui --env production --level error,critical
+  rollbar tui --since 24h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				return err
+			}
+
+			opts, err := filters.options()
+			if err != nil {
+				return err
+			}
+
+			client := newClient(false)
+			return tui.Run(client, opts)
+		},
+	}
+
+	filters.addFlags(cmd)
+
+	return cmd
+}
@@ -4,8 +4,6 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
-
-	"github.com/robzolkos/rollbar-cli/internal/api"
 )
 
 func newWhoamiCmd() *cobra.Command {
@@ -21,7 +19,7 @@ Examples:
 				return err
 			}
 
-			client := api.NewClient(cfg.AccessToken)
+			client := newClient(false)
 			info, err := client.GetProjectInfo()
 			if err != nil {
 				return err
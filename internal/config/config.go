@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,7 +16,12 @@ type Config struct {
 	AccessToken        string       `yaml:"access_token" json:"access_token"`
 	ProjectID          int          `yaml:"project_id" json:"project_id"`
 	DefaultEnvironment string       `yaml:"default_environment" json:"default_environment"`
+	ProjectURL         string       `yaml:"project_url,omitempty" json:"project_url,omitempty"` // Project page, e.g. https://rollbar.com/acme/web
+	Timezone           string       `yaml:"timezone,omitempty" json:"timezone,omitempty"`       // Zone times are shown in, e.g. Europe/Berlin (default local)
 	Output             OutputConfig `yaml:"output" json:"output"`
+	MCP                MCPConfig    `yaml:"mcp,omitempty" json:"mcp,omitempty"`
+	Source             SourceConfig `yaml:"source,omitempty" json:"source,omitempty"`
+	Redact             RedactConfig `yaml:"redact,omitempty" json:"redact,omitempty"`
 }
 
 // OutputConfig configures output formatting
@@ -23,6 +30,28 @@ type OutputConfig struct {
 	Color  string `yaml:"color" json:"color"`   // auto | always | never
 }
 
+// MCPConfig configures the 'rollbar mcp' server
+type MCPConfig struct {
+	AllowWrites bool `yaml:"allow_writes" json:"allow_writes"` // Expose tools that modify items
+}
+
+// SourceConfig maps stack frame paths to files in the local working tree
+type SourceConfig struct {
+	// PathMap rewrites frame path prefixes to local paths, e.g. "/app/": "./".
+	// The longest matching prefix wins.
+	PathMap      map[string]string `yaml:"path_map,omitempty" json:"path_map,omitempty"`
+	ContextLines *int              `yaml:"context_lines,omitempty" json:"context_lines,omitempty"` // Lines around each frame (default 3)
+}
+
+// RedactConfig controls masking of request and custom data in occurrence output.
+// Cookies, authorization headers, emails, tokens, card numbers and password
+// keys are always masked unless Disabled is set.
+type RedactConfig struct {
+	Disabled bool     `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Keys     []string `yaml:"keys,omitempty" json:"keys,omitempty"`   // Extra key patterns to mask
+	Allow    []string `yaml:"allow,omitempty" json:"allow,omitempty"` // Keys to show even when they match a pattern
+}
+
 // GlobalConfig represents the global ~/.config/rollbar/config.yaml format
 type GlobalConfig struct {
 	Profiles       map[string]Profile `yaml:"profiles" json:"profiles"`
@@ -214,6 +243,27 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// ItemURL returns the Rollbar page of an item, or "" without a project URL
+func (c *Config) ItemURL(counter int) string {
+	if c.ProjectURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/items/%d/", strings.TrimSuffix(c.ProjectURL, "/"), counter)
+}
+
+// ParseTimezone returns the zone for an IANA name such as America/New_York,
+// UTC, or "local" (also "") for the system zone
+func ParseTimezone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (expected e.g. UTC, Europe/Berlin or local)", name)
+	}
+	return loc, nil
+}
+
 // ConfigPath returns the path where a project config would be saved
 func ConfigPath() string {
 	return ".rollbar.yaml"
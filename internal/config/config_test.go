@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadFromEnv(t *testing.T) {
@@ -163,3 +164,34 @@ func TestSave(t *testing.T) {
 		t.Errorf("expected project_id 123, got %d", loaded.ProjectID)
 	}
 }
+
+func TestItemURL(t *testing.T) {
+	tests := []struct {
+		projectURL string
+		want       string
+	}{
+		{"", ""},
+		{"https://rollbar.com/acme/web", "https://rollbar.com/acme/web/items/42/"},
+		{"https://rollbar.com/acme/web/", "https://rollbar.com/acme/web/items/42/"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{ProjectURL: tt.projectURL}
+		if got := cfg.ItemURL(42); got != tt.want {
+			t.Errorf("ItemURL with %q = %q, want %q", tt.projectURL, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimezone(t *testing.T) {
+	for _, name := range []string{"", "local", "Local"} {
+		if loc, err := ParseTimezone(name); err != nil || loc != time.Local {
+			t.Errorf("ParseTimezone(%q) = %v, %v; want the local zone", name, loc, err)
+		}
+	}
+	if loc, err := ParseTimezone("UTC"); err != nil || loc != time.UTC {
+		t.Errorf("ParseTimezone(UTC) = %v, %v", loc, err)
+	}
+	if _, err := ParseTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an error for an unknown zone")
+	}
+}
@@ -0,0 +1,481 @@
+// Package diff compares two occurrences field by field, as a JSON Patch and
+// as a unified text view.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// ANSI color codes for the unified view
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorCyan  = "\033[36m"
+	colorGray  = "\033[90m"
+	colorBold  = "\033[1m"
+)
+
+// contextLines is how many unchanged lines are kept around each change
+const contextLines = 2
+
+// sections are the top-level keys of a normalized occurrence, in display order
+var sections = []string{
+	"item_id", "environment", "level", "code_version", "browser",
+	"exception", "frames", "request", "person", "server", "custom",
+}
+
+// Op is a JSON Patch (RFC 6902) operation that turns the first occurrence's
+// normalized document into the second's. Old is an extension member holding
+// the replaced or removed value; patch tools ignore it.
+type Op struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"-"`
+	Old   interface{} `json:"-"`
+}
+
+// MarshalJSON includes value and old only for the operations that have them,
+// so that null and empty values are still written
+func (o Op) MarshalJSON() ([]byte, error) {
+	type op struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value,omitempty"`
+		Old   json.RawMessage `json:"old,omitempty"`
+	}
+	out := op{Op: o.Op, Path: o.Path}
+	var err error
+	if o.Op != "remove" {
+		if out.Value, err = json.Marshal(o.Value); err != nil {
+			return nil, err
+		}
+	}
+	if o.Op != "add" {
+		if out.Old, err = json.Marshal(o.Old); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(out)
+}
+
+// Result is the comparison of two occurrences
+type Result struct {
+	From, To *api.Instance
+	Patch    []Op
+
+	fromDoc, toDoc map[string]interface{}
+	frames         []framePair
+}
+
+// framePair is a stack frame aligned across both occurrences; a key is empty
+// when the frame exists in only one of them
+type framePair struct {
+	from, to string
+}
+
+// Instances compares two occurrences. Timestamps and occurrence IDs are
+// ignored; frames are aligned by filename and method, so a shifted line
+// number shows up as a change to that frame rather than a different stack.
+func Instances(from, to *api.Instance) *Result {
+	fromDoc, fromFrames := Document(from)
+	toDoc, toFrames := Document(to)
+
+	r := &Result{From: from, To: to, fromDoc: fromDoc, toDoc: toDoc, Patch: []Op{}}
+	r.frames = alignFrames(fromFrames, toFrames)
+	for _, section := range sections {
+		diffValues(&r.Patch, "/"+section, fromDoc[section], toDoc[section])
+	}
+	return r
+}
+
+// Identical reports whether the occurrences have no compared differences
+func (r *Result) Identical() bool {
+	return len(r.Patch) == 0
+}
+
+// Document normalizes an occurrence into the tree that patches apply to,
+// leaving out empty fields. It also returns the frame keys in stack order.
+func Document(inst *api.Instance) (map[string]interface{}, []string) {
+	data := &inst.Data
+	doc := map[string]interface{}{
+		"item_id":      inst.ItemID,
+		"environment":  data.Environment,
+		"level":        data.Level,
+		"code_version": data.ResolvedCodeVersion(),
+	}
+	if data.Client != nil && data.Client.JavaScript != nil {
+		doc["browser"] = data.Client.JavaScript.Browser
+	}
+
+	exception := map[string]interface{}{}
+	frames := map[string]interface{}{}
+	var frameKeys []string
+	seen := map[string]int{}
+	for i, trace := range data.Body.Traces() {
+		if i == 0 {
+			exception["class"] = trace.Exception.Class
+			exception["message"] = trace.Exception.Message
+			exception["description"] = trace.Exception.Description
+		} else {
+			exception[fmt.Sprintf("cause_%d", i)] = trace.Exception.Class + ": " + trace.Exception.Message
+		}
+		for _, frame := range trace.Frames {
+			key := frame.Filename + ":" + frame.Method
+			seen[key]++
+			if n := seen[key]; n > 1 {
+				key = fmt.Sprintf("%s#%d", key, n)
+			}
+			frameKeys = append(frameKeys, key)
+			frames[key] = map[string]interface{}{
+				"filename": frame.Filename,
+				"method":   frame.Method,
+				"lineno":   frame.Lineno,
+				"colno":    frame.Colno,
+				"code":     frame.Code,
+			}
+		}
+	}
+	if data.Body.Message != nil {
+		exception["message"] = data.Body.Message.Body
+	}
+	if data.Body.CrashReport != nil {
+		exception["crash_report"] = data.Body.CrashReport.Raw
+	}
+	doc["exception"] = exception
+	doc["frames"] = frames
+
+	if req := data.Request; req != nil {
+		doc["request"] = map[string]interface{}{
+			"method":       req.Method,
+			"url":          req.URL,
+			"headers":      req.Headers,
+			"params":       req.Params,
+			"GET":          req.GET,
+			"POST":         req.POST,
+			"body":         req.Body,
+			"query_string": req.QueryString,
+			"user_ip":      req.UserIP,
+		}
+	}
+	if p := data.Person; p != nil {
+		doc["person"] = map[string]interface{}{"id": p.ID, "username": p.Username, "email": p.Email}
+	}
+	if s := data.Server; s != nil {
+		doc["server"] = map[string]interface{}{
+			"host":         s.Host,
+			"root":         s.Root,
+			"branch":       s.Branch,
+			"code_version": s.CodeVersion,
+			"argv":         s.Argv,
+			"pid":          s.PID,
+		}
+	}
+	doc["custom"] = data.Custom
+
+	// Round-trip through JSON so both sides use the same types, then drop
+	// empty values so missing and blank fields compare equal
+	var normalized map[string]interface{}
+	b, _ := json.Marshal(doc)
+	_ = json.Unmarshal(b, &normalized)
+	normalized, _ = prune(normalized).(map[string]interface{})
+	if normalized == nil {
+		normalized = map[string]interface{}{}
+	}
+	return normalized, frameKeys
+}
+
+// prune removes empty strings, zero numbers, nulls and empty containers
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for k, child := range v {
+			if child = prune(child); child != nil {
+				out[k] = child
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+	case string:
+		if v == "" {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return v
+}
+
+// diffValues appends the operations that turn a into b at path
+func diffValues(ops *[]Op, path string, a, b interface{}) {
+	am, aIsMap := a.(map[string]interface{})
+	bm, bIsMap := b.(map[string]interface{})
+	switch {
+	case aIsMap && bIsMap:
+		for _, key := range unionKeys(am, bm) {
+			diffValues(ops, path+"/"+escapePointer(key), am[key], bm[key])
+		}
+	case isEmpty(a) && isEmpty(b):
+	case isEmpty(a):
+		*ops = append(*ops, Op{Op: "add", Path: path, Value: b})
+	case isEmpty(b):
+		*ops = append(*ops, Op{Op: "remove", Path: path, Old: a})
+	case !reflect.DeepEqual(a, b):
+		*ops = append(*ops, Op{Op: "replace", Path: path, Value: b, Old: a})
+	}
+}
+
+func isEmpty(v interface{}) bool {
+	if m, ok := v.(map[string]interface{}); ok {
+		return len(m) == 0
+	}
+	return v == nil
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapePointer escapes a JSON Pointer reference token
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// alignFrames pairs frames with the same key using a longest common
+// subsequence, keeping unmatched frames in stack order
+func alignFrames(a, b []string) []framePair {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs []framePair
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			pairs = append(pairs, framePair{a[i], b[j]})
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			pairs = append(pairs, framePair{from: a[i]})
+			i++
+		default:
+			pairs = append(pairs, framePair{to: b[j]})
+			j++
+		}
+	}
+	return pairs
+}
+
+// line is one line of the unified view
+type line struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// WriteUnified writes a unified-diff style view of the comparison, grouped
+// by section, with unchanged lines collapsed around the changes
+func (r *Result) WriteUnified(w io.Writer, color bool) {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	fmt.Fprintln(w, paint(colorBold+colorRed, fmt.Sprintf("--- occurrence %d (%s)", r.From.ID, output.FormatTime(r.From.Time))))
+	fmt.Fprintln(w, paint(colorBold+colorGreen, fmt.Sprintf("+++ occurrence %d (%s)", r.To.ID, output.FormatTime(r.To.Time))))
+	if r.Identical() {
+		fmt.Fprintln(w, "No differences (timestamps and occurrence IDs are not compared)")
+		return
+	}
+
+	for _, section := range sections {
+		var lines []line
+		if section == "frames" {
+			lines = r.frameLines()
+		} else {
+			lines = leafLines("", r.fromDoc[section], r.toDoc[section])
+		}
+		if !hasChanges(lines) {
+			continue
+		}
+
+		fmt.Fprintln(w, paint(colorCyan, "@@ "+section+" @@"))
+		for _, h := range hunks(lines) {
+			if h.skipped > 0 {
+				fmt.Fprintln(w, paint(colorGray, fmt.Sprintf("  ... %d unchanged", h.skipped)))
+				continue
+			}
+			switch h.line.kind {
+			case '-':
+				fmt.Fprintln(w, paint(colorRed, "-"+h.line.text))
+			case '+':
+				fmt.Fprintln(w, paint(colorGreen, "+"+h.line.text))
+			default:
+				fmt.Fprintln(w, " "+h.line.text)
+			}
+		}
+	}
+}
+
+// leafLines lists the leaf values of a section as "name: value" lines
+func leafLines(prefix string, a, b interface{}) []line {
+	am, aIsMap := a.(map[string]interface{})
+	bm, bIsMap := b.(map[string]interface{})
+	if aIsMap || bIsMap {
+		var lines []line
+		for _, key := range unionKeys(am, bm) {
+			name := key
+			if prefix != "" {
+				name = prefix + "." + key
+			}
+			lines = append(lines, leafLines(name, am[key], bm[key])...)
+		}
+		return lines
+	}
+
+	switch {
+	case reflect.DeepEqual(a, b):
+		return []line{{' ', prefix + ": " + render(a)}}
+	case a == nil:
+		return []line{{'+', prefix + ": " + render(b)}}
+	case b == nil:
+		return []line{{'-', prefix + ": " + render(a)}}
+	}
+	return []line{{'-', prefix + ": " + render(a)}, {'+', prefix + ": " + render(b)}}
+}
+
+// frameLines lists the aligned frames as "file:line in method()" lines, with
+// the source line when it differs
+func (r *Result) frameLines() []line {
+	fromFrames, _ := r.fromDoc["frames"].(map[string]interface{})
+	toFrames, _ := r.toDoc["frames"].(map[string]interface{})
+
+	var lines []line
+	for _, p := range r.frames {
+		a, _ := fromFrames[p.from].(map[string]interface{})
+		b, _ := toFrames[p.to].(map[string]interface{})
+		switch {
+		case p.to == "":
+			lines = append(lines, line{'-', frameText(a)})
+		case p.from == "":
+			lines = append(lines, line{'+', frameText(b)})
+		case reflect.DeepEqual(a, b):
+			lines = append(lines, line{' ', frameText(a)})
+		default:
+			lines = append(lines, line{'-', frameText(a)}, line{'+', frameText(b)})
+			if !reflect.DeepEqual(a["code"], b["code"]) {
+				lines = append(lines, line{'-', "    " + render(a["code"])}, line{'+', "    " + render(b["code"])})
+			}
+		}
+	}
+	return lines
+}
+
+func frameText(frame map[string]interface{}) string {
+	text := fmt.Sprintf("%s:%s", render(frame["filename"]), render(frame["lineno"]))
+	if method, ok := frame["method"].(string); ok {
+		text += " in " + method + "()"
+	}
+	return text
+}
+
+// render formats a leaf value on one line
+func render(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func hasChanges(lines []line) bool {
+	for _, l := range lines {
+		if l.kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// hunk is either a line to print or a count of collapsed unchanged lines
+type hunk struct {
+	line    line
+	skipped int
+}
+
+// hunks keeps changed lines and up to contextLines unchanged lines on either
+// side of them, collapsing the rest
+func hunks(lines []line) []hunk {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.kind == ' ' {
+			continue
+		}
+		for j := max(i-contextLines, 0); j <= min(i+contextLines, len(lines)-1); j++ {
+			keep[j] = true
+		}
+	}
+
+	var out []hunk
+	skipped := 0
+	for i, l := range lines {
+		if !keep[i] {
+			skipped++
+			continue
+		}
+		if skipped > 0 {
+			out = append(out, hunk{skipped: skipped})
+			skipped = 0
+		}
+		out = append(out, hunk{line: l})
+	}
+	if skipped > 0 {
+		out = append(out, hunk{skipped: skipped})
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+func checkoutInstance(id int64) *api.Instance {
+	return &api.Instance{
+		ID:     id,
+		ItemID: 42,
+		Data: api.InstanceData{
+			Environment: "production",
+			CodeVersion: "abc123",
+			Body: api.Body{Trace: &api.Trace{
+				Exception: api.Exception{Class: "KeyError", Message: "key not found: :sku"},
+				Frames: []api.Frame{
+					{Filename: "/app/app/services/cart.rb", Lineno: 10, Method: "total"},
+					{Filename: "/app/app/models/line_item.rb", Lineno: 5, Method: "Pricing::Rules.price"},
+					{Filename: "/app/app/controllers/checkout_controller.rb", Lineno: 20, Method: "create"},
+				},
+			}},
+			Request: &api.Request{
+				Method:  "POST",
+				URL:     "https://example.com/checkout",
+				Headers: map[string]string{"User-Agent": "Safari", "Accept": "text/html"},
+				POST:    map[string]interface{}{"coupon": "SPRING", "qty": float64(1)},
+			},
+			Person: &api.Person{ID: "7"},
+			Custom: map[string]interface{}{"tenant": "acme"},
+		},
+	}
+}
+
+func TestInstances(t *testing.T) {
+	from := checkoutInstance(1)
+	to := checkoutInstance(2)
+	to.Data.Body.Trace.Frames = []api.Frame{
+		{Filename: "/app/app/services/cart.rb", Lineno: 12, Method: "total"},
+		{Filename: "/app/app/services/discount.rb", Lineno: 3, Method: "apply"},
+		{Filename: "/app/app/models/line_item.rb", Lineno: 5, Method: "Pricing::Rules.price"},
+		{Filename: "/app/app/controllers/checkout_controller.rb", Lineno: 20, Method: "create"},
+	}
+	to.Data.Request.Headers = map[string]string{"User-Agent": "Chrome", "Accept": "text/html"}
+	to.Data.Request.POST = map[string]interface{}{"qty": float64(1)}
+	to.Data.Custom = map[string]interface{}{"tenant": "acme", "beta": true}
+
+	if !Instances(from, checkoutInstance(3)).Identical() {
+		t.Error("expected occurrences differing only by ID to be identical")
+	}
+
+	result := Instances(from, to)
+	var got []string
+	for _, op := range result.Patch {
+		got = append(got, op.Op+" "+op.Path)
+	}
+	want := []string{
+		"replace /frames/~1app~1app~1services~1cart.rb:total/lineno",
+		"add /frames/~1app~1app~1services~1discount.rb:apply",
+		"remove /request/POST/coupon",
+		"replace /request/headers/User-Agent",
+		"add /custom/beta",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected patch:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	b, err := json.Marshal(result.Patch[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"op":"replace","path":"/request/headers/User-Agent","value":"Chrome","old":"Safari"}` {
+		t.Errorf("unexpected op JSON: %s", b)
+	}
+	b, _ = json.Marshal(result.Patch[2])
+	if string(b) != `{"op":"remove","path":"/request/POST/coupon","old":"SPRING"}` {
+		t.Errorf("unexpected op JSON: %s", b)
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	defer output.SetTimeDisplay(time.Local, output.TimeRelative)
+	if err := output.SetTimeDisplay(time.FixedZone("CET", 3600), output.TimeISO); err != nil {
+		t.Fatal(err)
+	}
+
+	from := checkoutInstance(1)
+	from.Time = time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	to := checkoutInstance(2)
+	to.Data.Body.Trace.Frames[0].Lineno = 12
+	to.Data.Person = &api.Person{ID: "8"}
+
+	var buf bytes.Buffer
+	Instances(from, to).WriteUnified(&buf, false)
+	out := buf.String()
+
+	for _, s := range []string{
+		"@@ frames @@",
+		"-/app/app/services/cart.rb:10 in total()",
+		"+/app/app/services/cart.rb:12 in total()",
+		" /app/app/models/line_item.rb:5 in Pricing::Rules.price()",
+		"@@ person @@",
+		"-id: 7",
+		"+id: 8",
+	} {
+		if !strings.Contains(out, s+"\n") {
+			t.Errorf("expected %q in output:\n%s", s, out)
+		}
+	}
+	if !strings.HasPrefix(out, "--- occurrence 1 (2026-03-01T10:30:00+01:00)\n") || !strings.Contains(out, "\n+++ occurrence 2 (") {
+		t.Errorf("unexpected header:\n%s", out)
+	}
+	if strings.Contains(out, "@@ request @@") {
+		t.Error("expected unchanged sections to be left out")
+	}
+	if strings.Contains(out, "\033[") {
+		t.Error("expected no color codes")
+	}
+
+	buf.Reset()
+	Instances(from, checkoutInstance(3)).WriteUnified(&buf, false)
+	if !strings.Contains(buf.String(), "No differences") {
+		t.Errorf("expected no differences, got:\n%s", buf.String())
+	}
+}
+
+func TestHunks(t *testing.T) {
+	var lines []line
+	for i := 0; i < 10; i++ {
+		lines = append(lines, line{' ', "same"})
+	}
+	lines[5] = line{'-', "changed"}
+
+	var got []string
+	for _, h := range hunks(lines) {
+		if h.skipped > 0 {
+			got = append(got, "skip")
+		} else {
+			got = append(got, string(h.line.kind))
+		}
+	}
+	if strings.Join(got, ",") != "skip, , ,-, , ,skip" {
+		t.Errorf("unexpected hunks: %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+// Package exporter serves Rollbar item metrics in the Prometheus text format.
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// Lister is the subset of the Rollbar API used by the exporter
+type Lister interface {
+	ListItems(opts api.ItemsOptions) ([]api.Item, int, error)
+}
+
+// Options configures what the exporter polls and exposes
+type Options struct {
+	Environment string // Filter items by environment
+	Level       string // Filter items by level (comma-separated)
+	Pages       int    // Most item pages to read per poll
+	TopN        int    // Number of items exposed with per-item series
+}
+
+// Exporter polls Rollbar and caches the latest metrics. Scrapes are served from
+// the cache so they never trigger API calls of their own.
+type Exporter struct {
+	lister Lister
+	opts   Options
+
+	mu            sync.RWMutex
+	items         []api.Item
+	truncated     bool // The last successful poll stopped at opts.Pages
+	lastScrape    time.Time
+	lastDuration  time.Duration
+	lastSuccess   bool
+	scrapeErrors  int
+	scrapesTotal  int
+	rateRemaining func() int
+}
+
+// New creates an exporter. rateRemaining, if set, reports the client's
+// remaining Rollbar rate-limit budget.
+func New(lister Lister, opts Options, rateRemaining func() int) *Exporter {
+	if opts.Pages < 1 {
+		opts.Pages = 1
+	}
+	return &Exporter{
+		lister:        lister,
+		opts:          opts,
+		rateRemaining: rateRemaining,
+	}
+}
+
+// Poll fetches active items and updates the cached metrics. Pages are read
+// until a short one, up to opts.Pages.
+func (e *Exporter) Poll() error {
+	start := time.Now()
+	var items []api.Item
+	var err error
+	truncated := false
+
+	for page := 1; page <= e.opts.Pages; page++ {
+		var batch []api.Item
+		batch, _, err = e.lister.ListItems(api.ItemsOptions{
+			Status:      "active",
+			Level:       e.opts.Level,
+			Environment: e.opts.Environment,
+			Page:        page,
+		})
+		if err != nil {
+			break
+		}
+		items = append(items, batch...)
+		if len(batch) < api.ItemsPageSize {
+			break
+		}
+		truncated = page == e.opts.Pages
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scrapesTotal++
+	e.lastScrape = start
+	e.lastDuration = time.Since(start)
+	e.lastSuccess = err == nil
+	if err != nil {
+		// Keep serving the previous items
+		e.scrapeErrors++
+		return err
+	}
+	e.items, e.truncated = items, truncated
+	return nil
+}
+
+// Run polls every interval until stop is closed
+func (e *Exporter) Run(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Poll(); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ServeHTTP writes the cached metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteMetrics(w)
+}
+
+// WriteMetrics renders the cached metrics in the Prometheus text format
+func (e *Exporter) WriteMetrics(w io.Writer) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	// Active items by level and environment
+	type levelEnv struct{ level, env string }
+	active := map[levelEnv]int{}
+	for _, item := range e.items {
+		active[levelEnv{item.LevelString, item.Environment}]++
+	}
+	keys := make([]levelEnv, 0, len(active))
+	for k := range active {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return keys[i].level < keys[j].level
+		}
+		return keys[i].env < keys[j].env
+	})
+
+	writeHeader(w, "rollbar_active_items", "gauge", "Number of active items by level and environment.")
+	for _, k := range keys {
+		fmt.Fprintf(w, "rollbar_active_items{level=%s,env=%s} %d\n", label(k.level), label(k.env), active[k])
+	}
+
+	// Per-item occurrence totals, bounded to the top N items
+	top := make([]api.Item, len(e.items))
+	copy(top, e.items)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].TotalOccurrences > top[j].TotalOccurrences
+	})
+	if e.opts.TopN >= 0 && len(top) > e.opts.TopN {
+		top = top[:e.opts.TopN]
+	}
+
+	writeHeader(w, "rollbar_item_occurrences_total", "counter", "Total occurrences of the top items by occurrence count.")
+	for _, item := range top {
+		fmt.Fprintf(w, "rollbar_item_occurrences_total{counter=\"%d\",level=%s} %d\n",
+			item.Counter, label(item.LevelString), item.TotalOccurrences)
+	}
+
+	// Scrape health
+	writeHeader(w, "rollbar_exporter_scrape_success", "gauge", "Whether the last poll of the Rollbar API succeeded.")
+	fmt.Fprintf(w, "rollbar_exporter_scrape_success %d\n", boolValue(e.lastSuccess))
+	writeHeader(w, "rollbar_exporter_scrape_duration_seconds", "gauge", "Duration of the last poll of the Rollbar API.")
+	fmt.Fprintf(w, "rollbar_exporter_scrape_duration_seconds %g\n", e.lastDuration.Seconds())
+	writeHeader(w, "rollbar_exporter_last_scrape_timestamp_seconds", "gauge", "Unix time of the last poll of the Rollbar API.")
+	if e.lastScrape.IsZero() {
+		fmt.Fprintln(w, "rollbar_exporter_last_scrape_timestamp_seconds 0")
+	} else {
+		fmt.Fprintf(w, "rollbar_exporter_last_scrape_timestamp_seconds %d\n", e.lastScrape.Unix())
+	}
+	writeHeader(w, "rollbar_exporter_items_truncated", "gauge", "Whether the last poll stopped at the page limit, so active items may be uncounted.")
+	fmt.Fprintf(w, "rollbar_exporter_items_truncated %d\n", boolValue(e.truncated))
+	writeHeader(w, "rollbar_exporter_scrapes_total", "counter", "Polls of the Rollbar API.")
+	fmt.Fprintf(w, "rollbar_exporter_scrapes_total %d\n", e.scrapesTotal)
+	writeHeader(w, "rollbar_exporter_scrape_errors_total", "counter", "Failed polls of the Rollbar API.")
+	fmt.Fprintf(w, "rollbar_exporter_scrape_errors_total %d\n", e.scrapeErrors)
+
+	if e.rateRemaining != nil {
+		if remaining := e.rateRemaining(); remaining >= 0 {
+			writeHeader(w, "rollbar_exporter_rate_limit_remaining", "gauge", "Remaining Rollbar API requests in the current rate-limit window.")
+			fmt.Fprintf(w, "rollbar_exporter_rate_limit_remaining %d\n", remaining)
+		}
+	}
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// labelEscaper applies the escapes allowed in Prometheus label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes a label value
+func label(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+type fakeLister struct {
+	items []api.Item
+	err   error
+	opts  []api.ItemsOptions
+}
+
+func (l *fakeLister) ListItems(opts api.ItemsOptions) ([]api.Item, int, error) {
+	l.opts = append(l.opts, opts)
+	if l.err != nil {
+		return nil, 0, l.err
+	}
+	start := min((opts.Page-1)*api.ItemsPageSize, len(l.items))
+	end := min(start+api.ItemsPageSize, len(l.items))
+	return l.items[start:end], opts.Page, nil
+}
+
+func testItems() []api.Item {
+	return []api.Item{
+		{Counter: 1, LevelString: "error", Environment: "production", TotalOccurrences: 10},
+		{Counter: 2, LevelString: "error", Environment: "production", TotalOccurrences: 500},
+		{Counter: 3, LevelString: "warning", Environment: "staging", TotalOccurrences: 42},
+		{Counter: 4, LevelString: "error", Environment: `we"ird`, TotalOccurrences: 1},
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	lister := &fakeLister{items: testItems()}
+	e := New(lister, Options{Environment: "production", Pages: 2, TopN: 2}, func() int { return 4999 })
+	if err := e.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	if len(lister.opts) != 1 || lister.opts[0].Status != "active" || lister.opts[0].Environment != "production" {
+		t.Errorf("unexpected list options: %+v", lister.opts)
+	}
+
+	var b strings.Builder
+	e.WriteMetrics(&b)
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE rollbar_active_items gauge",
+		`rollbar_active_items{level="error",env="production"} 2`,
+		`rollbar_active_items{level="warning",env="staging"} 1`,
+		`rollbar_active_items{level="error",env="we\"ird"} 1`,
+		"# TYPE rollbar_item_occurrences_total counter",
+		`rollbar_item_occurrences_total{counter="2",level="error"} 500`,
+		`rollbar_item_occurrences_total{counter="3",level="warning"} 42`,
+		"rollbar_exporter_scrape_success 1",
+		"rollbar_exporter_scrapes_total 1",
+		"rollbar_exporter_scrape_errors_total 0",
+		"rollbar_exporter_items_truncated 0",
+		"rollbar_exporter_rate_limit_remaining 4999",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in metrics output:\n%s", want, out)
+		}
+	}
+
+	// Top-N bounds per-item series
+	if strings.Contains(out, `counter="1"`) {
+		t.Errorf("expected item #1 to be outside the top 2:\n%s", out)
+	}
+}
+
+func TestPollPages(t *testing.T) {
+	items := make([]api.Item, 250)
+	for i := range items {
+		items[i] = api.Item{Counter: i + 1, LevelString: "error", Environment: "production"}
+	}
+
+	tests := []struct {
+		pages         int
+		wantRequests  int
+		wantActive    int
+		wantTruncated int
+	}{
+		{10, 3, 250, 0},
+		{3, 3, 250, 0},
+		{2, 2, 200, 1},
+	}
+	for _, tt := range tests {
+		lister := &fakeLister{items: items}
+		e := New(lister, Options{Pages: tt.pages}, nil)
+		if err := e.Poll(); err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		e.WriteMetrics(&b)
+		out := b.String()
+
+		if len(lister.opts) != tt.wantRequests {
+			t.Errorf("pages %d: expected %d requests, got %d", tt.pages, tt.wantRequests, len(lister.opts))
+		}
+		for _, want := range []string{
+			fmt.Sprintf(`rollbar_active_items{level="error",env="production"} %d`, tt.wantActive),
+			fmt.Sprintf("rollbar_exporter_items_truncated %d", tt.wantTruncated),
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("pages %d: expected %q in metrics output:\n%s", tt.pages, want, out)
+			}
+		}
+	}
+}
+
+func TestPollErrorKeepsPreviousItems(t *testing.T) {
+	lister := &fakeLister{items: testItems()}
+	e := New(lister, Options{TopN: 10}, nil)
+	if err := e.Poll(); err != nil {
+		t.Fatalf("Poll failed: %v", err)
+	}
+
+	lister.err = errors.New("boom")
+	if err := e.Poll(); err == nil {
+		t.Fatal("expected poll error")
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		"rollbar_exporter_scrape_success 0",
+		"rollbar_exporter_scrape_errors_total 1",
+		`rollbar_item_occurrences_total{counter="2",level="error"} 500`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in metrics output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "rate_limit_remaining") {
+		t.Error("expected no rate limit metric without a rate reporter")
+	}
+}
@@ -0,0 +1,748 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtin implements a function called with unevaluated argument expressions
+type builtin func(e *env, in interface{}, args []node) ([]interface{}, error)
+
+type callNode struct {
+	name string
+	fn   builtin
+	args []node
+}
+
+func (n *callNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	return n.fn(e, in, n.args)
+}
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"empty/0": func(*env, interface{}, []node) ([]interface{}, error) { return nil, nil },
+		"error/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			msg, err := evalOne(e, in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return nil, &valueError{value: msg}
+		},
+		"not/0":     simple(func(v interface{}) (interface{}, error) { return !truthy(v), nil }),
+		"length/0":  simple(length),
+		"type/0":    simple(func(v interface{}) (interface{}, error) { return typeName(v), nil }),
+		"keys/0":    simple(keys),
+		"has/1":     withArg(has),
+		"add/0":     simple(add),
+		"first/0":   simple(func(v interface{}) (interface{}, error) { return index(v, 0.0) }),
+		"last/0":    simple(func(v interface{}) (interface{}, error) { return index(v, -1.0) }),
+		"nth/1":     withArg(func(v, n interface{}) (interface{}, error) { return index(v, n) }),
+		"reverse/0": simple(reverse),
+		"sort/0": simple(func(v interface{}) (interface{}, error) {
+			return sortBy(v, func(x interface{}) (interface{}, error) { return x, nil })
+		}),
+		"unique/0": simple(func(v interface{}) (interface{}, error) {
+			return uniqueBy(v, func(x interface{}) (interface{}, error) { return x, nil })
+		}),
+		"min/0":     simple(func(v interface{}) (interface{}, error) { return extreme(v, identityKey, -1) }),
+		"max/0":     simple(func(v interface{}) (interface{}, error) { return extreme(v, identityKey, 1) }),
+		"flatten/0": simple(func(v interface{}) (interface{}, error) { return flatten(v, -1) }),
+		"any/0":     simple(func(v interface{}) (interface{}, error) { return anyAll(v, true) }),
+		"all/0":     simple(func(v interface{}) (interface{}, error) { return anyAll(v, false) }),
+		"floor/0":   numeric(math.Floor),
+		"ceil/0":    numeric(math.Ceil),
+		"round/0":   numeric(math.Round),
+		"tostring/0": simple(func(v interface{}) (interface{}, error) {
+			return toText(v)
+		}),
+		"tonumber/0": simple(tonumber),
+		"tojson/0": simple(func(v interface{}) (interface{}, error) {
+			return Encode(v, "")
+		}),
+		"fromjson/0": simple(func(v interface{}) (interface{}, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s (%s) cannot be parsed as JSON", typeName(v), short(v))
+			}
+			return decode(s)
+		}),
+		"ascii_downcase/0": stringFunc(strings.ToLower),
+		"ascii_upcase/0":   stringFunc(strings.ToUpper),
+		"todate/0": simple(func(v interface{}) (interface{}, error) {
+			f, ok := toFloat(v)
+			if !ok {
+				return nil, fmt.Errorf("todate requires a number, not %s", typeName(v))
+			}
+			return time.Unix(int64(f), 0).UTC().Format(time.RFC3339), nil
+		}),
+		"to_entries/0":   simple(toEntries),
+		"from_entries/0": simple(fromEntries),
+		"startswith/1":   stringArg(strings.HasPrefix),
+		"endswith/1":     stringArg(strings.HasSuffix),
+		"ltrimstr/1": withArg(func(v, a interface{}) (interface{}, error) {
+			s, ok1 := v.(string)
+			p, ok2 := a.(string)
+			if ok1 && ok2 {
+				return strings.TrimPrefix(s, p), nil
+			}
+			return v, nil
+		}),
+		"rtrimstr/1": withArg(func(v, a interface{}) (interface{}, error) {
+			s, ok1 := v.(string)
+			p, ok2 := a.(string)
+			if ok1 && ok2 {
+				return strings.TrimSuffix(s, p), nil
+			}
+			return v, nil
+		}),
+		"split/1": withArg(func(v, a interface{}) (interface{}, error) {
+			s, ok1 := v.(string)
+			sep, ok2 := a.(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("split input and separator must be strings")
+			}
+			return splitString(s, sep), nil
+		}),
+		"join/1":     withArg(join),
+		"test/1":     withArg(test),
+		"contains/1": withArg(func(v, a interface{}) (interface{}, error) { return contains(v, a) }),
+		"map/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			values, err := iterate(in)
+			if err != nil {
+				return nil, err
+			}
+			out := []interface{}{}
+			for _, v := range values {
+				results, err := args[0].eval(e, v)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, results...)
+			}
+			return []interface{}{out}, nil
+		},
+		"map_values/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			switch v := in.(type) {
+			case []interface{}:
+				out := []interface{}{}
+				for _, x := range v {
+					results, err := args[0].eval(e, x)
+					if err != nil {
+						return nil, err
+					}
+					if len(results) > 0 {
+						out = append(out, results[0])
+					}
+				}
+				return []interface{}{out}, nil
+			case map[string]interface{}:
+				out := map[string]interface{}{}
+				for k, x := range v {
+					results, err := args[0].eval(e, x)
+					if err != nil {
+						return nil, err
+					}
+					if len(results) > 0 {
+						out[k] = results[0]
+					}
+				}
+				return []interface{}{out}, nil
+			}
+			return nil, fmt.Errorf("cannot iterate over %s", typeName(in))
+		},
+		"select/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			conds, err := args[0].eval(e, in)
+			if err != nil {
+				return nil, err
+			}
+			var out []interface{}
+			for _, c := range conds {
+				if truthy(c) {
+					out = append(out, in)
+				}
+			}
+			return out, nil
+		},
+		"with_entries/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			entries, err := toEntries(in)
+			if err != nil {
+				return nil, err
+			}
+			mapped := []interface{}{}
+			for _, entry := range entries.([]interface{}) {
+				results, err := args[0].eval(e, entry)
+				if err != nil {
+					return nil, err
+				}
+				mapped = append(mapped, results...)
+			}
+			obj, err := fromEntries(mapped)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{obj}, nil
+		},
+		"first/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			results, err := args[0].eval(e, in)
+			if err != nil || len(results) == 0 {
+				return nil, err
+			}
+			return results[:1], nil
+		},
+		"last/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			results, err := args[0].eval(e, in)
+			if err != nil || len(results) == 0 {
+				return nil, err
+			}
+			return results[len(results)-1:], nil
+		},
+		"limit/2": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			n, err := evalOne(e, in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			f, ok := toFloat(n)
+			if !ok {
+				return nil, fmt.Errorf("limit requires a number")
+			}
+			if f <= 0 {
+				return nil, nil
+			}
+			results, err := args[1].eval(e, in)
+			if err != nil {
+				return nil, err
+			}
+			return results[:max(0, min(int(f), len(results)))], nil
+		},
+		"range/1": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			n, err := evalOne(e, in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return numberRange(0, n)
+		},
+		"range/2": func(e *env, in interface{}, args []node) ([]interface{}, error) {
+			from, err := evalOne(e, in, args[0])
+			if err != nil {
+				return nil, err
+			}
+			to, err := evalOne(e, in, args[1])
+			if err != nil {
+				return nil, err
+			}
+			f, ok := toFloat(from)
+			if !ok {
+				return nil, fmt.Errorf("range requires numbers")
+			}
+			return numberRange(f, to)
+		},
+		"recurse/0": func(_ *env, in interface{}, _ []node) ([]interface{}, error) {
+			return recurseNode{}.eval(nil, in)
+		},
+		"sort_by/1":   keyed(sortBy),
+		"group_by/1":  keyed(groupBy),
+		"unique_by/1": keyed(uniqueBy),
+		"min_by/1": keyed(func(v interface{}, key keyFunc) (interface{}, error) {
+			return extreme(v, key, -1)
+		}),
+		"max_by/1": keyed(func(v interface{}, key keyFunc) (interface{}, error) {
+			return extreme(v, key, 1)
+		}),
+	}
+}
+
+// evalOne returns the first output of n, or null
+func evalOne(e *env, in interface{}, n node) (interface{}, error) {
+	values, err := n.eval(e, in)
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// simple adapts a function of the input
+func simple(f func(v interface{}) (interface{}, error)) builtin {
+	return func(_ *env, in interface{}, _ []node) ([]interface{}, error) {
+		v, err := f(in)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{v}, nil
+	}
+}
+
+// withArg adapts a function of the input and each output of the argument
+func withArg(f func(v, arg interface{}) (interface{}, error)) builtin {
+	return func(e *env, in interface{}, args []node) ([]interface{}, error) {
+		values, err := args[0].eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]interface{}, 0, len(values))
+		for _, a := range values {
+			v, err := f(in, a)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+}
+
+func numeric(f func(float64) float64) builtin {
+	return simple(func(v interface{}) (interface{}, error) {
+		n, ok := toFloat(v)
+		if !ok {
+			return nil, fmt.Errorf("%s (%s) number required", typeName(v), short(v))
+		}
+		return f(n), nil
+	})
+}
+
+func stringFunc(f func(string) string) builtin {
+	return simple(func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s (%s) cannot be case-converted", typeName(v), short(v))
+		}
+		return f(s), nil
+	})
+}
+
+func stringArg(f func(s, arg string) bool) builtin {
+	return withArg(func(v, a interface{}) (interface{}, error) {
+		s, ok1 := v.(string)
+		arg, ok2 := a.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("startswith/endswith requires string inputs")
+		}
+		return f(s, arg), nil
+	})
+}
+
+// keyFunc evaluates a sort or group key for an element
+type keyFunc func(v interface{}) (interface{}, error)
+
+func identityKey(v interface{}) (interface{}, error) { return v, nil }
+
+// keyed adapts a function taking the input array and a key expression
+func keyed(f func(v interface{}, key keyFunc) (interface{}, error)) builtin {
+	return func(e *env, in interface{}, args []node) ([]interface{}, error) {
+		key := func(v interface{}) (interface{}, error) {
+			// Keys with several outputs compare as an array, as in jq
+			values, err := args[0].eval(e, v)
+			if err != nil {
+				return nil, err
+			}
+			if len(values) == 1 {
+				return values[0], nil
+			}
+			return values, nil
+		}
+		v, err := f(in, key)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{v}, nil
+	}
+}
+
+func length(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0.0, nil
+	case bool:
+		return nil, fmt.Errorf("boolean (%v) has no length", v)
+	case string:
+		return float64(len([]rune(v))), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	f, _ := toFloat(v)
+	return math.Abs(f), nil
+}
+
+func keys(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return stringsToValues(sortedKeys(v)), nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = float64(i)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s (%s) has no keys", typeName(v), short(v))
+}
+
+func has(v, key interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			_, found := v[k]
+			return found, nil
+		}
+	case []interface{}:
+		if f, ok := toFloat(key); ok {
+			return f >= 0 && int(f) < len(v), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot check whether %s has a %s key", typeName(v), typeName(key))
+}
+
+func add(v interface{}) (interface{}, error) {
+	values, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	var sum interface{}
+	for _, x := range values {
+		if sum, err = binary("+", sum, x); err != nil {
+			return nil, err
+		}
+	}
+	return sum, nil
+}
+
+func reverse(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case string:
+		r := []rune(v)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, x := range v {
+			out[len(v)-1-i] = x
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot reverse %s", typeName(v))
+}
+
+// keyedArray pairs each element of an array with its key
+func keyedArray(v interface{}, key keyFunc) ([][2]interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) cannot be sorted, as it is not an array", typeName(v), short(v))
+	}
+	pairs := make([][2]interface{}, len(arr))
+	for i, x := range arr {
+		k, err := key(x)
+		if err != nil {
+			return nil, err
+		}
+		pairs[i] = [2]interface{}{k, x}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return compare(pairs[i][0], pairs[j][0]) < 0 })
+	return pairs, nil
+}
+
+func sortBy(v interface{}, key keyFunc) (interface{}, error) {
+	pairs, err := keyedArray(v, key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(pairs))
+	for i, p := range pairs {
+		out[i] = p[1]
+	}
+	return out, nil
+}
+
+func groupBy(v interface{}, key keyFunc) (interface{}, error) {
+	pairs, err := keyedArray(v, key)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for i, p := range pairs {
+		if i == 0 || compare(p[0], pairs[i-1][0]) != 0 {
+			out = append(out, []interface{}{})
+		}
+		last := len(out) - 1
+		out[last] = append(out[last].([]interface{}), p[1])
+	}
+	return out, nil
+}
+
+func uniqueBy(v interface{}, key keyFunc) (interface{}, error) {
+	pairs, err := keyedArray(v, key)
+	if err != nil {
+		return nil, err
+	}
+	out := []interface{}{}
+	for i, p := range pairs {
+		if i == 0 || compare(p[0], pairs[i-1][0]) != 0 {
+			out = append(out, p[1])
+		}
+	}
+	return out, nil
+}
+
+// extreme returns the element with the smallest (dir -1) or largest (dir 1) key
+func extreme(v interface{}, key keyFunc, dir int) (interface{}, error) {
+	pairs, err := keyedArray(v, key)
+	if err != nil || len(pairs) == 0 {
+		return nil, err
+	}
+	if dir < 0 {
+		return pairs[0][1], nil
+	}
+	return pairs[len(pairs)-1][1], nil
+}
+
+func flatten(v interface{}, depth int) (interface{}, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot flatten %s", typeName(v))
+	}
+	out := []interface{}{}
+	for _, x := range arr {
+		if inner, ok := x.([]interface{}); ok && depth != 0 {
+			flat, _ := flatten(inner, depth-1)
+			out = append(out, flat.([]interface{})...)
+			continue
+		}
+		out = append(out, x)
+	}
+	return out, nil
+}
+
+func anyAll(v interface{}, isAny bool) (interface{}, error) {
+	values, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range values {
+		if truthy(x) == isAny {
+			return isAny, nil
+		}
+	}
+	return !isAny, nil
+}
+
+func tonumber(v interface{}) (interface{}, error) {
+	if f, ok := toFloat(v); ok {
+		return f, nil
+	}
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("%s (%s) cannot be parsed as a number", typeName(v), short(v))
+}
+
+func toEntries(v interface{}) (interface{}, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s (%s) has no keys", typeName(v), short(v))
+	}
+	out := make([]interface{}, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		out = append(out, map[string]interface{}{"key": k, "value": m[k]})
+	}
+	return out, nil
+}
+
+func fromEntries(v interface{}) (interface{}, error) {
+	entries, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("from_entries requires objects, not %s", typeName(entry))
+		}
+		var key interface{}
+		for _, name := range []string{"key", "k", "name", "Name", "Key", "K"} {
+			if k, ok := m[name]; ok && k != nil {
+				key = k
+				break
+			}
+		}
+		value, ok := m["value"]
+		if !ok {
+			value = m["v"]
+		}
+		k, err := toText(key)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = value
+	}
+	return out, nil
+}
+
+func join(v, sep interface{}) (interface{}, error) {
+	values, err := iterate(v)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := sep.(string)
+	if !ok {
+		return nil, fmt.Errorf("join separator must be a string")
+	}
+	parts := make([]string, len(values))
+	for i, x := range values {
+		switch x := x.(type) {
+		case nil:
+		case string:
+			parts[i] = x
+		case []interface{}, map[string]interface{}:
+			return nil, fmt.Errorf("cannot join with %s", typeName(x))
+		default:
+			parts[i], _ = toText(x)
+		}
+	}
+	return strings.Join(parts, s), nil
+}
+
+func test(v, pattern interface{}) (interface{}, error) {
+	s, ok1 := v.(string)
+	p, ok2 := pattern.(string)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%s (%s) cannot be matched, as it is not a string", typeName(v), short(v))
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", p, err)
+	}
+	return re.MatchString(s), nil
+}
+
+// contains reports whether b is contained in a: substrings, array elements
+// and object values are matched recursively
+func contains(a, b interface{}) (bool, error) {
+	if typeRank(a) != typeRank(b) && !(typeRank(a) <= 2 && typeRank(b) <= 2) {
+		return false, fmt.Errorf("%s (%s) and %s (%s) cannot have their containment checked", typeName(a), short(a), typeName(b), short(b))
+	}
+	switch av := a.(type) {
+	case string:
+		return strings.Contains(av, b.(string)), nil
+	case []interface{}:
+		for _, bx := range b.([]interface{}) {
+			found := false
+			for _, ax := range av {
+				if ok, _ := contains(ax, bx); ok && typeRank(ax) == typeRank(bx) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false, nil
+			}
+		}
+		return true, nil
+	case map[string]interface{}:
+		for k, bx := range b.(map[string]interface{}) {
+			ax, ok := av[k]
+			if !ok {
+				return false, nil
+			}
+			if ok, err := contains(ax, bx); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	return compare(a, b) == 0, nil
+}
+
+// maxRange caps the length of range. Filters are evaluated eagerly, so
+// limit(1; range(1e9)) would otherwise build the whole range first.
+const maxRange = 1000000
+
+func numberRange(from float64, to interface{}) ([]interface{}, error) {
+	end, ok := toFloat(to)
+	if !ok {
+		return nil, fmt.Errorf("range requires numbers")
+	}
+	if n := math.Ceil(end - from); n > maxRange || math.IsNaN(n) {
+		return nil, fmt.Errorf("range of %v numbers is too long (at most %d)", n, maxRange)
+	}
+	var out []interface{}
+	for i := from; i < end; i++ {
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+// decode parses JSON keeping numbers exact
+func decode(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// formats are the @name string formats
+var formats = map[string]func(v interface{}) (string, error){
+	"text": toText,
+	"json": func(v interface{}) (string, error) { return Encode(v, "") },
+	"csv": func(v interface{}) (string, error) {
+		return formatRow(v, ",", func(s string) string {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		})
+	},
+	"tsv": func(v interface{}) (string, error) {
+		return formatRow(v, "\t", strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace)
+	},
+}
+
+// formatRow joins an array of scalars into a CSV or TSV row
+func formatRow(v interface{}, sep string, quote func(string) string) (string, error) {
+	arr, ok := v.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("%s (%s) cannot be formatted as a row, only an array can be", typeName(v), short(v))
+	}
+	cells := make([]string, len(arr))
+	for i, x := range arr {
+		switch x := x.(type) {
+		case nil:
+		case string:
+			cells[i] = quote(x)
+		case bool:
+			cells[i] = strconv.FormatBool(x)
+		case []interface{}, map[string]interface{}:
+			return "", fmt.Errorf("%s is not valid in a row", typeName(x))
+		default:
+			cells[i], _ = toText(x)
+		}
+	}
+	return strings.Join(cells, sep), nil
+}
+
+type formatNode struct {
+	name string
+}
+
+func (n *formatNode) eval(_ *env, in interface{}) ([]interface{}, error) {
+	s, err := formats[n.name](in)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{s}, nil
+}
@@ -0,0 +1,800 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// node is a compiled expression. eval returns every output for one input.
+type node interface {
+	eval(e *env, in interface{}) ([]interface{}, error)
+}
+
+// env is a linked list of variable bindings
+type env struct {
+	name   string
+	value  interface{}
+	parent *env
+}
+
+func (e *env) lookup(name string) (interface{}, bool) {
+	for ; e != nil; e = e.parent {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	return nil, false
+}
+
+// valueError is raised by error/1 and carries its value to catch
+type valueError struct {
+	value interface{}
+}
+
+func (e *valueError) Error() string {
+	if s, ok := e.value.(string); ok {
+		return s
+	}
+	s, _ := Encode(e.value, "")
+	return s + " (not a string)"
+}
+
+type identity struct{}
+
+func (identity) eval(_ *env, in interface{}) ([]interface{}, error) {
+	return []interface{}{in}, nil
+}
+
+type recurseNode struct{}
+
+func (recurseNode) eval(_ *env, in interface{}) ([]interface{}, error) {
+	var out []interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		out = append(out, v)
+		switch v := v.(type) {
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		case map[string]interface{}:
+			for _, k := range sortedKeys(v) {
+				walk(v[k])
+			}
+		}
+	}
+	walk(in)
+	return out, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(*env, interface{}) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+type varNode struct {
+	name string
+}
+
+func (n *varNode) eval(e *env, _ interface{}) ([]interface{}, error) {
+	v, ok := e.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("$%s is not defined", n.name)
+	}
+	return []interface{}{v}, nil
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n *pipeNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, v := range lefts {
+		rights, err := n.right.eval(e, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rights...)
+	}
+	return out, nil
+}
+
+type bindNode struct {
+	source node
+	name   string
+	body   node
+}
+
+func (n *bindNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	values, err := n.source.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, v := range values {
+		results, err := n.body.eval(&env{name: n.name, value: v, parent: e}, in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+type commaNode struct {
+	left, right node
+}
+
+func (n *commaNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	left, err := n.left.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+type altNode struct {
+	left, right node
+}
+
+func (n *altNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	left, _ := n.left.eval(e, in) // Errors count as no output
+	var out []interface{}
+	for _, v := range left {
+		if truthy(v) {
+			out = append(out, v)
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return n.right.eval(e, in)
+}
+
+type logicNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, l := range lefts {
+		// Short-circuit: false and ... / true or ...
+		if truthy(l) == (n.op == "or") {
+			out = append(out, truthy(l))
+			continue
+		}
+		rights, err := n.right.eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rights {
+			out = append(out, truthy(r))
+		}
+	}
+	return out, nil
+}
+
+type ifNode struct {
+	cond, then, els node
+}
+
+func (n *ifNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	conds, err := n.cond.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, c := range conds {
+		branch := n.els
+		if truthy(c) {
+			branch = n.then
+		}
+		results, err := branch.eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, results...)
+	}
+	return out, nil
+}
+
+type tryNode struct {
+	body, handler node
+}
+
+func (n *tryNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	out, err := n.body.eval(e, in)
+	if err == nil {
+		return out, nil
+	}
+	if n.handler == nil {
+		return nil, nil
+	}
+	var msg interface{} = err.Error()
+	if ve, ok := err.(*valueError); ok {
+		msg = ve.value
+	}
+	return n.handler.eval(e, msg)
+}
+
+type indexNode struct {
+	target, key node
+}
+
+func (n *indexNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, t := range targets {
+		// The key is evaluated against the original input, as in .[.i]
+		keys, err := n.key.eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			v, err := index(t, k)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func index(v, key interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			return v[k], nil
+		}
+	case []interface{}:
+		if f, ok := toFloat(key); ok {
+			i := int(math.Floor(f))
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return nil, nil
+			}
+			return v[i], nil
+		}
+	}
+	k, _ := Encode(key, "")
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(v), k)
+}
+
+type sliceNode struct {
+	target, from, to node
+}
+
+func (n *sliceNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	bound := func(b node, def int) (int, error) {
+		if b == nil {
+			return def, nil
+		}
+		vs, err := b.eval(e, in)
+		if err != nil || len(vs) == 0 {
+			return def, err
+		}
+		f, ok := toFloat(vs[0])
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be numbers")
+		}
+		return int(math.Floor(f)), nil
+	}
+
+	var out []interface{}
+	for _, t := range targets {
+		var length int
+		switch t := t.(type) {
+		case nil:
+			out = append(out, nil)
+			continue
+		case []interface{}:
+			length = len(t)
+		case string:
+			length = len([]rune(t))
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(t))
+		}
+		from, err := bound(n.from, 0)
+		if err != nil {
+			return nil, err
+		}
+		to, err := bound(n.to, length)
+		if err != nil {
+			return nil, err
+		}
+		from, to = clampIndex(from, length), clampIndex(to, length)
+		if to < from {
+			to = from
+		}
+		switch t := t.(type) {
+		case []interface{}:
+			out = append(out, append([]interface{}{}, t[from:to]...))
+		case string:
+			out = append(out, string([]rune(t)[from:to]))
+		}
+	}
+	return out, nil
+}
+
+func clampIndex(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	return max(0, min(i, length))
+}
+
+type iterateNode struct {
+	target node
+}
+
+func (n *iterateNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	targets, err := n.target.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, t := range targets {
+		values, err := iterate(t)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, values...)
+	}
+	return out, nil
+}
+
+// iterate returns the elements of an array or the values of an object in key order
+func iterate(v interface{}) ([]interface{}, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			out = append(out, v[k])
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(v))
+}
+
+type arrayNode struct {
+	body node // nil for []
+}
+
+func (n *arrayNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	if n.body == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+	values, err := n.body.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []interface{}{}
+	}
+	return []interface{}{values}, nil
+}
+
+type objectNode struct {
+	entries [][2]node
+}
+
+// eval builds one object per combination of key and value outputs
+func (n *objectNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	objects := []map[string]interface{}{{}}
+	for _, entry := range n.entries {
+		keys, err := entry[0].eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry[1].eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		var next []map[string]interface{}
+		for _, obj := range objects {
+			for _, k := range keys {
+				key, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, not %s", typeName(k))
+				}
+				for _, v := range values {
+					o := make(map[string]interface{}, len(obj)+1)
+					for ok, ov := range obj {
+						o[ok] = ov
+					}
+					o[key] = v
+					next = append(next, o)
+				}
+			}
+		}
+		objects = next
+	}
+	out := make([]interface{}, len(objects))
+	for i, o := range objects {
+		out[i] = o
+	}
+	return out, nil
+}
+
+type stringNode struct {
+	parts []interface{} // string or node
+}
+
+func (n *stringNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	results := []string{""}
+	for _, part := range n.parts {
+		if s, ok := part.(string); ok {
+			for i := range results {
+				results[i] += s
+			}
+			continue
+		}
+		values, err := part.(node).eval(e, in)
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, r := range results {
+			for _, v := range values {
+				s, err := toText(v)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, r+s)
+			}
+		}
+		results = next
+	}
+	out := make([]interface{}, len(results))
+	for i, r := range results {
+		out[i] = r
+	}
+	return out, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+// eval applies the operator to every pair of outputs, iterating the right
+// side in the outer loop as jq does
+func (n *binaryNode) eval(e *env, in interface{}) ([]interface{}, error) {
+	rights, err := n.right.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	lefts, err := n.left.eval(e, in)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, r := range rights {
+		for _, l := range lefts {
+			v, err := binary(n.op, l, r)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func binary(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return compare(l, r) == 0, nil
+	case "!=":
+		return compare(l, r) != 0, nil
+	case "<":
+		return compare(l, r) < 0, nil
+	case "<=":
+		return compare(l, r) <= 0, nil
+	case ">":
+		return compare(l, r) > 0, nil
+	case ">=":
+		return compare(l, r) >= 0, nil
+	}
+
+	lf, lnum := toFloat(l)
+	rf, rnum := toFloat(r)
+	if lnum && rnum {
+		switch op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, fmt.Errorf("cannot divide %v by zero", lf)
+			}
+			return lf / rf, nil
+		case "%":
+			if int64(rf) == 0 {
+				return nil, fmt.Errorf("cannot divide %v by zero", lf)
+			}
+			return float64(int64(lf) % int64(rf)), nil
+		}
+	}
+
+	switch op {
+	case "+":
+		if l == nil {
+			return r, nil
+		}
+		if r == nil {
+			return l, nil
+		}
+		switch lv := l.(type) {
+		case string:
+			if rv, ok := r.(string); ok {
+				return lv + rv, nil
+			}
+		case []interface{}:
+			if rv, ok := r.([]interface{}); ok {
+				return append(append([]interface{}{}, lv...), rv...), nil
+			}
+		case map[string]interface{}:
+			if rv, ok := r.(map[string]interface{}); ok {
+				out := make(map[string]interface{}, len(lv)+len(rv))
+				for k, v := range lv {
+					out[k] = v
+				}
+				for k, v := range rv {
+					out[k] = v
+				}
+				return out, nil
+			}
+		}
+	case "-":
+		if lv, ok := l.([]interface{}); ok {
+			if rv, ok := r.([]interface{}); ok {
+				out := []interface{}{}
+				for _, v := range lv {
+					if !containsValue(rv, v) {
+						out = append(out, v)
+					}
+				}
+				return out, nil
+			}
+		}
+	case "*":
+		lv, lok := l.(map[string]interface{})
+		rv, rok := r.(map[string]interface{})
+		if lok && rok {
+			return deepMerge(lv, rv), nil
+		}
+	case "/":
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if lok && rok {
+			return splitString(ls, rs), nil
+		}
+	}
+	return nil, fmt.Errorf("%s (%s) and %s (%s) cannot be combined with %s", typeName(l), short(l), typeName(r), short(r), op)
+}
+
+func deepMerge(l, r map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(l)+len(r))
+	for k, v := range l {
+		out[k] = v
+	}
+	for k, v := range r {
+		lm, lok := out[k].(map[string]interface{})
+		rm, rok := v.(map[string]interface{})
+		if lok && rok {
+			out[k] = deepMerge(lm, rm)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func splitString(s, sep string) []interface{} {
+	out := []interface{}{}
+	if s == "" {
+		return out
+	}
+	for _, part := range strings.Split(s, sep) {
+		out = append(out, part)
+	}
+	return out
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, candidate := range values {
+		if compare(candidate, v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// short renders a value for error messages
+func short(v interface{}) string {
+	s, _ := Encode(v, "")
+	if len(s) > 11 {
+		s = s[:10] + "..."
+	}
+	return s
+}
+
+func truthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeRank orders values of different types as jq does
+func typeRank(v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[string]interface{}:
+		return 6
+	}
+	return 3 // Numbers
+}
+
+// compare orders any two values: null < false < true < numbers < strings <
+// arrays < objects
+func compare(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return cmpInt(ra, rb)
+	}
+	switch av := a.(type) {
+	case string:
+		return strings.Compare(av, b.(string))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compare(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return cmpInt(len(av), len(bv))
+	case map[string]interface{}:
+		bv := b.(map[string]interface{})
+		ak, bk := sortedKeys(av), sortedKeys(bv)
+		if c := compare(stringsToValues(ak), stringsToValues(bk)); c != 0 {
+			return c
+		}
+		for _, k := range ak {
+			if c := compare(av[k], bv[k]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	if ra == 3 {
+		af, _ := toFloat(a)
+		bf, _ := toFloat(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+	}
+	return 0
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToValues(s []string) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
+
+// toText renders a value inside a string: strings as-is, anything else as JSON
+func toText(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return Encode(v, "")
+}
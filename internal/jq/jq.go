@@ -0,0 +1,714 @@
+// Package jq implements the commonly used subset of the jq language, so JSON
+// output can be filtered in-process with --jq instead of piping to jq.
+//
+// Supported: paths (.a.b, .[0], .[2:4], .[], ..), pipes, commas, object and
+// array construction, string interpolation, arithmetic, comparisons, and/or,
+// the alternative operator (//), if/elif/else, try/catch, optional (?),
+// "as $var" bindings, the @csv, @tsv, @json and @text formats and the common
+// builtins (map, select, sort_by, group_by, length, keys, has, join, test, ...).
+// Assignment operators, reduce, foreach and function definitions are not.
+//
+// Every filter produces all of its outputs before the next one runs, so
+// limit and first stop nothing early and range is capped at a million numbers.
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed jq program
+type Query struct {
+	root node
+}
+
+// Parse compiles a jq program
+func Parse(src string) (*Query, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("jq: %w", err)
+	}
+	p := &parser{toks: toks, src: src}
+	root, err := p.parsePipe(false)
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jq: %w", err)
+	}
+	return &Query{root: root}, nil
+}
+
+// Run evaluates the program against v, a value decoded by encoding/json
+// (numbers may be float64 or json.Number), and returns every output
+func (q *Query) Run(v interface{}) ([]interface{}, error) {
+	out, err := q.root.eval(nil, v)
+	if err != nil {
+		return nil, fmt.Errorf("jq: error: %w", err)
+	}
+	return out, nil
+}
+
+// Token kinds
+const (
+	tokEOF    = iota
+	tokPunct  // . .. [ ] { } ( ) | , : ; ? and operators
+	tokIdent  // Names and keywords
+	tokField  // .name
+	tokVar    // $name
+	tokFormat // @name
+	tokNumber
+	tokString
+)
+
+type token struct {
+	kind  int
+	text  string
+	num   float64
+	parts []interface{} // String literal parts: string or node (interpolation)
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return "string"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators are matched longest first
+var operators = []string{"..", "==", "!=", "<=", ">=", "//", ".", "[", "]", "{", "}", "(", ")", "|", ",", ":", ";", "?", "<", ">", "+", "-", "*", "/", "%"}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '"':
+			parts, end, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tokString, parts: parts, pos: i})
+			i = end
+			continue
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				j++
+				if j < len(src) && (src[j] == '+' || src[j] == '-') {
+					j++
+				}
+				for j < len(src) && isDigit(src[j]) {
+					j++
+				}
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", src[i:j], i)
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], num: n, pos: i})
+			i = j
+			continue
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+			continue
+		case (c == '.' || c == '$' || c == '@') && i+1 < len(src) && isIdentStart(src[i+1]):
+			j := i + 2
+			for j < len(src) && isIdentChar(src[j]) {
+				j++
+			}
+			kind := map[byte]int{'.': tokField, '$': tokVar, '@': tokFormat}[c]
+			toks = append(toks, token{kind: kind, text: src[i+1 : j], pos: i})
+			i = j
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(src[i:], op) {
+				toks = append(toks, token{kind: tokPunct, text: op, pos: i})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	return append(toks, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexString reads the string literal starting at src[start], returning its
+// literal and interpolated parts and the position after the closing quote
+func lexString(src string, start int) ([]interface{}, int, error) {
+	var parts []interface{}
+	var b strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch c {
+		case '"':
+			if b.Len() > 0 || len(parts) == 0 {
+				parts = append(parts, b.String())
+			}
+			return parts, i + 1, nil
+		case '\\':
+			if i+1 >= len(src) {
+				break
+			}
+			esc := src[i+1]
+			if esc == '(' {
+				end, err := matchParen(src, i+1)
+				if err != nil {
+					return nil, 0, err
+				}
+				q, err := Parse(src[i+2 : end])
+				if err != nil {
+					return nil, 0, fmt.Errorf("in string interpolation: %w", err)
+				}
+				if b.Len() > 0 {
+					parts = append(parts, b.String())
+					b.Reset()
+				}
+				parts = append(parts, q.root)
+				i = end + 1
+				continue
+			}
+			if esc == 'u' && i+6 <= len(src) {
+				r, err := strconv.ParseUint(src[i+2:i+6], 16, 32)
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid escape at position %d", i)
+				}
+				b.WriteRune(rune(r))
+				i += 6
+				continue
+			}
+			unescaped, ok := map[byte]string{'"': `"`, '\\': `\`, '/': "/", 'n': "\n", 't': "\t", 'r': "\r", 'b': "\b", 'f': "\f"}[esc]
+			if !ok {
+				return nil, 0, fmt.Errorf("invalid escape at position %d", i)
+			}
+			b.WriteString(unescaped)
+			i += 2
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return nil, 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+// matchParen returns the position of the parenthesis closing src[open],
+// skipping nested string literals
+func matchParen(src string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		case '"':
+			_, end, err := lexString(src, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		}
+	}
+	return 0, fmt.Errorf("unterminated string interpolation at position %d", open)
+}
+
+func isDigit(c byte) bool      { return c >= '0' && c <= '9' }
+func isIdentStart(c byte) bool { return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isIdentChar(c byte) bool  { return isIdentStart(c) || isDigit(c) }
+
+type parser struct {
+	toks []token
+	pos  int
+	src  string
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next token is the punctuation or keyword s
+func (p *parser) is(s string) bool {
+	t := p.peek()
+	return (t.kind == tokPunct || t.kind == tokIdent) && t.text == s
+}
+
+func (p *parser) accept(s string) bool {
+	if p.is(s) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q, found %s", s, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(format+" at position %d", append(args, p.peek().pos)...)
+}
+
+// parsePipe parses "a | b" and "a as $x | b". Object values are parsed
+// with noComma, since a comma ends the entry there.
+func (p *parser) parsePipe(noComma bool) (node, error) {
+	var left node
+	var err error
+	if noComma {
+		left, err = p.parseAlt()
+	} else {
+		left, err = p.parseComma()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.accept("as") {
+		t := p.next()
+		if t.kind != tokVar {
+			return nil, p.errorf("expected $name after as")
+		}
+		if err := p.expect("|"); err != nil {
+			return nil, err
+		}
+		body, err := p.parsePipe(noComma)
+		if err != nil {
+			return nil, err
+		}
+		return &bindNode{source: left, name: t.text, body: body}, nil
+	}
+	if p.accept("|") {
+		right, err := p.parsePipe(noComma)
+		if err != nil {
+			return nil, err
+		}
+		return &pipeNode{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAlt()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(",") {
+		right, err := p.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		left = &commaNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAlt() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("//") {
+		right, err := p.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		return &altNode{left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			return &binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+// binaryLevels are the arithmetic operators, lowest precedence first
+var binaryLevels = [][]string{{"+", "-"}, {"*", "/", "%"}}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, candidate := range binaryLevels[level] {
+			if p.is(candidate) {
+				op = candidate
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("-") {
+		operand, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: "-", left: &literalNode{value: 0.0}, right: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokField:
+			p.next()
+			term = &indexNode{target: term, key: &literalNode{value: t.text}}
+		case p.is(".") && p.toks[p.pos+1].kind == tokString:
+			p.next()
+			key, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			term = &indexNode{target: term, key: key}
+		case p.is(".") && p.toks[p.pos+1].kind == tokPunct && p.toks[p.pos+1].text == "[":
+			p.next()
+		case p.is("["):
+			if term, err = p.parseBracket(term); err != nil {
+				return nil, err
+			}
+		case p.is("?"):
+			p.next()
+			term = &tryNode{body: term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseBracket parses [], [e], [e:], [:e] and [e:e] after target
+func (p *parser) parseBracket(target node) (node, error) {
+	p.next() // [
+	if p.accept("]") {
+		return &iterateNode{target: target}, nil
+	}
+	var from, to node
+	var err error
+	if !p.is(":") {
+		if from, err = p.parsePipe(false); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept(":") {
+		if !p.is("]") {
+			if to, err = p.parsePipe(false); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &sliceNode{target: target, from: from, to: to}, nil
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return &indexNode{target: target, key: from}, nil
+}
+
+func (p *parser) parseTerm() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literalNode{value: t.num}, nil
+	case tokString:
+		return stringTerm(t), nil
+	case tokField:
+		return &indexNode{target: identity{}, key: &literalNode{value: t.text}}, nil
+	case tokVar:
+		return &varNode{name: t.text}, nil
+	case tokFormat:
+		if _, ok := formats[t.text]; !ok {
+			return nil, fmt.Errorf("unknown format @%s at position %d", t.text, t.pos)
+		}
+		return &formatNode{name: t.text}, nil
+	case tokEOF:
+		return nil, p.errorf("unexpected end of input")
+	case tokPunct:
+		switch t.text {
+		case ".":
+			if p.peek().kind == tokString {
+				key, err := p.parseTerm()
+				if err != nil {
+					return nil, err
+				}
+				return &indexNode{target: identity{}, key: key}, nil
+			}
+			return identity{}, nil
+		case "..":
+			return recurseNode{}, nil
+		case "(":
+			body, err := p.parsePipe(false)
+			if err != nil {
+				return nil, err
+			}
+			return body, p.expect(")")
+		case "[":
+			if p.accept("]") {
+				return &arrayNode{}, nil
+			}
+			body, err := p.parsePipe(false)
+			if err != nil {
+				return nil, err
+			}
+			return &arrayNode{body: body}, p.expect("]")
+		case "{":
+			return p.parseObject()
+		}
+	case tokIdent:
+		return p.parseIdent(t)
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+func stringTerm(t token) node {
+	if len(t.parts) == 1 {
+		if s, ok := t.parts[0].(string); ok {
+			return &literalNode{value: s}
+		}
+	}
+	return &stringNode{parts: t.parts}
+}
+
+func (p *parser) parseIdent(t token) (node, error) {
+	switch t.text {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	case "null":
+		return &literalNode{value: nil}, nil
+	case "if":
+		return p.parseIf()
+	case "try":
+		body, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		n := &tryNode{body: body}
+		if p.accept("catch") {
+			if n.handler, err = p.parsePostfix(); err != nil {
+				return nil, err
+			}
+		}
+		return n, nil
+	case "reduce", "foreach", "def", "label", "import", "include":
+		return nil, fmt.Errorf("%s is not supported at position %d", t.text, t.pos)
+	}
+
+	var args []node
+	if p.accept("(") {
+		for {
+			arg, err := p.parsePipe(false)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.accept(")") {
+				break
+			}
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	key := fmt.Sprintf("%s/%d", t.text, len(args))
+	fn, ok := builtins[key]
+	if !ok {
+		return nil, fmt.Errorf("%s is not defined at position %d", key, t.pos)
+	}
+	return &callNode{name: key, fn: fn, args: args}, nil
+}
+
+func (p *parser) parseIf() (node, error) {
+	cond, err := p.parsePipe(false)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe(false)
+	if err != nil {
+		return nil, err
+	}
+	n := &ifNode{cond: cond, then: then, els: identity{}}
+	switch {
+	case p.accept("elif"):
+		if n.els, err = p.parseIf(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case p.accept("else"):
+		if n.els, err = p.parsePipe(false); err != nil {
+			return nil, err
+		}
+	}
+	return n, p.expect("end")
+}
+
+func (p *parser) parseObject() (node, error) {
+	obj := &objectNode{}
+	if p.accept("}") {
+		return obj, nil
+	}
+	for {
+		var key, value node
+		t := p.next()
+		switch {
+		case t.kind == tokIdent:
+			key = &literalNode{value: t.text}
+		case t.kind == tokVar:
+			key, value = &literalNode{value: t.text}, &varNode{name: t.text}
+		case t.kind == tokString:
+			key = stringTerm(t)
+		case t.kind == tokPunct && t.text == "(":
+			k, err := p.parsePipe(false)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			key = k
+		default:
+			return nil, fmt.Errorf("unexpected %s in object at position %d", t, t.pos)
+		}
+
+		if p.accept(":") {
+			v, err := p.parsePipe(true)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		} else if value == nil {
+			// {name} is shorthand for {name: .name}
+			value = &indexNode{target: identity{}, key: key}
+		}
+		obj.entries = append(obj.entries, [2]node{key, value})
+
+		if p.accept("}") {
+			return obj, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Encode renders a value as JSON the way jq does, without HTML escaping
+func Encode(v interface{}, indent string) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,192 @@
+package jq
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `{
+	"result": [
+		{"counter": 1, "title": "NoMethodError", "level": "error", "total_occurrences": 12, "tags": ["a", "b"]},
+		{"counter": 2, "title": "Timeout", "level": "warning", "total_occurrences": 3, "tags": []},
+		{"counter": 3, "title": "KeyError", "level": "error", "total_occurrences": 40, "tags": ["b"]}
+	],
+	"meta": {"page": 1, "next": null}
+}`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // Outputs encoded compactly, one per line
+	}{
+		{`.`, `{"meta":{"next":null,"page":1},"result":[{"counter":1,"level":"error","tags":["a","b"],"title":"NoMethodError","total_occurrences":12},{"counter":2,"level":"warning","tags":[],"title":"Timeout","total_occurrences":3},{"counter":3,"level":"error","tags":["b"],"title":"KeyError","total_occurrences":40}]}`},
+		{`.meta.page`, `1`},
+		{`.meta["page"]`, `1`},
+		{`.missing.deeper`, `null`},
+		{`.result[0].title`, `"NoMethodError"`},
+		{`.result[-1].counter`, `3`},
+		{`.result[1:].[].counter`, "2\n3"},
+		{`.result[].counter`, "1\n2\n3"},
+		{`.result | length`, `3`},
+		{`[.result[] | select(.level == "error") | .counter]`, `[1,3]`},
+		{`.result | map(.total_occurrences) | add`, `55`},
+		{`.result | sort_by(-.total_occurrences) | .[0].title`, `"KeyError"`},
+		{`.result | group_by(.level) | map({level: .[0].level, n: length})`, `[{"level":"error","n":2},{"level":"warning","n":1}]`},
+		{`.result | max_by(.total_occurrences).counter`, `3`},
+		{`.result[] | "#\(.counter) \(.title)"`, "\"#1 NoMethodError\"\n\"#2 Timeout\"\n\"#3 KeyError\""},
+		{`.result[] | [.counter, .title] | @csv`, "\"1,\\\"NoMethodError\\\"\"\n\"2,\\\"Timeout\\\"\"\n\"3,\\\"KeyError\\\"\""},
+		{`.result[0] | {counter, title}`, `{"counter":1,"title":"NoMethodError"}`},
+		{`.result[0] | keys`, `["counter","level","tags","title","total_occurrences"]`},
+		{`.result[0] | to_entries | map(select(.key | startswith("t"))) | from_entries`, `{"tags":["a","b"],"title":"NoMethodError","total_occurrences":12}`},
+		{`.meta.next // "none"`, `"none"`},
+		{`.result[] | if .total_occurrences > 10 then "hot" elif .level == "warning" then "warn" else "cold" end`, "\"hot\"\n\"warn\"\n\"hot\""},
+		{`[.result[].tags[]] | unique`, `["a","b"]`},
+		{`.result | map(.title | ascii_downcase | test("error"))`, `[true,false,true]`},
+		{`[.result[].level] | any`, `true`},
+		{`.result[0].title | split("Method") | join("-")`, `"No-Error"`},
+		{`.result[0].title as $t | .meta | {t: $t, page}`, `{"page":1,"t":"NoMethodError"}`},
+		{`try error("boom") catch .`, `"boom"`},
+		{`[.result[].counter] | first, last`, "1\n3"},
+		{`[limit(2; .result[].counter)]`, `[1,2]`},
+		{`[range(3)] | reverse`, `[2,1,0]`},
+		{`1 + 2 * 3 - 4 / 2`, `5`},
+		{`{a: 1} + {b: 2} | tojson`, `"{\"a\":1,\"b\":2}"`},
+		{`[1, [2, [3]]] | flatten`, `[1,2,3]`},
+		{`.result[0].tags | contains(["a"])`, `true`},
+		{`not`, `false`},
+		{`.meta | has("page"), has("x")`, "true\nfalse"},
+		{`[.[] | type]`, `["object","array"]`},
+		{`"12" | tonumber + 1`, `13`},
+	}
+
+	input, err := decode(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			out, err := q.Run(input)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			lines := make([]string, len(out))
+			for i, v := range out {
+				if lines[i], err = Encode(v, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := strings.Join(lines, "\n"); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		input   string
+		query   string
+		want    string // Outputs encoded compactly, one per line
+		wantErr string
+	}{
+		{`null`, `[limit(3; range(10))]`, `[0,1,2]`, ""},
+		{`null`, `[limit(5; 1, 2)]`, `[1,2]`, ""},
+		{`null`, `[limit(0; error("evaluated"))]`, `[]`, ""},
+		{`null`, `[limit(-1; 1, 2)]`, `[]`, ""},
+		{`null`, `limit("a"; 1)`, "", "limit requires a number"},
+		{`null`, `[range(0)]`, `[]`, ""},
+		{`null`, `[range(2; 5)]`, `[2,3,4]`, ""},
+		{`null`, `[range(5; 2)]`, `[]`, ""},
+		{`null`, `[range(1.5)]`, `[0,1]`, ""},
+		{`null`, `range("a")`, "", "range requires numbers"},
+		{`null`, `[limit(1; range(1e9))]`, "", "too long"},
+		{`null`, `[range(-1e18; 0)]`, "", "too long"},
+		{`null`, `[range(0; 1e18)] | length`, "", "too long"},
+		{`{"a":[1,{"b":2}]}`, `[recurse]`, `[{"a":[1,{"b":2}]},[1,{"b":2}],1,{"b":2},2]`, ""},
+		{`{"a":[1,{"b":2}]}`, `[..] | length`, `5`, ""},
+		{`{"a":[1,{"b":2}]}`, `[.. | numbers?]`, "", "numbers/0 is not defined"},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b[1]`, `20`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b[-1]`, `30`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b[5]`, `null`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b[1:]`, `[20,30]`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b[:-1]`, `[10,20]`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a["b"][]`, "10\n20\n30", ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b.c`, "", "cannot index array"},
+		{`{"a":{"b":[10,20,30]}}`, `[.a.b.c?]`, `[]`, ""},
+		{`{"a":{"b":[10,20,30]}}`, `.a.b | .[1:2][0]`, `20`, ""},
+		{`{"a":1}`, `error("boom")`, "", "boom"},
+		{`{"a":1}`, `error({"code": 1})`, "", "code"},
+		{`{"a":1}`, `try error("x") catch "caught"`, `"caught"`, ""},
+		{`{"a":1}`, `[.a | try tostring catch "no"]`, `["1"]`, ""},
+		{`{"a":1}`, `first(range(3)), last(range(3))`, "0\n2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			input, err := decode(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, err := Parse(tt.query)
+			var out []interface{}
+			if err == nil {
+				out, err = q.Run(input)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := make([]string, len(out))
+			for i, v := range out {
+				if lines[i], err = Encode(v, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := strings.Join(lines, "\n"); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{`.a |`, "unexpected end of input"},
+		{`.[`, "unexpected end of input"},
+		{`nosuchfn`, "nosuchfn/0 is not defined"},
+		{`reduce .[] as $x (0; . + $x)`, "reduce is not supported"},
+		{`@xml`, "unknown format @xml"},
+		{`"\(.a"`, "unterminated"},
+		{`.result[0] + 1`, "cannot be combined with +"},
+		{`.meta.page | .x`, "cannot index number"},
+		{`.result[0].title | error(.)`, "NoMethodError"},
+	}
+
+	input, err := decode(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err == nil {
+				_, err = q.Run(input)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
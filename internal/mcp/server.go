@@ -0,0 +1,191 @@
+// Package mcp implements a Model Context Protocol server over stdio, exposing
+// Rollbar queries as tools for AI agents.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/robzolkos/rollbar-cli/internal/version"
+)
+
+// ProtocolVersion is the MCP revision this server implements
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// maxMessageSize bounds a single newline-delimited JSON-RPC message
+const maxMessageSize = 10 * 1024 * 1024
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// textContent is an MCP text content block
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// toolResult is the result of tools/call
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// Server answers MCP requests using the registered tools
+type Server struct {
+	tools []Tool
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewServer creates a server exposing the Rollbar tools. Tools that modify
+// items are only registered when allowWrites is set.
+func NewServer(backend Backend, allowWrites bool) *Server {
+	var tools []Tool
+	for _, t := range rollbarTools(backend) {
+		if t.Write && !allowWrites {
+			continue
+		}
+		tools = append(tools, t)
+	}
+	return &Server{tools: tools}
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes responses
+// to w until r is exhausted
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}})
+			continue
+		}
+
+		result, rerr := s.handle(&req)
+
+		// Notifications have no ID and get no response
+		if len(req.ID) == 0 {
+			continue
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID}
+		if rerr != nil {
+			resp.Error = rerr
+		} else {
+			resp.Result = result
+		}
+		s.write(resp)
+	}
+
+	return scanner.Err()
+}
+
+func (s *Server) write(resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{codeInvalidRequest, err.Error()}})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, _ = s.out.Write(append(data, '\n'))
+}
+
+func (s *Server) handle(req *request) (interface{}, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{codeInvalidRequest, "jsonrpc must be \"2.0\""}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities": map[string]interface{}{
+				"tools": map[string]interface{}{},
+			},
+			"serverInfo": map[string]interface{}{
+				"name":    "rollbar",
+				"version": version.Info(),
+			},
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		tools := make([]map[string]interface{}, 0, len(s.tools))
+		for _, t := range s.tools {
+			tools = append(tools, map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"inputSchema": Schema(t.Args),
+			})
+		}
+		return map[string]interface{}{"tools": tools}, nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	default:
+		return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+	}
+}
+
+func (s *Server) callTool(params json.RawMessage) (interface{}, *rpcError) {
+	var call struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+	}
+
+	for _, t := range s.tools {
+		if t.Name != call.Name {
+			continue
+		}
+		text, err := t.Call(call.Arguments)
+		if err != nil {
+			// Tool failures are reported in the result so the model can see them
+			return toolResult{
+				Content: []textContent{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		return toolResult{Content: []textContent{{Type: "text", Text: text}}}, nil
+	}
+
+	return nil, &rpcError{codeInvalidParams, "unknown tool: " + call.Name}
+}
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+type fakeBackend struct {
+	resolved []int64
+	lastOpts api.ItemsOptions
+}
+
+func (b *fakeBackend) ListItems(opts api.ItemsOptions) ([]api.Item, int, error) {
+	b.lastOpts = opts
+	return []api.Item{{ID: 1, Counter: 7, Title: "NoMethodError", LevelString: "error", TotalOccurrences: 3}}, 1, nil
+}
+
+func (b *fakeBackend) GetItemByCounter(counter int) (*api.Item, error) {
+	return &api.Item{ID: api.JSONInt64(counter * 10), Counter: counter, Title: "NoMethodError", LevelString: "error"}, nil
+}
+
+func (b *fakeBackend) ListInstances(opts api.InstancesOptions) ([]api.Instance, error) {
+	return []api.Instance{{ID: 555, ItemID: opts.ItemID, Data: api.InstanceData{
+		Level: "error",
+		Body:  api.Body{Trace: &api.Trace{Exception: api.Exception{Class: "NoMethodError", Message: "undefined method"}}},
+	}}}, nil
+}
+
+func (b *fakeBackend) GetInstance(id int64) (*api.Instance, error) {
+	return &api.Instance{ID: id, Data: api.InstanceData{Level: "error"}}, nil
+}
+
+func (b *fakeBackend) UpdateItemStatus(id int64, status string) (*api.Item, error) {
+	b.resolved = append(b.resolved, id)
+	return &api.Item{ID: api.JSONInt64(id), Counter: int(id / 10), Title: "NoMethodError", Status: status}, nil
+}
+
+// session sends requests to a server and returns the decoded responses
+func session(t *testing.T, s *Server, requests ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var responses []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func toolText(t *testing.T, resp map[string]interface{}) (string, bool) {
+	t.Helper()
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected result, got %v", resp)
+	}
+	content := result["content"].([]interface{})[0].(map[string]interface{})
+	isError, _ := result["isError"].(bool)
+	return content["text"].(string), isError
+}
+
+func TestServerSession(t *testing.T) {
+	b := &fakeBackend{}
+	s := NewServer(b, false)
+
+	responses := session(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list_items","arguments":{"level":"error","environment":"production"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_context","arguments":{"counter":7}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"resolve_item","arguments":{"counter":7}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"bogus"}`,
+		`not json`,
+	)
+
+	// The notification gets no response
+	if len(responses) != 7 {
+		t.Fatalf("expected 7 responses, got %d: %v", len(responses), responses)
+	}
+
+	init := responses[0]["result"].(map[string]interface{})
+	if init["protocolVersion"] != ProtocolVersion {
+		t.Errorf("unexpected protocol version: %v", init["protocolVersion"])
+	}
+
+	tools := responses[1]["result"].(map[string]interface{})["tools"].([]interface{})
+	names := []string{}
+	for _, tool := range tools {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "list_items,get_item,get_context,list_occurrences,get_occurrence" {
+		t.Errorf("unexpected tools without writes: %v", names)
+	}
+
+	text, isError := toolText(t, responses[2])
+	if isError || !strings.Contains(text, "#7 NoMethodError") {
+		t.Errorf("unexpected list_items result: %q", text)
+	}
+	if b.lastOpts.Status != "active" || b.lastOpts.Level != "error" || b.lastOpts.Environment != "production" {
+		t.Errorf("unexpected list options: %+v", b.lastOpts)
+	}
+
+	text, _ = toolText(t, responses[3])
+	if !strings.Contains(text, "# Bug Report") {
+		t.Errorf("expected markdown context, got %q", text)
+	}
+
+	// resolve_item is not registered without writes
+	if responses[4]["error"] == nil {
+		t.Errorf("expected error for gated write tool, got %v", responses[4])
+	}
+	if len(b.resolved) != 0 {
+		t.Error("expected no item to be resolved")
+	}
+
+	if code := responses[5]["error"].(map[string]interface{})["code"].(float64); code != codeMethodNotFound {
+		t.Errorf("expected method not found, got %v", code)
+	}
+	if code := responses[6]["error"].(map[string]interface{})["code"].(float64); code != codeParseError {
+		t.Errorf("expected parse error, got %v", code)
+	}
+}
+
+func TestServerWriteTools(t *testing.T) {
+	b := &fakeBackend{}
+	s := NewServer(b, true)
+
+	responses := session(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"resolve_item","arguments":{"counter":7}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"resolve_item","arguments":{}}}`,
+	)
+
+	text, isError := toolText(t, responses[0])
+	if isError || text != "Resolved item #7: NoMethodError" {
+		t.Errorf("unexpected resolve result: %q", text)
+	}
+	if len(b.resolved) != 1 || b.resolved[0] != 70 {
+		t.Errorf("expected item 70 to be resolved, got %v", b.resolved)
+	}
+
+	text, isError = toolText(t, responses[1])
+	if !isError || !strings.Contains(text, "counter is required") {
+		t.Errorf("expected tool error for missing counter, got %q", text)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema(listOccurrencesArgs{})
+	props := schema["properties"].(map[string]interface{})
+
+	for _, name := range []string{"counter", "item_id", "page", "limit", "format"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("expected property %q in schema, got %v", name, props)
+		}
+	}
+	if props["item_id"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("expected integer item_id, got %v", props["item_id"])
+	}
+
+	items := Schema(api.ItemsOptions{})["properties"].(map[string]interface{})
+	status := items["status"].(map[string]interface{})
+	if len(status["enum"].([]string)) != 4 {
+		t.Errorf("expected status enum, got %v", status)
+	}
+	if items["date_from"].(map[string]interface{})["format"] != "date-time" {
+		t.Errorf("expected date-time format for date_from, got %v", items["date_from"])
+	}
+
+	// Embedded pointers are flattened and later fields override theirs
+	record := Schema(output.ItemRecord{})["properties"].(map[string]interface{})
+	if record["counter"] == nil || record["level"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("expected counter and a level name, got %v", record)
+	}
+
+	required := Schema(itemArgs{})["required"].([]string)
+	if len(required) != 1 || required[0] != "counter" {
+		t.Errorf("expected counter to be required, got %v", required)
+	}
+}
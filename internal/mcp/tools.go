@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// defaultContextOccurrences matches the 'rollbar context' default
+const defaultContextOccurrences = 3
+
+// Backend is the subset of the Rollbar API used by the tools
+type Backend interface {
+	ListItems(opts api.ItemsOptions) ([]api.Item, int, error)
+	GetItemByCounter(counter int) (*api.Item, error)
+	ListInstances(opts api.InstancesOptions) ([]api.Instance, error)
+	GetInstance(id int64) (*api.Instance, error)
+	UpdateItemStatus(id int64, status string) (*api.Item, error)
+}
+
+// Tool is an MCP tool. Args is a zero value of the argument struct, used both
+// to generate the input schema and to decode arguments.
+type Tool struct {
+	Name        string
+	Description string
+	Args        interface{}
+	Write       bool // Modifies Rollbar state; only exposed when writes are allowed
+	Call        func(args json.RawMessage) (string, error)
+}
+
+// formatArgs selects the output format of a tool result
+type formatArgs struct {
+	Format string `json:"format,omitempty" description:"Output format (default: compact)" enum:"compact,json,markdown"`
+}
+
+func (a formatArgs) formatter() output.Formatter {
+	switch output.Format(a.Format) {
+	case output.FormatJSON:
+		return &output.JSONFormatter{}
+	case output.FormatMarkdown:
+		return &output.MarkdownFormatter{}
+	default:
+		return &output.CompactFormatter{}
+	}
+}
+
+type listItemsArgs struct {
+	api.ItemsOptions
+	formatArgs
+}
+
+type itemArgs struct {
+	Counter int `json:"counter" description:"Project item counter (the #number shown in Rollbar)" required:"true"`
+	formatArgs
+}
+
+type contextArgs struct {
+	Counter     int    `json:"counter" description:"Project item counter (the #number shown in Rollbar)" required:"true"`
+	Occurrences int    `json:"occurrences,omitempty" description:"Number of recent occurrences to include (default 3)"`
+	Format      string `json:"format,omitempty" description:"Output format (default: markdown)" enum:"markdown,compact,json"`
+}
+
+type listOccurrencesArgs struct {
+	Counter int `json:"counter,omitempty" description:"Project item counter; alternative to item_id. Omit both to list occurrences across the project"`
+	api.InstancesOptions
+	Limit int `json:"limit,omitempty" description:"Maximum number of occurrences to return"`
+	formatArgs
+}
+
+type occurrenceArgs struct {
+	ID int64 `json:"id" description:"Occurrence (instance) ID" required:"true"`
+	formatArgs
+}
+
+type resolveArgs struct {
+	Counter int `json:"counter" description:"Project item counter to mark as resolved" required:"true"`
+}
+
+// decode unmarshals tool arguments, treating missing arguments as empty
+func decode(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func render(fn func(w *bytes.Buffer) error) (string, error) {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func rollbarTools(b Backend) []Tool {
+	return []Tool{
+		{
+			Name:        "list_items",
+			Description: "List Rollbar items (error groups) matching filters. Defaults to active items.",
+			Args:        listItemsArgs{},
+			Call: func(raw json.RawMessage) (string, error) {
+				var args listItemsArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.Status == "" {
+					args.Status = "active"
+				}
+				items, _, err := b.ListItems(args.ItemsOptions)
+				if err != nil {
+					return "", err
+				}
+				if args.Limit > 0 && len(items) > args.Limit {
+					items = items[:args.Limit]
+				}
+				if len(items) == 0 && args.Format != string(output.FormatJSON) {
+					return "No items found.", nil
+				}
+				return render(func(w *bytes.Buffer) error { return args.formatter().FormatItems(w, items) })
+			},
+		},
+		{
+			Name:        "get_item",
+			Description: "Get details for a Rollbar item by its project counter.",
+			Args:        itemArgs{},
+			Call: func(raw json.RawMessage) (string, error) {
+				var args itemArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.Counter <= 0 {
+					return "", fmt.Errorf("counter is required")
+				}
+				item, err := b.GetItemByCounter(args.Counter)
+				if err != nil {
+					return "", err
+				}
+				return render(func(w *bytes.Buffer) error { return args.formatter().FormatItem(w, item) })
+			},
+		},
+		{
+			Name:        "get_context",
+			Description: "Generate bug-fixing context for an item: exception, stack trace (app frames first), request, person and recent occurrences.",
+			Args:        contextArgs{},
+			Call: func(raw json.RawMessage) (string, error) {
+				var args contextArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.Counter <= 0 {
+					return "", fmt.Errorf("counter is required")
+				}
+				item, err := b.GetItemByCounter(args.Counter)
+				if err != nil {
+					return "", err
+				}
+				instances, err := b.ListInstances(api.InstancesOptions{ItemID: item.ID.Int64()})
+				if err != nil {
+					return "", err
+				}
+				limit := args.Occurrences
+				if limit <= 0 {
+					limit = defaultContextOccurrences
+				}
+				if len(instances) > limit {
+					instances = instances[:limit]
+				}
+
+				var formatter output.Formatter
+				switch output.Format(args.Format) {
+				case output.FormatJSON:
+					formatter = &output.JSONFormatter{}
+				case output.FormatCompact:
+					formatter = &output.CompactFormatter{}
+				default:
+					formatter = &output.MarkdownFormatter{}
+				}
+				return render(func(w *bytes.Buffer) error { return formatter.FormatContext(w, item, instances) })
+			},
+		},
+		{
+			Name:        "list_occurrences",
+			Description: "List occurrences (individual error instances) for an item, or across the project when no item is given.",
+			Args:        listOccurrencesArgs{},
+			Call: func(raw json.RawMessage) (string, error) {
+				var args listOccurrencesArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.Counter > 0 {
+					item, err := b.GetItemByCounter(args.Counter)
+					if err != nil {
+						return "", err
+					}
+					args.ItemID = item.ID.Int64()
+				}
+				instances, err := b.ListInstances(args.InstancesOptions)
+				if err != nil {
+					return "", err
+				}
+				if args.Limit > 0 && len(instances) > args.Limit {
+					instances = instances[:args.Limit]
+				}
+				if len(instances) == 0 && args.Format != string(output.FormatJSON) {
+					return "No occurrences found.", nil
+				}
+				return render(func(w *bytes.Buffer) error { return args.formatter().FormatInstances(w, instances) })
+			},
+		},
+		{
+			Name:        "get_occurrence",
+			Description: "Get full details for a single occurrence by ID.",
+			Args:        occurrenceArgs{},
+			Call: func(raw json.RawMessage) (string, error) {
+				var args occurrenceArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.ID <= 0 {
+					return "", fmt.Errorf("id is required")
+				}
+				instance, err := b.GetInstance(args.ID)
+				if err != nil {
+					return "", err
+				}
+				return render(func(w *bytes.Buffer) error { return args.formatter().FormatInstance(w, instance) })
+			},
+		},
+		{
+			Name:        "resolve_item",
+			Description: "Mark a Rollbar item as resolved. Requires a token with write scope.",
+			Args:        resolveArgs{},
+			Write:       true,
+			Call: func(raw json.RawMessage) (string, error) {
+				var args resolveArgs
+				if err := decode(raw, &args); err != nil {
+					return "", err
+				}
+				if args.Counter <= 0 {
+					return "", fmt.Errorf("counter is required")
+				}
+				item, err := b.GetItemByCounter(args.Counter)
+				if err != nil {
+					return "", err
+				}
+				item, err = b.UpdateItemStatus(item.ID.Int64(), "resolved")
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Resolved item #%d: %s", item.Counter, item.Title), nil
+			},
+		},
+	}
+}
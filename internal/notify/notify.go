@@ -0,0 +1,252 @@
+// Package notify detects new and spiking items between runs and posts webhook notifications.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// DefaultTimeout bounds each webhook request
+const DefaultTimeout = 10 * time.Second
+
+// Event kinds
+const (
+	EventNew   = "new"
+	EventSpike = "spike"
+)
+
+// Payload formats
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// ItemState is the per-item state remembered between runs
+type ItemState struct {
+	Counter     int    `json:"counter"`
+	Title       string `json:"title"`
+	Occurrences int    `json:"occurrences"`
+	// SeenAt is when the item was last listed; older snapshots leave it unset
+	SeenAt time.Time `json:"seen_at,omitempty"`
+}
+
+// Snapshot records the items seen on the previous run
+type Snapshot struct {
+	TakenAt time.Time           `json:"taken_at"`
+	Items   map[int64]ItemState `json:"items"`
+}
+
+// Event describes a new or spiking item
+type Event struct {
+	Type          string  `json:"type"`
+	Counter       int     `json:"counter"`
+	Title         string  `json:"title"`
+	Level         string  `json:"level"`
+	Environment   string  `json:"environment"`
+	Occurrences   int     `json:"occurrences"`
+	Delta         int     `json:"delta"`
+	RatePerMinute float64 `json:"rate_per_minute"`
+}
+
+// NewSnapshot records the current state of items
+func NewSnapshot(items []api.Item, now time.Time) *Snapshot {
+	snap := &Snapshot{
+		TakenAt: now.UTC(),
+		Items:   make(map[int64]ItemState, len(items)),
+	}
+	for _, item := range items {
+		snap.Items[item.ID.Int64()] = ItemState{
+			Counter:     item.Counter,
+			Title:       item.Title,
+			Occurrences: item.TotalOccurrences,
+			SeenAt:      now.UTC(),
+		}
+	}
+	return snap
+}
+
+// Keep copies the items of older that are missing from s, so an item that
+// drops out of the listed pages is still known when it comes back
+func (s *Snapshot) Keep(older *Snapshot) {
+	if older == nil {
+		return
+	}
+	for id, state := range older.Items {
+		if _, ok := s.Items[id]; ok {
+			continue
+		}
+		if state.SeenAt.IsZero() {
+			state.SeenAt = older.TakenAt
+		}
+		s.Items[id] = state
+	}
+}
+
+// LoadSnapshot reads a snapshot file. A missing file returns nil without error.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// Save writes the snapshot to path
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Detect compares items against the previous snapshot. Items missing from the
+// snapshot are reported as new unless they first occurred before it was taken;
+// items whose occurrence rate since they were last seen reaches spikeRate
+// (occurrences per minute) are reported as spiking. A nil snapshot only
+// establishes a baseline and yields no events. A spikeRate of 0 disables spike
+// detection.
+func Detect(prev *Snapshot, items []api.Item, now time.Time, spikeRate float64) []Event {
+	if prev == nil {
+		return nil
+	}
+
+	var events []Event
+
+	for _, item := range items {
+		state, seen := prev.Items[item.ID.Int64()]
+		if !seen {
+			if item.FirstOccurrenceTime.IsZero() || !item.FirstOccurrenceTime.Before(prev.TakenAt) {
+				events = append(events, newEvent(EventNew, item, item.TotalOccurrences, 0))
+			}
+			continue
+		}
+
+		since := state.SeenAt
+		if since.IsZero() {
+			since = prev.TakenAt
+		}
+		minutes := now.Sub(since).Minutes()
+		delta := item.TotalOccurrences - state.Occurrences
+		if spikeRate <= 0 || delta <= 0 || minutes <= 0 {
+			continue
+		}
+		rate := float64(delta) / minutes
+		if rate >= spikeRate {
+			events = append(events, newEvent(EventSpike, item, delta, rate))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return events[i].Type == EventNew
+		}
+		return events[i].Delta > events[j].Delta
+	})
+
+	return events
+}
+
+func newEvent(kind string, item api.Item, delta int, rate float64) Event {
+	return Event{
+		Type:          kind,
+		Counter:       item.Counter,
+		Title:         item.Title,
+		Level:         item.LevelString,
+		Environment:   item.Environment,
+		Occurrences:   item.TotalOccurrences,
+		Delta:         delta,
+		RatePerMinute: rate,
+	}
+}
+
+// BuildPayload renders events as a webhook body in the given format
+func BuildPayload(format string, events []Event, now time.Time) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": slackText(events)})
+	case FormatJSON, "":
+		return json.Marshal(struct {
+			Source string    `json:"source"`
+			SentAt time.Time `json:"sent_at"`
+			Events []Event   `json:"events"`
+		}{
+			Source: "rollbar-cli",
+			SentAt: now.UTC(),
+			Events: events,
+		})
+	default:
+		return nil, fmt.Errorf("unknown payload format: %s (use json or slack)", format)
+	}
+}
+
+func slackText(events []Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Rollbar: %d notification(s)*", len(events))
+	for _, e := range events {
+		switch e.Type {
+		case EventNew:
+			fmt.Fprintf(&b, "\n:new: *#%d* %s [%s] %d occ", e.Counter, e.Title, e.Level, e.Occurrences)
+		case EventSpike:
+			fmt.Fprintf(&b, "\n:chart_with_upwards_trend: *#%d* %s [%s] +%d occ (%.1f/min)",
+				e.Counter, e.Title, e.Level, e.Delta, e.RatePerMinute)
+		}
+		if e.Environment != "" {
+			fmt.Fprintf(&b, " in %s", e.Environment)
+		}
+	}
+	return b.String()
+}
+
+// Webhook posts payloads to a URL
+type Webhook struct {
+	URL        string
+	Headers    map[string]string
+	HTTPClient *http.Client
+}
+
+// Send posts the payload as JSON and fails on non-2xx responses
+func (h *Webhook) Send(payload []byte) error {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := h.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
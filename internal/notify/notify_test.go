@@ -0,0 +1,182 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func item(id int64, counter, occ int) api.Item {
+	return api.Item{
+		ID:               api.JSONInt64(id),
+		Counter:          counter,
+		Title:            "Error",
+		LevelString:      "error",
+		Environment:      "production",
+		TotalOccurrences: occ,
+	}
+}
+
+func TestDetect(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	prev := NewSnapshot([]api.Item{item(1, 10, 100), item(2, 20, 5)}, start)
+
+	// Ten minutes later: #10 gained 200 occurrences, #20 gained 5, #30 is new
+	now := start.Add(10 * time.Minute)
+	items := []api.Item{item(1, 10, 300), item(2, 20, 10), item(3, 30, 2)}
+
+	events := Detect(prev, items, now, 10)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(events), events)
+	}
+	if events[0].Type != EventNew || events[0].Counter != 30 {
+		t.Errorf("expected new event for #30 first, got %+v", events[0])
+	}
+	if events[1].Type != EventSpike || events[1].Counter != 10 || events[1].Delta != 200 {
+		t.Errorf("expected spike event for #10, got %+v", events[1])
+	}
+	if events[1].RatePerMinute != 20 {
+		t.Errorf("expected rate 20/min, got %v", events[1].RatePerMinute)
+	}
+
+	t.Run("baseline", func(t *testing.T) {
+		if events := Detect(nil, items, now, 10); len(events) != 0 {
+			t.Errorf("expected no events without a snapshot, got %+v", events)
+		}
+	})
+
+	t.Run("spike detection disabled", func(t *testing.T) {
+		events := Detect(prev, items, now, 0)
+		if len(events) != 1 || events[0].Type != EventNew {
+			t.Errorf("expected only the new event, got %+v", events)
+		}
+	})
+}
+
+func TestDetectReturningItems(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	older := NewSnapshot([]api.Item{item(1, 10, 100), item(2, 20, 5)}, start)
+
+	// #20 is not listed on the next run but is kept
+	prev := NewSnapshot([]api.Item{item(1, 10, 100)}, start.Add(50*time.Minute))
+	prev.Keep(older)
+	if state, ok := prev.Items[2]; !ok || !state.SeenAt.Equal(start) {
+		t.Fatalf("expected #20 kept as seen at %v, got %+v", start, prev.Items)
+	}
+
+	// It returns with 500 more occurrences over the hour since it was seen
+	now := start.Add(time.Hour)
+	returning := item(2, 20, 505)
+	untracked := item(3, 30, 7)
+	untracked.FirstOccurrenceTime = start.Add(-24 * time.Hour)
+
+	events := Detect(prev, []api.Item{item(1, 10, 100), returning, untracked}, now, 10)
+	if len(events) != 0 {
+		t.Errorf("expected no events, got %+v", events)
+	}
+	events = Detect(prev, []api.Item{returning}, now, 5)
+	if len(events) != 1 || events[0].Type != EventSpike || events[0].RatePerMinute != 500.0/60 {
+		t.Errorf("expected a spike measured from when #20 was last seen, got %+v", events)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	missing, err := LoadSnapshot(path)
+	if err != nil || missing != nil {
+		t.Fatalf("expected nil snapshot for missing file, got %v, %v", missing, err)
+	}
+
+	snap := NewSnapshot([]api.Item{item(1, 10, 100)}, time.Now())
+	if err := snap.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if loaded.Items[1].Occurrences != 100 || loaded.Items[1].Counter != 10 {
+		t.Errorf("unexpected snapshot contents: %+v", loaded.Items)
+	}
+}
+
+func TestBuildPayload(t *testing.T) {
+	events := []Event{{Type: EventSpike, Counter: 10, Title: "Boom", Level: "error", Delta: 50, RatePerMinute: 5}}
+	now := time.Now()
+
+	t.Run("json", func(t *testing.T) {
+		data, err := BuildPayload(FormatJSON, events, now)
+		if err != nil {
+			t.Fatalf("BuildPayload failed: %v", err)
+		}
+		var payload struct {
+			Source string  `json:"source"`
+			Events []Event `json:"events"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if payload.Source != "rollbar-cli" || len(payload.Events) != 1 {
+			t.Errorf("unexpected payload: %s", data)
+		}
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		data, err := BuildPayload(FormatSlack, events, now)
+		if err != nil {
+			t.Fatalf("BuildPayload failed: %v", err)
+		}
+		var payload map[string]string
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if !strings.Contains(payload["text"], "#10* Boom") || !strings.Contains(payload["text"], "5.0/min") {
+			t.Errorf("unexpected slack text: %q", payload["text"])
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := BuildPayload("xml", events, now); err == nil {
+			t.Error("expected error for unknown format")
+		}
+	})
+}
+
+func TestWebhookSend(t *testing.T) {
+	var got []byte
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %s", r.Header.Get("Content-Type"))
+		}
+		auth = r.Header.Get("Authorization")
+		got, _ = io.ReadAll(r.Body)
+		if strings.Contains(string(got), "fail") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad payload"))
+		}
+	}))
+	defer server.Close()
+
+	hook := &Webhook{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer x"}}
+	if err := hook.Send([]byte(`{"text":"hi"}`)); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if string(got) != `{"text":"hi"}` || auth != "Bearer x" {
+		t.Errorf("unexpected request: body=%s auth=%s", got, auth)
+	}
+
+	err := hook.Send([]byte(`{"text":"fail"}`))
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
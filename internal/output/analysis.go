@@ -0,0 +1,208 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// analysisTopValues is how many of the most common values are listed per field
+const analysisTopValues = 3
+
+// fieldVariance summarizes the values of one field across occurrences
+type fieldVariance struct {
+	Field    string       `json:"field"`
+	Distinct int          `json:"distinct"`
+	Missing  int          `json:"missing,omitempty"` // Occurrences without the field
+	Top      []valueCount `json:"top"`
+}
+
+// valueCount is a field value and the number of occurrences that have it
+type valueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// occurrenceAnalysis summarizes what is constant and what varies across occurrences
+type occurrenceAnalysis struct {
+	Occurrences int             `json:"occurrences"`
+	Constant    []fieldVariance `json:"constant"`
+	Varying     []fieldVariance `json:"varying"`
+}
+
+// analyzeOccurrences compares request, client, person, server and custom data
+// across instances. Fields that no occurrence has are left out, and nil is
+// returned for fewer than two occurrences.
+func analyzeOccurrences(instances []api.Instance) *occurrenceAnalysis {
+	if len(instances) < 2 {
+		return nil
+	}
+
+	values := map[string][]string{} // Field -> value per occurrence ("" when missing)
+	var fixed, params, custom []string
+	record := func(group *[]string, field string, i int, value string) {
+		if value == "" {
+			return
+		}
+		if _, ok := values[field]; !ok {
+			values[field] = make([]string, len(instances))
+			*group = append(*group, field)
+		}
+		if values[field][i] == "" {
+			values[field][i] = value
+		}
+	}
+
+	for i := range instances {
+		data := &instances[i].Data
+		if req := data.Request; req != nil {
+			record(&fixed, "url", i, stripQuery(req.URL))
+			record(&fixed, "method", i, req.Method)
+			for _, m := range []map[string]interface{}{req.GET, req.POST, req.Params} {
+				for key, v := range m {
+					record(&params, "params."+key, i, displayValue(v))
+				}
+			}
+			if len(req.GET) == 0 {
+				for key, v := range queryParams(req) {
+					record(&params, "params."+key, i, strings.Join(v, ","))
+				}
+			}
+		}
+		record(&fixed, "browser", i, getBrowser(data))
+		if data.Person != nil {
+			record(&fixed, "person_id", i, string(data.Person.ID))
+		}
+		if data.Server != nil {
+			record(&fixed, "host", i, data.Server.Host)
+		}
+		record(&fixed, "code_version", i, data.ResolvedCodeVersion())
+		for key, v := range data.Custom {
+			record(&custom, "custom."+key, i, displayValue(v))
+		}
+	}
+
+	// Fixed fields keep their natural order; params and custom keys are sorted
+	sort.Slice(fixed, func(i, j int) bool { return fieldRank(fixed[i]) < fieldRank(fixed[j]) })
+	sort.Strings(params)
+	sort.Strings(custom)
+
+	a := &occurrenceAnalysis{
+		Occurrences: len(instances),
+		Constant:    []fieldVariance{},
+		Varying:     []fieldVariance{},
+	}
+	for _, field := range append(append(fixed, params...), custom...) {
+		v := summarizeField(field, values[field])
+		if v.Distinct == 1 && v.Missing == 0 {
+			a.Constant = append(a.Constant, v)
+		} else {
+			a.Varying = append(a.Varying, v)
+		}
+	}
+	return a
+}
+
+var fieldOrder = []string{"url", "method", "browser", "person_id", "host", "code_version"}
+
+func fieldRank(field string) int {
+	for i, f := range fieldOrder {
+		if f == field {
+			return i
+		}
+	}
+	return len(fieldOrder)
+}
+
+// summarizeField counts the values of one field, most common first
+func summarizeField(field string, values []string) fieldVariance {
+	counts := map[string]int{}
+	v := fieldVariance{Field: field}
+	for _, value := range values {
+		if value == "" {
+			v.Missing++
+			continue
+		}
+		counts[value]++
+	}
+
+	all := make([]valueCount, 0, len(counts))
+	for value, n := range counts {
+		all = append(all, valueCount{Value: value, Count: n})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Count != all[j].Count {
+			return all[i].Count > all[j].Count
+		}
+		return all[i].Value < all[j].Value
+	})
+
+	v.Distinct = len(all)
+	v.Top = all[:min(len(all), analysisTopValues)]
+	return v
+}
+
+// stripQuery removes the query string and fragment from a URL, since request
+// params are analyzed separately
+func stripQuery(rawURL string) string {
+	rawURL, _, _ = strings.Cut(stripFragment(rawURL), "?")
+	return rawURL
+}
+
+func stripFragment(rawURL string) string {
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	return rawURL
+}
+
+// queryParams parses the query string of a request without GET params
+func queryParams(req *api.Request) url.Values {
+	query := req.QueryString
+	if query == "" {
+		_, query, _ = strings.Cut(stripFragment(req.URL), "?")
+	}
+	values, _ := url.ParseQuery(strings.TrimPrefix(query, "?"))
+	return values
+}
+
+// displayValue renders a param or custom value on one line, as JSON unless it is a string
+func displayValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// describeValues lists a field's top values with their counts, e.g.
+// "Safari 17 (4), Chrome 120 (1), +2 more, none (1)"
+func describeValues(v fieldVariance) string {
+	parts := make([]string, 0, len(v.Top)+2)
+	for _, vc := range v.Top {
+		parts = append(parts, fmt.Sprintf("%s (%d)", truncate(vc.Value, 60), vc.Count))
+	}
+	if more := v.Distinct - len(v.Top); more > 0 {
+		parts = append(parts, fmt.Sprintf("+%d more", more))
+	}
+	if v.Missing > 0 {
+		parts = append(parts, fmt.Sprintf("none (%d)", v.Missing))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// distinctLabel describes how many values a varying field has
+func distinctLabel(v fieldVariance) string {
+	if v.Missing > 0 {
+		return fmt.Sprintf("%d distinct, missing in %d", v.Distinct, v.Missing)
+	}
+	return fmt.Sprintf("%d distinct", v.Distinct)
+}
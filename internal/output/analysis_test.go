@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// varyingInstances builds Safari failures on one endpoint from different users
+func varyingInstances() []api.Instance {
+	instances := make([]api.Instance, 5)
+	for i := range instances {
+		host := "web-1"
+		if i >= 3 {
+			host = "web-2"
+		}
+		instances[i] = api.Instance{
+			ID: int64(i + 1),
+			Data: api.InstanceData{
+				Body: api.Body{Trace: &api.Trace{Exception: api.Exception{Class: "TypeError", Message: "x is undefined"}}},
+				Request: &api.Request{
+					Method: "POST",
+					URL:    "https://example.com/checkout?step=" + string(rune('1'+i%2)),
+					POST:   map[string]interface{}{"quantity": float64(i + 1)},
+				},
+				Client: &api.ClientInfo{JavaScript: &api.ClientJavaScript{Browser: "Safari 17.1", CodeVersion: "abc123"}},
+				Person: &api.Person{ID: api.JSONString(string(rune('a' + i)))},
+				Server: &api.Server{Host: host},
+			},
+		}
+	}
+	instances[0].Data.Custom = map[string]interface{}{"tenant": "acme"}
+	return instances
+}
+
+func TestAnalyzeOccurrences(t *testing.T) {
+	if a := analyzeOccurrences(varyingInstances()[:1]); a != nil {
+		t.Errorf("expected no analysis for one occurrence, got %+v", a)
+	}
+
+	a := analyzeOccurrences(varyingInstances())
+	if a == nil || a.Occurrences != 5 {
+		t.Fatalf("unexpected analysis: %+v", a)
+	}
+
+	var constant []string
+	for _, v := range a.Constant {
+		constant = append(constant, v.Field+"="+v.Top[0].Value)
+	}
+	if got := strings.Join(constant, ","); got != "url=https://example.com/checkout,method=POST,browser=Safari 17.1,code_version=abc123" {
+		t.Errorf("unexpected constant fields: %s", got)
+	}
+
+	varying := map[string]fieldVariance{}
+	var order []string
+	for _, v := range a.Varying {
+		varying[v.Field] = v
+		order = append(order, v.Field)
+	}
+	if got := strings.Join(order, ","); got != "person_id,host,params.quantity,params.step,custom.tenant" {
+		t.Errorf("unexpected varying fields: %s", got)
+	}
+	if host := varying["host"]; host.Distinct != 2 || host.Top[0].Value != "web-1" || host.Top[0].Count != 3 {
+		t.Errorf("unexpected host summary: %+v", host)
+	}
+	if person := varying["person_id"]; person.Distinct != 5 || len(person.Top) != analysisTopValues {
+		t.Errorf("unexpected person summary: %+v", person)
+	}
+	if tenant := varying["custom.tenant"]; tenant.Distinct != 1 || tenant.Missing != 4 {
+		t.Errorf("unexpected custom summary: %+v", tenant)
+	}
+	if got := describeValues(varying["person_id"]); got != "a (1), b (1), c (1), +2 more" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestContextOccurrenceAnalysis(t *testing.T) {
+	formatters := map[string]struct {
+		f    Formatter
+		want []string
+	}{
+		"markdown": {&MarkdownFormatter{}, []string{
+			"## Occurrence Analysis (5 occurrences)",
+			"- **browser:** Safari 17.1",
+			"- **host** (2 distinct): web-1 (3), web-2 (2)",
+			"- **custom.tenant** (1 distinct, missing in 4): acme (1), none (4)",
+		}},
+		"compact": {&CompactFormatter{}, []string{
+			"## Variance (5 occ)",
+			"Same: url=https://example.com/checkout | method=POST | browser=Safari 17.1",
+			"- host (2 distinct): web-1 (3), web-2 (2)",
+		}},
+		"table": {&TableFormatter{}, []string{
+			"Occurrence Analysis (5)",
+			"browser:",
+		}},
+	}
+
+	for name, tt := range formatters {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.f.FormatContext(&buf, sampleItem(), varyingInstances()); err != nil {
+				t.Fatalf("FormatContext failed: %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("expected %q in output:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := (&JSONFormatter{}).FormatContext(&buf, sampleItem(), varyingInstances()); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+	var result struct {
+		Analysis *occurrenceAnalysis `json:"analysis"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if result.Analysis == nil || len(result.Analysis.Varying) != 5 {
+		t.Errorf("unexpected JSON analysis: %+v", result.Analysis)
+	}
+}
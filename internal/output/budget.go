@@ -0,0 +1,240 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// unlimited means "no cap" for a contextLimits count
+const unlimited = -1
+
+// contextLimits controls how much detail FormatContext includes
+type contextLimits struct {
+	appFrames     int  // App frames shown (unlimited = all)
+	vendorFrames  int  // Vendor frames shown (unlimited = all)
+	vendorWithApp bool // Show vendor frames even when app frames exist
+	occurrences   int  // Occurrences listed (unlimited = all passed in)
+	code          bool // Show source lines for frames
+	request       bool // Show the request section
+	person        bool // Show the person section
+	analysis      bool // Show the cross-occurrence analysis
+	requestData   bool // Show request headers, params and body (Details.Request)
+	customData    bool // Show custom data (Details.Custom)
+
+	// Vendor frames already shown for an earlier item of a bundle, keyed by
+	// frameKey, to that item's counter. They are collapsed into a reference.
+	seenVendor map[string]int
+}
+
+// budgetSteps are applied in order until the context fits the token budget.
+// They drop detail in reverse priority: vendor frames (down to 5, then none),
+// extra occurrences (down to 3, then 1), the analysis, custom data, request
+// data, the person, the request, app frames beyond 10, frame code, and
+// finally app frames down to 5 and then 1. The exception is always kept.
+var budgetSteps = []func(l *contextLimits) bool{
+	func(l *contextLimits) bool { return capLimit(&l.vendorFrames, 5) },
+	func(l *contextLimits) bool { return capLimit(&l.vendorFrames, 0) },
+	func(l *contextLimits) bool { return capLimit(&l.occurrences, 3) },
+	func(l *contextLimits) bool { return capLimit(&l.occurrences, 1) },
+	func(l *contextLimits) bool { return clearFlag(&l.analysis) },
+	func(l *contextLimits) bool { return clearFlag(&l.customData) },
+	func(l *contextLimits) bool { return clearFlag(&l.requestData) },
+	func(l *contextLimits) bool { return clearFlag(&l.person) },
+	func(l *contextLimits) bool { return clearFlag(&l.request) },
+	func(l *contextLimits) bool { return capLimit(&l.appFrames, 10) },
+	func(l *contextLimits) bool { return clearFlag(&l.code) },
+	func(l *contextLimits) bool { return capLimit(&l.appFrames, 5) },
+	func(l *contextLimits) bool { return capLimit(&l.appFrames, 1) },
+}
+
+func capLimit(v *int, max int) bool {
+	if *v != unlimited && *v <= max {
+		return false
+	}
+	*v = max
+	return true
+}
+
+func clearFlag(v *bool) bool {
+	if !*v {
+		return false
+	}
+	*v = false
+	return true
+}
+
+// withDetails enables the optional sections selected by d
+func (l contextLimits) withDetails(d Details) contextLimits {
+	l.requestData = d.Request
+	l.customData = d.Custom
+	return l
+}
+
+// fullLimits includes everything available except optional details
+func fullLimits() contextLimits {
+	return contextLimits{
+		appFrames:     unlimited,
+		vendorFrames:  unlimited,
+		vendorWithApp: true,
+		occurrences:   unlimited,
+		code:          true,
+		request:       true,
+		person:        true,
+		analysis:      true,
+	}
+}
+
+// limitCount applies a contextLimits count to n available entries
+func limitCount(limit, n int) int {
+	if limit == unlimited || limit > n {
+		return n
+	}
+	return limit
+}
+
+// EstimateTokens approximates the LLM token count of s (about four characters per token)
+func EstimateTokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+// writeBudgetedContext renders the context with the most detail that fits in
+// maxTokens, starting from full, followed by a trailer listing what omit
+// reports as left out
+func writeBudgetedContext(w io.Writer, maxTokens int, full contextLimits,
+	render func(w io.Writer, lim contextLimits) error, omit func(lim contextLimits) []string,
+	trailer func(omitted []string) string) error {
+
+	lim := full
+	var buf bytes.Buffer
+	var tail string
+	step := 0
+
+	for {
+		buf.Reset()
+		if err := render(&buf, lim); err != nil {
+			return err
+		}
+		tail = ""
+		if omitted := omit(lim); len(omitted) > 0 {
+			tail = trailer(omitted)
+		}
+		if EstimateTokens(buf.String()+tail) <= maxTokens {
+			break
+		}
+
+		// Apply the next step that actually reduces detail
+		reduced := false
+		for step < len(budgetSteps) && !reduced {
+			reduced = budgetSteps[step](&lim)
+			step++
+		}
+		if !reduced {
+			// Nothing left to drop; emit the minimal context
+			break
+		}
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, tail)
+	return err
+}
+
+// omissions describes what lim leaves out of the context compared to full
+func omissions(instances []api.Instance, full, lim contextLimits) []string {
+	var omitted []string
+	if len(instances) == 0 {
+		return nil
+	}
+
+	inst := instances[0]
+	appOmitted, vendorOmitted, codeOmitted := 0, 0, false
+	for _, trace := range inst.Data.Body.Traces() {
+		app, vendor := separateFrames(trace.Frames)
+		vendor, _, _ = lim.unseenVendor(vendor)
+		appOmitted += len(app) - limitCount(lim.appFrames, len(app))
+		vendorOmitted += len(vendor) - limitCount(lim.vendorFrames, len(vendor))
+		if !lim.code && framesHaveCode(app) {
+			codeOmitted = true
+		}
+	}
+	if appOmitted > 0 {
+		omitted = append(omitted, plural(appOmitted, "app frame"))
+	}
+	if codeOmitted {
+		omitted = append(omitted, "frame code")
+	}
+	if vendorOmitted > 0 {
+		omitted = append(omitted, plural(vendorOmitted, "vendor frame"))
+	}
+	if full.requestData && !lim.requestData && len(requestGroups(inst.Data.Request)) > 0 {
+		omitted = append(omitted, "request data")
+	}
+	if full.customData && !lim.customData && len(inst.Data.Custom) > 0 {
+		omitted = append(omitted, "custom data")
+	}
+	if !lim.request && inst.Data.Request != nil && inst.Data.Request.URL != "" {
+		omitted = append(omitted, "request")
+	}
+	if !lim.person && inst.Data.Person != nil && inst.Data.Person.ID != "" {
+		omitted = append(omitted, "person")
+	}
+	if n := len(instances) - limitCount(lim.occurrences, len(instances)); n > 0 {
+		omitted = append(omitted, plural(n, "occurrence"))
+	}
+	if !lim.analysis && len(instances) > 1 {
+		omitted = append(omitted, "occurrence analysis")
+	}
+	return omitted
+}
+
+// frameKey identifies a frame across items
+func frameKey(frame api.Frame) string {
+	return fmt.Sprintf("%s:%d:%s", frame.Filename, frame.Lineno, frame.Method)
+}
+
+// unseenVendor drops vendor frames already shown for another item, returning
+// how many were dropped and the counter of the item that showed the first one
+func (l contextLimits) unseenVendor(vendor []api.Frame) (unseen []api.Frame, shared, sharedWith int) {
+	if len(l.seenVendor) == 0 {
+		return vendor, 0, 0
+	}
+	for _, frame := range vendor {
+		counter, ok := l.seenVendor[frameKey(frame)]
+		if !ok {
+			unseen = append(unseen, frame)
+			continue
+		}
+		if shared == 0 {
+			sharedWith = counter
+		}
+		shared++
+	}
+	return unseen, shared, sharedWith
+}
+
+func framesHaveCode(frames []api.Frame) bool {
+	for _, f := range frames {
+		if f.Code != "" || len(f.Context.Pre) > 0 || len(f.Context.Post) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func joinOmitted(omitted []string) string {
+	return strings.Join(omitted, ", ")
+}
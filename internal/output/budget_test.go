@@ -0,0 +1,150 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// largeInstances builds occurrences with long traces so budgets bite
+func largeInstances(n int) []api.Instance {
+	var frames []api.Frame
+	for i := 0; i < 30; i++ {
+		frames = append(frames, api.Frame{
+			Filename: fmt.Sprintf("/app/app/models/model_%d.rb", i),
+			Lineno:   i + 1,
+			Method:   fmt.Sprintf("method_%d", i),
+			Code:     "do_something_with(record, options)",
+		})
+	}
+	for i := 0; i < 40; i++ {
+		frames = append(frames, api.Frame{
+			Filename: fmt.Sprintf("/usr/local/bundle/gems/rack-2.2.0/lib/rack/file_%d.rb", i),
+			Lineno:   i + 1,
+			Method:   "call",
+		})
+	}
+
+	instances := make([]api.Instance, n)
+	for i := range instances {
+		instances[i] = api.Instance{
+			ID:   int64(1000 + i),
+			Time: time.Date(2024, 1, 15, 10, i, 0, 0, time.UTC),
+			Data: api.InstanceData{
+				Body: api.Body{Trace: &api.Trace{
+					Frames:    frames,
+					Exception: api.Exception{Class: "NoMethodError", Message: "undefined method 'name' for nil"},
+				}},
+				Request: &api.Request{Method: "GET", URL: "https://example.com/users/1"},
+				Person:  &api.Person{ID: "42", Email: "test@example.com"},
+			},
+		}
+	}
+	return instances
+}
+
+func TestContextTokenBudget(t *testing.T) {
+	formatters := map[string]func(max int) Formatter{
+		"markdown": func(max int) Formatter { return &MarkdownFormatter{MaxTokens: max} },
+		"compact":  func(max int) Formatter { return &CompactFormatter{MaxTokens: max} },
+	}
+
+	for name, newFormatter := range formatters {
+		t.Run(name, func(t *testing.T) {
+			instances := largeInstances(10)
+
+			var full bytes.Buffer
+			if err := newFormatter(1000000).FormatContext(&full, sampleItem(), instances); err != nil {
+				t.Fatalf("FormatContext failed: %v", err)
+			}
+			if strings.Contains(strings.ToLower(full.String()), "omitted to fit") {
+				t.Error("expected no omission trailer when everything fits")
+			}
+			if !strings.Contains(full.String(), "rack/file_39.rb") {
+				t.Error("expected all vendor frames with a large budget")
+			}
+
+			tests := []struct {
+				max      int
+				want     []string
+				dontWant []string
+			}{
+				{
+					max:      1000,
+					want:     []string{"NoMethodError", "model_0.rb", "vendor frames"},
+					dontWant: []string{"rack/file_39.rb"},
+				},
+				{
+					max:      200,
+					want:     []string{"NoMethodError", "model_0.rb", "request", "person"},
+					dontWant: []string{"model_29.rb", "test@example.com"},
+				},
+			}
+
+			for _, tt := range tests {
+				var buf bytes.Buffer
+				if err := newFormatter(tt.max).FormatContext(&buf, sampleItem(), instances); err != nil {
+					t.Fatalf("FormatContext failed: %v", err)
+				}
+				out := buf.String()
+
+				if got := EstimateTokens(out); got > tt.max {
+					t.Errorf("max %d: estimated %d tokens", tt.max, got)
+				}
+				if !strings.Contains(strings.ToLower(out), "omitted to fit") {
+					t.Errorf("max %d: expected omission trailer", tt.max)
+				}
+				for _, s := range tt.want {
+					if !strings.Contains(out, s) {
+						t.Errorf("max %d: expected %q in output", tt.max, s)
+					}
+				}
+				for _, s := range tt.dontWant {
+					if strings.Contains(out, s) {
+						t.Errorf("max %d: did not expect %q in output", tt.max, s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestContextWithoutBudgetUnchanged(t *testing.T) {
+	var buf bytes.Buffer
+	f := &CompactFormatter{}
+	if err := f.FormatContext(&buf, sampleItem(), largeInstances(3)); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "...+20 more app frames") {
+		t.Error("expected default cap of 10 app frames")
+	}
+	if strings.Contains(out, "## Vendor Frames") {
+		t.Error("expected vendor frames hidden when app frames exist")
+	}
+	if strings.Contains(out, "## Other Occurrences") {
+		t.Error("expected only the latest occurrence without a budget")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"héllo wörld", 3},
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.in); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
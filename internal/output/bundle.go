@@ -0,0 +1,245 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// ContextEntry is an item and its recent occurrences, newest first
+type ContextEntry struct {
+	Item      *api.Item
+	Instances []api.Instance
+}
+
+// BundleFormatter renders the context of several related items, such as
+// those firing during an incident, as one document
+type BundleFormatter interface {
+	FormatBundle(w io.Writer, entries []ContextEntry) error
+}
+
+// bundleTimelineEvents caps the occurrences listed in a bundle's timeline
+const bundleTimelineEvents = 20
+
+// sharedValue is a host, code version or person and the items it appears in
+type sharedValue struct {
+	Value       string `json:"value"`
+	Items       []int  `json:"items"` // Item counters
+	Occurrences int    `json:"occurrences"`
+}
+
+// timelineEvent is one occurrence on a bundle's common timeline
+type timelineEvent struct {
+	Time        time.Time `json:"time"`
+	Item        int       `json:"item"`
+	Occurrence  int64     `json:"occurrence"`
+	Host        string    `json:"host,omitempty"`
+	CodeVersion string    `json:"code_version,omitempty"`
+}
+
+// bundleSummary is what the items of a bundle have in common
+type bundleSummary struct {
+	Items           []int           `json:"items"`
+	Occurrences     int             `json:"occurrences"` // Fetched occurrences across items
+	Start           *time.Time      `json:"start,omitempty"`
+	End             *time.Time      `json:"end,omitempty"`
+	Hosts           []sharedValue   `json:"hosts"`
+	CodeVersions    []sharedValue   `json:"code_versions"`
+	People          []sharedValue   `json:"people"`
+	Timeline        []timelineEvent `json:"timeline"`
+	TimelineOmitted int             `json:"timeline_omitted,omitempty"` // Earlier occurrences left off the timeline
+}
+
+// valueIndex collects sharedValues by value
+type valueIndex map[string]*sharedValue
+
+func (idx valueIndex) add(value string, counter int) {
+	if value == "" {
+		return
+	}
+	v, ok := idx[value]
+	if !ok {
+		v = &sharedValue{Value: value}
+		idx[value] = v
+	}
+	v.Occurrences++
+	if n := len(v.Items); n == 0 || v.Items[n-1] != counter {
+		v.Items = append(v.Items, counter)
+	}
+}
+
+// sorted returns the values appearing in the most items first
+func (idx valueIndex) sorted() []sharedValue {
+	out := make([]sharedValue, 0, len(idx))
+	for _, v := range idx {
+		out = append(out, *v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Items) != len(out[j].Items) {
+			return len(out[i].Items) > len(out[j].Items)
+		}
+		if out[i].Occurrences != out[j].Occurrences {
+			return out[i].Occurrences > out[j].Occurrences
+		}
+		return out[i].Value < out[j].Value
+	})
+	return out
+}
+
+// summarizeBundle merges the occurrences of every entry into a common
+// timeline and finds the hosts, code versions and people they share
+func summarizeBundle(entries []ContextEntry) *bundleSummary {
+	s := &bundleSummary{Items: make([]int, 0, len(entries))}
+	hosts, versions, people := valueIndex{}, valueIndex{}, valueIndex{}
+
+	for _, e := range entries {
+		counter := e.Item.Counter
+		s.Items = append(s.Items, counter)
+		for _, inst := range e.Instances {
+			data := &inst.Data
+			ev := timelineEvent{Time: inst.Time, Item: counter, Occurrence: inst.ID, CodeVersion: data.ResolvedCodeVersion()}
+			if data.Server != nil {
+				ev.Host = data.Server.Host
+			}
+			s.Timeline = append(s.Timeline, ev)
+
+			hosts.add(ev.Host, counter)
+			versions.add(ev.CodeVersion, counter)
+			if p := data.Person; p != nil {
+				// Group by the stable ID, which also keeps emails out of the bundle
+				if p.ID != "" {
+					people.add(string(p.ID), counter)
+				} else {
+					people.add(p.Username, counter)
+				}
+			}
+		}
+	}
+
+	s.Occurrences = len(s.Timeline)
+	s.Hosts, s.CodeVersions, s.People = hosts.sorted(), versions.sorted(), people.sorted()
+
+	sort.SliceStable(s.Timeline, func(i, j int) bool { return s.Timeline[i].Time.Before(s.Timeline[j].Time) })
+	if n := len(s.Timeline); n > 0 {
+		start, end := s.Timeline[0].Time, s.Timeline[n-1].Time
+		s.Start, s.End = &start, &end
+	}
+	if n := len(s.Timeline); n > bundleTimelineEvents {
+		s.TimelineOmitted = n - bundleTimelineEvents
+		s.Timeline = s.Timeline[s.TimelineOmitted:]
+	}
+	if s.Timeline == nil {
+		s.Timeline = []timelineEvent{}
+	}
+	return s
+}
+
+// describeShared lists values found in more than one item with the items
+// they appear in, e.g. "web-1 (#101, #102), +2 in one item only"
+func describeShared(values []sharedValue) string {
+	var parts []string
+	single := 0
+	for _, v := range values {
+		if len(v.Items) < 2 {
+			single++
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", truncate(v.Value, 60), itemRefs(v.Items)))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("no overlap (%d distinct)", len(values))
+	}
+	if single > 0 {
+		parts = append(parts, fmt.Sprintf("+%d in one item only", single))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// timelineDetail describes where and on which version an occurrence happened
+func timelineDetail(ev timelineEvent) string {
+	var detail string
+	if ev.Host != "" {
+		detail += " on " + ev.Host
+	}
+	if ev.CodeVersion != "" {
+		detail += " (" + shortVersion(ev.CodeVersion) + ")"
+	}
+	return detail
+}
+
+// shortVersion abbreviates a commit SHA code version
+func shortVersion(v string) string {
+	if len(v) == 40 && strings.Trim(v, "0123456789abcdef") == "" {
+		return v[:12]
+	}
+	return v
+}
+
+// itemRefs renders item counters as "#101, #102"
+func itemRefs(counters []int) string {
+	refs := make([]string, len(counters))
+	for i, c := range counters {
+		refs[i] = fmt.Sprintf("#%d", c)
+	}
+	return strings.Join(refs, ", ")
+}
+
+// bundleLimits gives every entry lim, marking the vendor frames an earlier
+// entry already shows so they are collapsed into a reference
+func bundleLimits(entries []ContextEntry, lim contextLimits) []contextLimits {
+	out := make([]contextLimits, len(entries))
+	seen := map[string]int{}
+	for i, e := range entries {
+		out[i] = lim
+		out[i].seenVendor = maps.Clone(seen)
+		if len(e.Instances) == 0 {
+			continue
+		}
+		for _, trace := range e.Instances[0].Data.Body.Traces() {
+			app, vendor := separateFrames(trace.Frames)
+			if len(app) > 0 && !lim.vendorWithApp {
+				continue
+			}
+			vendor, _, _ = out[i].unseenVendor(vendor)
+			for _, frame := range vendor[:limitCount(lim.vendorFrames, len(vendor))] {
+				if _, ok := seen[frameKey(frame)]; !ok {
+					seen[frameKey(frame)] = e.Item.Counter
+				}
+			}
+		}
+	}
+	return out
+}
+
+// bundleOmissions describes what lim leaves out of each entry's context
+func bundleOmissions(entries []ContextEntry, full, lim contextLimits) []string {
+	var omitted []string
+	for i, l := range bundleLimits(entries, lim) {
+		if o := omissions(entries[i].Instances, full, l); len(o) > 0 {
+			omitted = append(omitted, fmt.Sprintf("#%d (%s)", entries[i].Item.Counter, joinOmitted(o)))
+		}
+	}
+	return omitted
+}
+
+// demoteHeadings moves markdown headings outside code blocks one level down,
+// so a single-item context nests under a bundle
+func demoteHeadings(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	fenced := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced && strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "")
+}
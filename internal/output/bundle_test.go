@@ -0,0 +1,163 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// bundleEntries returns two items whose stack traces share rack frames and
+// whose occurrences share a host and a code version
+func bundleEntries() []ContextEntry {
+	first := largeInstances(2)
+	second := largeInstances(2)
+	hosts := []string{"web-1", "web-1", "web-1", "web-2"}
+	for i := range second {
+		second[i].ID += 10
+		second[i].Time = second[i].Time.Add(30 * time.Second)
+		second[i].Data.Body.Trace.Exception = api.Exception{Class: "ActiveRecord::RecordNotFound", Message: "Couldn't find User"}
+		second[i].Data.Person = &api.Person{ID: "7"}
+	}
+	for i := range first {
+		first[i].Data.Server = &api.Server{Host: hosts[i], CodeVersion: "abc123"}
+		second[i].Data.Server = &api.Server{Host: hosts[i+2], CodeVersion: "abc123"}
+	}
+
+	one, two := sampleItems()[0], sampleItems()[0]
+	one.Counter, one.Title = 101, "NoMethodError: undefined method 'name' for nil"
+	two.Counter, two.Title = 102, "ActiveRecord::RecordNotFound: Couldn't find User"
+	return []ContextEntry{{Item: &one, Instances: first}, {Item: &two, Instances: second}}
+}
+
+func TestSummarizeBundle(t *testing.T) {
+	s := summarizeBundle(bundleEntries())
+
+	if s.Occurrences != 4 || len(s.Timeline) != 4 {
+		t.Fatalf("expected 4 occurrences on the timeline, got %d/%d", s.Occurrences, len(s.Timeline))
+	}
+	if s.Timeline[0].Item != 101 || s.Timeline[1].Item != 102 {
+		t.Errorf("expected interleaved timeline, got %+v", s.Timeline)
+	}
+	if got := describeShared(s.Hosts); got != "web-1 (#101, #102), +1 in one item only" {
+		t.Errorf("unexpected hosts %q", got)
+	}
+	if got := describeShared(s.CodeVersions); got != "abc123 (#101, #102)" {
+		t.Errorf("unexpected versions %q", got)
+	}
+	if got := describeShared(s.People); got != "no overlap (2 distinct)" {
+		t.Errorf("unexpected people %q", got)
+	}
+}
+
+func TestFormatBundle(t *testing.T) {
+	tests := []struct {
+		name string
+		f    BundleFormatter
+		want []string
+	}{
+		{"markdown", &MarkdownFormatter{}, []string{
+			"# Incident Context: 2 items\n",
+			"| 102 | ActiveRecord::RecordNotFound: Couldn't find User | error |",
+			"- **Hosts:** web-1 (#101, #102), +1 in one item only\n",
+			"## Timeline\n- 2024-01-15T10:00:00Z **#101** on web-1 (abc123)\n",
+			"## Bug Report: NoMethodError",
+			"### Exception Details",
+			"_40 vendor frames shared with #101, shown above._",
+		}},
+		{"compact", &CompactFormatter{}, []string{
+			"# Incident: 2 items, 4 occ\n",
+			"- #101 [error] NoMethodError",
+			"Versions: abc123 (#101, #102)\n",
+			"# Error #102: ActiveRecord::RecordNotFound",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.f.FormatBundle(&buf, bundleEntries()); err != nil {
+				t.Fatalf("FormatBundle failed: %v", err)
+			}
+			out := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected %q in output:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatBundleCollapsesVendorFrames(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{}).FormatBundle(&buf, bundleEntries()); err != nil {
+		t.Fatalf("FormatBundle failed: %v", err)
+	}
+	if n := strings.Count(buf.String(), "rack/file_0.rb:1 in call()"); n != 1 {
+		t.Errorf("expected the shared vendor frame once, got %d", n)
+	}
+}
+
+func TestFormatBundleBudget(t *testing.T) {
+	var full bytes.Buffer
+	if err := (&MarkdownFormatter{}).FormatBundle(&full, bundleEntries()); err != nil {
+		t.Fatalf("FormatBundle failed: %v", err)
+	}
+
+	max := EstimateTokens(full.String()) / 2
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{MaxTokens: max}).FormatBundle(&buf, bundleEntries()); err != nil {
+		t.Fatalf("FormatBundle failed: %v", err)
+	}
+	out := buf.String()
+	if EstimateTokens(out) > max {
+		t.Errorf("expected output within %d tokens, got %d", max, EstimateTokens(out))
+	}
+	if !strings.Contains(out, "_Omitted to fit") || !strings.Contains(out, "#101 (") {
+		t.Errorf("expected per-item omissions in the trailer:\n%s", out[len(out)-300:])
+	}
+	if !strings.Contains(out, "### Exception Details") || strings.Count(out, "## Bug Report") != 2 {
+		t.Error("expected both items to keep their exception")
+	}
+}
+
+func TestJSONFormatBundle(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JSONFormatter{}).FormatBundle(&buf, bundleEntries()); err != nil {
+		t.Fatalf("FormatBundle failed: %v", err)
+	}
+	var got struct {
+		Summary struct {
+			Items []int `json:"items"`
+			Hosts []struct {
+				Value string `json:"value"`
+				Items []int  `json:"items"`
+			} `json:"hosts"`
+		} `json:"summary"`
+		Items []struct {
+			Item      api.Item       `json:"item"`
+			Instances []api.Instance `json:"instances"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got.Summary.Items) != 2 || len(got.Items) != 2 || len(got.Items[1].Instances) != 2 {
+		t.Errorf("unexpected bundle %+v", got)
+	}
+	if got.Summary.Hosts[0].Value != "web-1" || len(got.Summary.Hosts[0].Items) != 2 {
+		t.Errorf("unexpected hosts %+v", got.Summary.Hosts)
+	}
+}
+
+func TestDemoteHeadings(t *testing.T) {
+	in := "# Title\n## Section\n```\n# comment in code\n```\ntext # not a heading\n"
+	want := "## Title\n### Section\n```\n# comment in code\n```\ntext # not a heading\n"
+	if got := demoteHeadings(in); got != want {
+		t.Errorf("demoteHeadings() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// headline returns the class and message that best summarize an occurrence:
+// the outermost exception, the message body, or the first line of a crash report
+func headline(body *api.Body) (class, message string) {
+	if traces := body.Traces(); len(traces) > 0 {
+		return traces[0].Exception.Class, traces[0].Exception.Message
+	}
+	if body.Message != nil {
+		return "", body.Message.Body
+	}
+	if raw := crashReport(body); raw != "" {
+		line, _, _ := strings.Cut(raw, "\n")
+		return "", line
+	}
+	return "", ""
+}
+
+// headlineString joins the headline class and message as "Class: message"
+func headlineString(body *api.Body) string {
+	class, message := headline(body)
+	if class == "" {
+		return message
+	}
+	return class + ": " + message
+}
+
+// crashReport returns the raw crash report text, if any
+func crashReport(body *api.Body) string {
+	if body.CrashReport == nil {
+		return ""
+	}
+	return strings.TrimSpace(body.CrashReport.Raw)
+}
+
+// chainLink is one exception of a cause chain in JSON context output
+type chainLink struct {
+	Class        string      `json:"class"`
+	Message      string      `json:"message"`
+	Description  string      `json:"description,omitempty"`
+	AppFrames    []api.Frame `json:"app_frames"`
+	VendorFrames []api.Frame `json:"vendor_frames"`
+}
+
+// exceptionChain converts the traces of body into chain links, outermost first
+func exceptionChain(body *api.Body) []chainLink {
+	var chain []chainLink
+	for _, trace := range body.Traces() {
+		app, vendor := separateFrames(trace.Frames)
+		if app == nil {
+			app = []api.Frame{}
+		}
+		if vendor == nil {
+			vendor = []api.Frame{}
+		}
+		chain = append(chain, chainLink{
+			Class:        trace.Exception.Class,
+			Message:      trace.Exception.Message,
+			Description:  trace.Exception.Description,
+			AppFrames:    app,
+			VendorFrames: vendor,
+		})
+	}
+	return chain
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func chainedInstance() api.Instance {
+	return api.Instance{
+		ID:   2001,
+		Time: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		Data: api.InstanceData{
+			Level: "error",
+			Body: api.Body{TraceChain: []api.Trace{
+				{
+					Exception: api.Exception{Class: "ServiceError", Message: "could not load user"},
+					Frames: []api.Frame{
+						{Filename: "/app/app/services/users.rb", Lineno: 12, Method: "load"},
+						{Filename: "/usr/local/bundle/gems/rack-2.2.0/lib/rack.rb", Lineno: 3, Method: "call"},
+					},
+				},
+				{
+					Exception: api.Exception{Class: "KeyError", Message: "key not found: :id"},
+					Frames: []api.Frame{
+						{Filename: "/app/app/models/user.rb", Lineno: 40, Method: "fetch_id"},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func crashInstance() api.Instance {
+	return api.Instance{
+		ID:   2002,
+		Time: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		Data: api.InstanceData{
+			Level: "critical",
+			Body: api.Body{CrashReport: &api.CrashReport{
+				Raw: "Exception Type:  EXC_BAD_ACCESS (SIGSEGV)\nThread 0 Crashed:\n0   MyApp  0x0001 main + 12\n",
+			}},
+		},
+	}
+}
+
+func TestFormatExceptionChain(t *testing.T) {
+	formatters := map[string]Formatter{
+		"table":    &TableFormatter{},
+		"compact":  &CompactFormatter{},
+		"markdown": &MarkdownFormatter{},
+	}
+
+	for name, f := range formatters {
+		t.Run(name, func(t *testing.T) {
+			inst := chainedInstance()
+			outputs := map[string]func(w *bytes.Buffer) error{
+				"FormatInstance": func(w *bytes.Buffer) error { return f.FormatInstance(w, &inst) },
+				"FormatContext": func(w *bytes.Buffer) error {
+					return f.FormatContext(w, sampleItem(), []api.Instance{inst})
+				},
+			}
+
+			for method, fn := range outputs {
+				var buf bytes.Buffer
+				if err := fn(&buf); err != nil {
+					t.Fatalf("%s failed: %v", method, err)
+				}
+				out := buf.String()
+				for _, want := range []string{"ServiceError", "KeyError", "key not found: :id"} {
+					if !strings.Contains(out, want) {
+						t.Errorf("%s: expected %q in output", method, want)
+					}
+				}
+				if !strings.Contains(strings.ToLower(out), "caused by") {
+					t.Errorf("%s: expected a caused by marker", method)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatCrashReport(t *testing.T) {
+	formatters := map[string]Formatter{
+		"table":    &TableFormatter{},
+		"compact":  &CompactFormatter{},
+		"markdown": &MarkdownFormatter{},
+	}
+
+	for name, f := range formatters {
+		t.Run(name, func(t *testing.T) {
+			inst := crashInstance()
+
+			var buf bytes.Buffer
+			if err := f.FormatInstance(&buf, &inst); err != nil {
+				t.Fatalf("FormatInstance failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), "Thread 0 Crashed:") {
+				t.Error("expected crash report text in instance output")
+			}
+
+			buf.Reset()
+			if err := f.FormatInstances(&buf, []api.Instance{inst}); err != nil {
+				t.Fatalf("FormatInstances failed: %v", err)
+			}
+			if !strings.Contains(buf.String(), "EXC_BAD_ACCESS") {
+				t.Error("expected crash report headline in instances output")
+			}
+		})
+	}
+}
+
+func TestJSONContextExceptionChain(t *testing.T) {
+	var buf bytes.Buffer
+	f := &JSONFormatter{}
+	if err := f.FormatContext(&buf, sampleItem(), []api.Instance{chainedInstance()}); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+
+	var result struct {
+		ExceptionChain []struct {
+			Class        string      `json:"class"`
+			AppFrames    []api.Frame `json:"app_frames"`
+			VendorFrames []api.Frame `json:"vendor_frames"`
+		} `json:"exception_chain"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if len(result.ExceptionChain) != 2 {
+		t.Fatalf("expected 2 chain links, got %d", len(result.ExceptionChain))
+	}
+	outer := result.ExceptionChain[0]
+	if outer.Class != "ServiceError" || len(outer.AppFrames) != 1 || len(outer.VendorFrames) != 1 {
+		t.Errorf("unexpected outer link: %+v", outer)
+	}
+	if result.ExceptionChain[1].Class != "KeyError" {
+		t.Errorf("expected innermost cause KeyError, got %s", result.ExceptionChain[1].Class)
+	}
+}
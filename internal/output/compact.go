@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,12 +11,20 @@ import (
 )
 
 // CompactFormatter outputs minimal, token-efficient format for AI agents
-type CompactFormatter struct{}
+type CompactFormatter struct {
+	MaxTokens int // Token budget for FormatContext (0 = no budget)
+	Details   Details
+}
 
-func formatCompactTime(t time.Time) string {
+// FormatCompactTime returns t as "5m ago", "3h ago" or "2d ago", or as an
+// absolute time when --time-format asks for one
+func FormatCompactTime(t time.Time) string {
 	if t.IsZero() {
 		return "?"
 	}
+	if timeFormat != TimeRelative {
+		return FormatTime(t)
+	}
 	d := time.Since(t)
 	switch {
 	case d < time.Hour:
@@ -27,8 +36,8 @@ func formatCompactTime(t time.Time) string {
 	}
 }
 
-// isAppFrame returns true if the frame is from app code (not vendor/gem/node_modules)
-func isAppFrame(frame api.Frame) bool {
+// IsAppFrame returns true if the frame is from app code (not vendor/gem/node_modules)
+func IsAppFrame(frame api.Frame) bool {
 	f := frame.Filename
 	// Vendor/gem patterns to exclude
 	vendorPatterns := []string{
@@ -74,10 +83,22 @@ func isAppFrame(frame api.Frame) bool {
 	return false
 }
 
+// TopAppFrame returns the first app frame of an occurrence, or nil
+func TopAppFrame(body *api.Body) *api.Frame {
+	for _, trace := range body.Traces() {
+		for i := range trace.Frames {
+			if IsAppFrame(trace.Frames[i]) {
+				return &trace.Frames[i]
+			}
+		}
+	}
+	return nil
+}
+
 // separateFrames splits frames into app code and vendor code
 func separateFrames(frames []api.Frame) (app []api.Frame, vendor []api.Frame) {
 	for _, frame := range frames {
-		if isAppFrame(frame) {
+		if IsAppFrame(frame) {
 			app = append(app, frame)
 		} else {
 			vendor = append(vendor, frame)
@@ -86,6 +107,39 @@ func separateFrames(frames []api.Frame) (app []api.Frame, vendor []api.Frame) {
 	return
 }
 
+// writeFrameCode writes a frame's source line, numbered and with surrounding
+// lines when the frame has context
+func writeFrameCode(w io.Writer, frame api.Frame) {
+	pre, post := frame.Context.Pre, frame.Context.Post
+	if len(pre) == 0 && len(post) == 0 {
+		if frame.Code != "" {
+			fmt.Fprintf(w, "  > %s\n", frame.Code)
+		}
+		return
+	}
+
+	first := frame.Lineno - len(pre)
+	width := len(strconv.Itoa(frame.Lineno + len(post)))
+	for i, line := range pre {
+		fmt.Fprintf(w, "    %*d | %s\n", width, first+i, line)
+	}
+	fmt.Fprintf(w, "  > %*d | %s\n", width, frame.Lineno, frame.Code)
+	for i, line := range post {
+		fmt.Fprintf(w, "    %*d | %s\n", width, frame.Lineno+1+i, line)
+	}
+}
+
+// writeFrameBlame writes the local commit that last touched a frame's line
+func writeFrameBlame(w io.Writer, frame api.Frame) {
+	if b := frame.Blame; b != nil {
+		commit := b.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		fmt.Fprintf(w, "  blame: %s %s %s %q\n", commit, b.Time.UTC().Format("2006-01-02"), b.Author, b.Summary)
+	}
+}
+
 func (f *CompactFormatter) FormatItems(w io.Writer, items []api.Item) error {
 	for _, item := range items {
 		// First line: counter, title, level, occurrences
@@ -96,10 +150,14 @@ func (f *CompactFormatter) FormatItems(w io.Writer, items []api.Item) error {
 			item.TotalOccurrences,
 		)
 		// Second line: timing info
-		fmt.Fprintf(w, "  Last: %s | First: %s\n",
-			formatCompactTime(item.LastOccurrenceTime),
-			formatCompactTime(item.FirstOccurrenceTime),
+		fmt.Fprintf(w, "  Last: %s | First: %s",
+			FormatCompactTime(item.LastOccurrenceTime),
+			FormatCompactTime(item.FirstOccurrenceTime),
 		)
+		if item.Owners != nil {
+			fmt.Fprintf(w, " | Owner: %s", ownersString(item.Owners))
+		}
+		fmt.Fprintln(w)
 	}
 	return nil
 }
@@ -110,26 +168,18 @@ func (f *CompactFormatter) FormatItem(w io.Writer, item *api.Item) error {
 		item.LevelString, item.Status, item.TotalOccurrences)
 	fmt.Fprintf(w, "Env: %s | Framework: %s\n", item.Environment, item.Framework)
 	fmt.Fprintf(w, "Last: %s | First: %s\n",
-		formatCompactTime(item.LastOccurrenceTime),
-		formatCompactTime(item.FirstOccurrenceTime))
+		FormatCompactTime(item.LastOccurrenceTime),
+		FormatCompactTime(item.FirstOccurrenceTime))
 	return nil
 }
 
 func (f *CompactFormatter) FormatInstances(w io.Writer, instances []api.Instance) error {
 	for _, inst := range instances {
-		msg := ""
-		if inst.Data.Body.Trace != nil {
-			msg = inst.Data.Body.Trace.Exception.Class + ": " + inst.Data.Body.Trace.Exception.Message
-		} else if inst.Data.Body.Message != nil {
-			msg = inst.Data.Body.Message.Body
-		}
-		if len(msg) > 80 {
-			msg = msg[:77] + "..."
-		}
+		msg := truncate(headlineString(&inst.Data.Body), 80)
 		fmt.Fprintf(w, "%d [%s] %s %s\n",
 			inst.ID,
 			inst.Data.Level,
-			formatCompactTime(inst.Time),
+			FormatCompactTime(inst.Time),
 			msg,
 		)
 	}
@@ -141,30 +191,48 @@ func (f *CompactFormatter) FormatInstance(w io.Writer, instance *api.Instance) e
 	fmt.Fprintf(w, "Level: %s | Env: %s | Time: %s\n",
 		instance.Data.Level,
 		instance.Data.Environment,
-		formatCompactTime(instance.Time))
+		FormatCompactTime(instance.Time))
 
-	if instance.Data.Body.Trace != nil {
-		fmt.Fprintf(w, "Exception: %s: %s\n",
-			instance.Data.Body.Trace.Exception.Class,
-			instance.Data.Body.Trace.Exception.Message)
+	for i, trace := range instance.Data.Body.Traces() {
+		label := "Exception"
+		if i > 0 {
+			label = "Caused by"
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", label, trace.Exception.Class, trace.Exception.Message)
 
-		if len(instance.Data.Body.Trace.Frames) > 0 {
+		if len(trace.Frames) > 0 {
+			// App frames first; vendor frames only when there is no app code
+			appFrames, vendorFrames := separateFrames(trace.Frames)
+			frames := appFrames
+			if len(frames) == 0 {
+				frames = vendorFrames
+			}
 			fmt.Fprintln(w, "Stack:")
-			for i, frame := range instance.Data.Body.Trace.Frames {
-				if i >= 5 {
-					fmt.Fprintf(w, "  ...+%d more\n", len(instance.Data.Body.Trace.Frames)-5)
+			for j, frame := range frames {
+				if j >= 5 {
+					fmt.Fprintf(w, "  ...+%d more\n", len(frames)-5)
 					break
 				}
 				fmt.Fprintf(w, "  %s:%d %s()\n", frame.Filename, frame.Lineno, frame.Method)
 			}
+			if len(appFrames) > 0 && len(vendorFrames) > 0 {
+				fmt.Fprintf(w, "  +%d vendor frames\n", len(vendorFrames))
+			}
 		}
 	}
 
+	if raw := crashReport(&instance.Data.Body); raw != "" {
+		fmt.Fprintf(w, "Crash report:\n%s\n", raw)
+	}
+
 	if instance.Data.Request != nil && instance.Data.Request.URL != "" {
 		fmt.Fprintf(w, "Request: %s %s\n", instance.Data.Request.Method, instance.Data.Request.URL)
 		if browser := getBrowser(&instance.Data); browser != "" {
 			fmt.Fprintf(w, "Browser: %s\n", browser)
 		}
+		if f.Details.Request {
+			writeCompactDetails(w, requestGroups(instance.Data.Request))
+		}
 	}
 
 	if instance.Data.Person != nil && instance.Data.Person.ID != "" {
@@ -175,66 +243,66 @@ func (f *CompactFormatter) FormatInstance(w io.Writer, instance *api.Instance) e
 		fmt.Fprintf(w, "Person: %s\n", person)
 	}
 
+	if f.Details.Custom {
+		writeCompactDetails(w, customGroups(instance.Data.Custom))
+	}
+
 	return nil
 }
 
 func (f *CompactFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
+	if f.MaxTokens > 0 {
+		full := fullLimits().withDetails(f.Details)
+		return writeBudgetedContext(w, f.MaxTokens, full,
+			func(w io.Writer, lim contextLimits) error { return f.writeContext(w, item, instances, lim) },
+			func(lim contextLimits) []string { return omissions(instances, full, lim) },
+			func(omitted []string) string {
+				return fmt.Sprintf("[omitted to fit %d tokens: %s]\n", f.MaxTokens, joinOmitted(omitted))
+			})
+	}
+	return f.writeContext(w, item, instances, compactLimits().withDetails(f.Details))
+}
+
+// compactLimits are the fixed limits used without a token budget
+func compactLimits() contextLimits {
+	return contextLimits{
+		appFrames:    10,
+		vendorFrames: 5,
+		occurrences:  1,
+		code:         true,
+		request:      true,
+		person:       true,
+		analysis:     true,
+	}
+}
+
+func (f *CompactFormatter) writeContext(w io.Writer, item *api.Item, instances []api.Instance, lim contextLimits) error {
 	fmt.Fprintf(w, "# Error #%d: %s\n\n", item.Counter, item.Title)
 	fmt.Fprintf(w, "Level: %s | Status: %s | Occ: %d\n", item.LevelString, item.Status, item.TotalOccurrences)
 	fmt.Fprintf(w, "Env: %s | Framework: %s\n", item.Environment, item.Framework)
 	fmt.Fprintf(w, "First: %s | Last: %s\n\n",
-		item.FirstOccurrenceTime.Format(time.RFC3339),
-		item.LastOccurrenceTime.Format(time.RFC3339))
+		FormatTime(item.FirstOccurrenceTime),
+		FormatTime(item.LastOccurrenceTime))
 
 	if len(instances) > 0 {
 		inst := instances[0]
-		if inst.Data.Body.Trace != nil {
-			fmt.Fprintf(w, "## Exception\n%s: %s\n\n",
-				inst.Data.Body.Trace.Exception.Class,
-				inst.Data.Body.Trace.Exception.Message)
-
-			if len(inst.Data.Body.Trace.Frames) > 0 {
-				appFrames, vendorFrames := separateFrames(inst.Data.Body.Trace.Frames)
-
-				// Show app code first (most useful for debugging)
-				if len(appFrames) > 0 {
-					fmt.Fprintln(w, "## App Code (source of error)")
-					for i, frame := range appFrames {
-						if i >= 10 {
-							fmt.Fprintf(w, "  ...+%d more app frames\n", len(appFrames)-10)
-							break
-						}
-						// Show code context if available
-						if frame.Code != "" {
-							fmt.Fprintf(w, "%s:%d %s()\n  > %s\n", frame.Filename, frame.Lineno, frame.Method, frame.Code)
-						} else {
-							fmt.Fprintf(w, "%s:%d %s()\n", frame.Filename, frame.Lineno, frame.Method)
-						}
-					}
-					fmt.Fprintln(w)
-				} else {
-					fmt.Fprintln(w, "## Stack Trace")
-					fmt.Fprintln(w, "⚠ No app code found in stack trace (only vendor/gem frames)")
-					fmt.Fprintln(w, "  Tip: Configure Rollbar to capture app frames or upload sourcemaps")
-					fmt.Fprintln(w)
-				}
+		for i, trace := range inst.Data.Body.Traces() {
+			heading := "## Exception"
+			if i > 0 {
+				heading = "## Caused By"
+			}
+			fmt.Fprintf(w, "%s\n%s: %s\n\n", heading, trace.Exception.Class, trace.Exception.Message)
 
-				// Show vendor frames (collapsed)
-				if len(vendorFrames) > 0 && len(appFrames) == 0 {
-					fmt.Fprintln(w, "## Vendor Frames (top 5)")
-					for i, frame := range vendorFrames {
-						if i >= 5 {
-							fmt.Fprintf(w, "  ...+%d more vendor frames\n", len(vendorFrames)-5)
-							break
-						}
-						fmt.Fprintf(w, "%s:%d %s()\n", frame.Filename, frame.Lineno, frame.Method)
-					}
-					fmt.Fprintln(w)
-				}
+			if len(trace.Frames) > 0 {
+				writeCompactFrames(w, trace.Frames, lim, i == 0)
 			}
 		}
 
-		if inst.Data.Request != nil && inst.Data.Request.URL != "" {
+		if raw := crashReport(&inst.Data.Body); raw != "" {
+			fmt.Fprintf(w, "## Crash Report\n%s\n\n", raw)
+		}
+
+		if lim.request && inst.Data.Request != nil && inst.Data.Request.URL != "" {
 			fmt.Fprintln(w, "## Request")
 			fmt.Fprintf(w, "%s %s\n", inst.Data.Request.Method, inst.Data.Request.URL)
 			if inst.Data.Request.UserIP != "" {
@@ -243,10 +311,19 @@ func (f *CompactFormatter) FormatContext(w io.Writer, item *api.Item, instances
 			if browser := getBrowser(&inst.Data); browser != "" {
 				fmt.Fprintf(w, "Browser: %s\n", browser)
 			}
+			if lim.requestData {
+				writeCompactDetails(w, requestGroups(inst.Data.Request))
+			}
 			fmt.Fprintln(w)
 		}
 
-		if inst.Data.Person != nil && inst.Data.Person.ID != "" {
+		if groups := customGroups(inst.Data.Custom); lim.customData && len(groups) > 0 {
+			fmt.Fprintln(w, "## Custom Data")
+			writeCompactDetails(w, groups)
+			fmt.Fprintln(w)
+		}
+
+		if lim.person && inst.Data.Person != nil && inst.Data.Person.ID != "" {
 			fmt.Fprintln(w, "## Person")
 			parts := []string{"ID: " + string(inst.Data.Person.ID)}
 			if inst.Data.Person.Email != "" {
@@ -269,11 +346,173 @@ func (f *CompactFormatter) FormatContext(w io.Writer, item *api.Item, instances
 				fmt.Fprintf(w, "Version: %s\n", inst.Data.Server.CodeVersion)
 			}
 		}
+
+		// Sections below are separated by a blank line; the server section
+		// ends without one
+		sep := inst.Data.Server != nil && inst.Data.Server.Host != ""
+
+		if a := analyzeOccurrences(instances); lim.analysis && a != nil {
+			if sep {
+				fmt.Fprintln(w)
+			}
+			writeCompactAnalysis(w, a)
+			sep = true
+		}
+
+		if n := limitCount(lim.occurrences, len(instances)); n > 1 {
+			if sep {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, "## Other Occurrences")
+			for _, occ := range instances[1:n] {
+				parts := []string{FormatTime(occ.Time)}
+				if lim.request && occ.Data.Request != nil && occ.Data.Request.URL != "" {
+					parts = append(parts, occ.Data.Request.Method+" "+occ.Data.Request.URL)
+				}
+				if lim.person && occ.Data.Person != nil && occ.Data.Person.ID != "" {
+					parts = append(parts, "Person: "+string(occ.Data.Person.ID))
+				}
+				if occ.Data.Server != nil && occ.Data.Server.Host != "" {
+					parts = append(parts, "Host: "+occ.Data.Server.Host)
+				}
+				fmt.Fprintf(w, "- %s\n", strings.Join(parts, " | "))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *CompactFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	if f.MaxTokens > 0 {
+		full := fullLimits().withDetails(f.Details)
+		return writeBudgetedContext(w, f.MaxTokens, full,
+			func(w io.Writer, lim contextLimits) error { return f.writeBundle(w, entries, lim) },
+			func(lim contextLimits) []string { return bundleOmissions(entries, full, lim) },
+			func(omitted []string) string {
+				return fmt.Sprintf("[omitted to fit %d tokens: %s]\n", f.MaxTokens, joinOmitted(omitted))
+			})
+	}
+	return f.writeBundle(w, entries, compactLimits().withDetails(f.Details))
+}
+
+func (f *CompactFormatter) writeBundle(w io.Writer, entries []ContextEntry, lim contextLimits) error {
+	s := summarizeBundle(entries)
+	fmt.Fprintf(w, "# Incident: %s, %d occ\n", plural(len(entries), "item"), s.Occurrences)
+	for _, e := range entries {
+		fmt.Fprintf(w, "- #%d [%s] %s (%d occ, last %s)\n", e.Item.Counter, e.Item.LevelString,
+			truncate(e.Item.Title, 80), e.Item.TotalOccurrences, FormatCompactTime(e.Item.LastOccurrenceTime))
+	}
+	if s.Start != nil {
+		fmt.Fprintf(w, "Window: %s - %s\n", FormatTime(*s.Start), FormatTime(*s.End))
+	}
+	for _, field := range []struct {
+		label  string
+		values []sharedValue
+	}{{"Hosts", s.Hosts}, {"Versions", s.CodeVersions}, {"People", s.People}} {
+		if len(field.values) > 0 {
+			fmt.Fprintf(w, "%s: %s\n", field.label, describeShared(field.values))
+		}
+	}
+	fmt.Fprintln(w)
+
+	if len(s.Timeline) > 0 {
+		fmt.Fprintln(w, "## Timeline")
+		if s.TimelineOmitted > 0 {
+			fmt.Fprintf(w, "  ...%d earlier\n", s.TimelineOmitted)
+		}
+		for _, ev := range s.Timeline {
+			fmt.Fprintf(w, "%s #%d%s\n", FormatTime(ev.Time), ev.Item, timelineDetail(ev))
+		}
+		fmt.Fprintln(w)
 	}
 
+	for i, l := range bundleLimits(entries, lim) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := f.writeContext(w, entries[i].Item, entries[i].Instances, l); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// writeCompactAnalysis writes constant fields on one line and each varying
+// field on its own line
+func writeCompactAnalysis(w io.Writer, a *occurrenceAnalysis) {
+	fmt.Fprintf(w, "## Variance (%d occ)\n", a.Occurrences)
+	if len(a.Constant) > 0 {
+		parts := make([]string, len(a.Constant))
+		for i, v := range a.Constant {
+			parts[i] = v.Field + "=" + truncate(v.Top[0].Value, 60)
+		}
+		fmt.Fprintf(w, "Same: %s\n", strings.Join(parts, " | "))
+	}
+	if len(a.Varying) > 0 {
+		fmt.Fprintln(w, "Varies:")
+		for _, v := range a.Varying {
+			fmt.Fprintf(w, "- %s (%s): %s\n", v.Field, distinctLabel(v), describeValues(v))
+		}
+	}
+}
+
+// writeCompactFrames writes app frames first, then vendor frames when there is
+// no app code or lim allows both. Causes get subheadings and no app code hint.
+func writeCompactFrames(w io.Writer, frames []api.Frame, lim contextLimits, primary bool) {
+	appFrames, vendorFrames := separateFrames(frames)
+	appHeading, vendorHeading := "## App Code (source of error)", "## Vendor Frames"
+	if !primary {
+		appHeading, vendorHeading = "### App Code", "### Vendor Frames"
+	}
+
+	// Show app code first (most useful for debugging)
+	if len(appFrames) > 0 {
+		fmt.Fprintln(w, appHeading)
+		shown := limitCount(lim.appFrames, len(appFrames))
+		for i, frame := range appFrames {
+			if i >= shown {
+				fmt.Fprintf(w, "  ...+%d more app frames\n", len(appFrames)-shown)
+				break
+			}
+			fmt.Fprintf(w, "%s:%d %s()\n", frame.Filename, frame.Lineno, frame.Method)
+			// Show code context if available
+			if lim.code {
+				writeFrameCode(w, frame)
+			}
+			writeFrameBlame(w, frame)
+		}
+		fmt.Fprintln(w)
+	} else if primary {
+		fmt.Fprintln(w, "## Stack Trace")
+		fmt.Fprintln(w, "⚠ No app code found in stack trace (only vendor/gem frames)")
+		fmt.Fprintln(w, "  Tip: Configure Rollbar to capture app frames or upload sourcemaps")
+		fmt.Fprintln(w)
+	}
+
+	// Show vendor frames (collapsed)
+	vendorFrames, shared, sharedWith := lim.unseenVendor(vendorFrames)
+	shown := limitCount(lim.vendorFrames, len(vendorFrames))
+	if shown > 0 && (len(appFrames) == 0 || lim.vendorWithApp) {
+		if lim.vendorFrames != unlimited {
+			fmt.Fprintf(w, "%s (top %d)\n", vendorHeading, lim.vendorFrames)
+		} else {
+			fmt.Fprintln(w, vendorHeading)
+		}
+		for i, frame := range vendorFrames {
+			if i >= shown {
+				fmt.Fprintf(w, "  ...+%d more vendor frames\n", len(vendorFrames)-shown)
+				break
+			}
+			fmt.Fprintf(w, "%s:%d %s()\n", frame.Filename, frame.Lineno, frame.Method)
+		}
+		fmt.Fprintln(w)
+	}
+	if shared > 0 && lim.vendorFrames != 0 && (len(appFrames) == 0 || lim.vendorWithApp) {
+		fmt.Fprintf(w, "(%s shared with #%d omitted)\n\n", plural(shared, "vendor frame"), sharedWith)
+	}
+}
+
 func (f *CompactFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
 	fmt.Fprintf(w, "Project: %s (ID: %d) - OK\n", info.Name, info.ID)
 	return nil
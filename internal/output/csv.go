@@ -0,0 +1,230 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// CSVFormatter writes items and occurrences as CSV with RFC 4180 quoting, or
+// as TSV with tabs, newlines and backslashes escaped as \t, \n and \\. Context
+// documents are written as their occurrence rows.
+type CSVFormatter struct {
+	TSV     bool
+	Columns []string // Column names in output order (empty = all columns)
+}
+
+// column is a named field of a CSV row
+type column[T any] struct {
+	name  string
+	value func(v *T) string
+}
+
+// itemColumns are the item columns, in default order
+var itemColumns = []column[api.Item]{
+	{"counter", func(i *api.Item) string { return strconv.Itoa(i.Counter) }},
+	{"id", func(i *api.Item) string { return strconv.FormatInt(int64(i.ID), 10) }},
+	{"title", func(i *api.Item) string { return i.Title }},
+	{"level", func(i *api.Item) string { return i.LevelString }},
+	{"status", func(i *api.Item) string { return i.Status }},
+	{"environment", func(i *api.Item) string { return i.Environment }},
+	{"total_occurrences", func(i *api.Item) string { return strconv.Itoa(i.TotalOccurrences) }},
+	{"unique_occurrences", func(i *api.Item) string { return strconv.Itoa(i.UniqueOccurrences) }},
+	{"first_occurrence", func(i *api.Item) string { return csvTime(i.FirstOccurrenceTime) }},
+	{"last_occurrence", func(i *api.Item) string { return csvTime(i.LastOccurrenceTime) }},
+	{"framework", func(i *api.Item) string { return i.Framework }},
+	{"platform", func(i *api.Item) string { return i.Platform }},
+	{"owners", func(i *api.Item) string { return strings.Join(i.Owners, " ") }},
+}
+
+// instanceColumns are the occurrence columns, in default order
+var instanceColumns = []column[api.Instance]{
+	{"id", func(i *api.Instance) string { return strconv.FormatInt(i.ID, 10) }},
+	{"item_id", func(i *api.Instance) string { return strconv.FormatInt(i.ItemID, 10) }},
+	{"time", func(i *api.Instance) string { return csvTime(i.Time) }},
+	{"level", func(i *api.Instance) string { return i.Data.Level }},
+	{"environment", func(i *api.Instance) string { return i.Data.Environment }},
+	{"class", func(i *api.Instance) string {
+		class, _ := headline(&i.Data.Body)
+		return class
+	}},
+	{"message", func(i *api.Instance) string {
+		_, msg := headline(&i.Data.Body)
+		return msg
+	}},
+	{"frame", func(i *api.Instance) string { return topAppFrame(&i.Data.Body) }},
+	{"host", func(i *api.Instance) string {
+		if i.Data.Server == nil {
+			return ""
+		}
+		return i.Data.Server.Host
+	}},
+	{"code_version", func(i *api.Instance) string { return i.Data.ResolvedCodeVersion() }},
+	{"method", func(i *api.Instance) string {
+		if i.Data.Request == nil {
+			return ""
+		}
+		return i.Data.Request.Method
+	}},
+	{"url", func(i *api.Instance) string {
+		if i.Data.Request == nil {
+			return ""
+		}
+		return i.Data.Request.URL
+	}},
+	{"person", func(i *api.Instance) string {
+		if i.Data.Person == nil {
+			return ""
+		}
+		return string(i.Data.Person.ID)
+	}},
+	{"browser", func(i *api.Instance) string { return getBrowser(&i.Data) }},
+}
+
+var projectColumns = []column[api.ProjectInfo]{
+	{"id", func(p *api.ProjectInfo) string { return strconv.Itoa(p.ID) }},
+	{"name", func(p *api.ProjectInfo) string { return p.Name }},
+}
+
+func columnNames[T any](cols []column[T]) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	return names
+}
+
+// csvTime formats a time in UTC, or empty when unknown
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// topAppFrame returns "file:line" of the first app frame of an occurrence
+func topAppFrame(body *api.Body) string {
+	frame := TopAppFrame(body)
+	if frame == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", frame.Filename, frame.Lineno)
+}
+
+// selectColumns returns the requested columns, or all of them
+func selectColumns[T any](all []column[T], names []string, kind string) ([]column[T], error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+	selected := make([]column[T], 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range all {
+			if c.name == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown %s column %q (available: %s)", kind, name, strings.Join(columnNames(all), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// writeRows writes a header and one row per record
+func writeRows[T any](f *CSVFormatter, w io.Writer, all []column[T], kind string, records []T) error {
+	cols, err := selectColumns(all, f.Columns, kind)
+	if err != nil {
+		return err
+	}
+	rw := newRowWriter(w, f.TSV)
+	rw.write(columnNames(cols))
+	row := make([]string, len(cols))
+	for i := range records {
+		for j, c := range cols {
+			row[j] = c.value(&records[i])
+		}
+		rw.write(row)
+	}
+	return rw.flush()
+}
+
+// rowWriter writes CSV through encoding/csv, or escaped TSV
+type rowWriter struct {
+	csv *csv.Writer
+	w   io.Writer
+	err error
+}
+
+func newRowWriter(w io.Writer, tsv bool) *rowWriter {
+	if tsv {
+		return &rowWriter{w: w}
+	}
+	return &rowWriter{csv: csv.NewWriter(w)}
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func (rw *rowWriter) write(row []string) {
+	if rw.err != nil {
+		return
+	}
+	if rw.csv != nil {
+		rw.err = rw.csv.Write(row)
+		return
+	}
+	escaped := make([]string, len(row))
+	for i, v := range row {
+		escaped[i] = tsvEscaper.Replace(v)
+	}
+	_, rw.err = fmt.Fprintln(rw.w, strings.Join(escaped, "\t"))
+}
+
+func (rw *rowWriter) flush() error {
+	if rw.csv != nil && rw.err == nil {
+		rw.csv.Flush()
+		rw.err = rw.csv.Error()
+	}
+	return rw.err
+}
+
+func (f *CSVFormatter) FormatItems(w io.Writer, items []api.Item) error {
+	return writeRows(f, w, itemColumns, "item", items)
+}
+
+func (f *CSVFormatter) FormatItem(w io.Writer, item *api.Item) error {
+	return writeRows(f, w, itemColumns, "item", []api.Item{*item})
+}
+
+func (f *CSVFormatter) FormatInstances(w io.Writer, instances []api.Instance) error {
+	return writeRows(f, w, instanceColumns, "occurrence", instances)
+}
+
+func (f *CSVFormatter) FormatInstance(w io.Writer, instance *api.Instance) error {
+	return writeRows(f, w, instanceColumns, "occurrence", []api.Instance{*instance})
+}
+
+func (f *CSVFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
+	return f.FormatInstances(w, instances)
+}
+
+// FormatBundle writes the occurrences of every item under one header; the
+// item_id column tells them apart
+func (f *CSVFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	var instances []api.Instance
+	for _, e := range entries {
+		instances = append(instances, e.Instances...)
+	}
+	return f.FormatInstances(w, instances)
+}
+
+func (f *CSVFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
+	return writeRows(f, w, projectColumns, "project", []api.ProjectInfo{*info})
+}
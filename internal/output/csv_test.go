@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func csvItems() []api.Item {
+	items := sampleItems()
+	items[0].Title = `Error: "quoted", with comma`
+	items[0].LastOccurrenceTime = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	second := items[0]
+	second.Counter, second.Title = 124, "line one\nline\ttwo"
+	return append(items, second)
+}
+
+func TestCSVFormatter(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *CSVFormatter
+		want string
+	}{
+		{"csv quoting", &CSVFormatter{Columns: []string{"counter", "title", "last_occurrence"}},
+			"counter,title,last_occurrence\n" +
+				"123,\"Error: \"\"quoted\"\", with comma\",2024-01-15T10:00:00Z\n" +
+				"124,\"line one\nline\ttwo\",2024-01-15T10:00:00Z\n"},
+		{"tsv escaping", &CSVFormatter{TSV: true, Columns: []string{"title", "counter"}},
+			"title\tcounter\n" +
+				"Error: \"quoted\", with comma\t123\n" +
+				"line one\\nline\\ttwo\t124\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.f.FormatItems(&buf, csvItems()); err != nil {
+				t.Fatalf("FormatItems failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVFormatterDefaultColumns(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&CSVFormatter{}).FormatInstances(&buf, sampleInstances()); err != nil {
+		t.Fatalf("FormatInstances failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and one row, got %q", buf.String())
+	}
+	if lines[0] != strings.Join(columnNames(instanceColumns), ",") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "999,0,") || !strings.Contains(lines[1], ",TestError,Something went wrong,") {
+		t.Errorf("unexpected row %q", lines[1])
+	}
+}
+
+func TestCSVFormatterUnknownColumn(t *testing.T) {
+	err := (&CSVFormatter{Columns: []string{"counter", "nope"}}).FormatItems(&bytes.Buffer{}, csvItems())
+	if err == nil || !strings.Contains(err.Error(), `unknown item column "nope"`) {
+		t.Errorf("expected an unknown column error, got %v", err)
+	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	f := &NDJSONFormatter{Fields: []string{"counter"}}
+	if err := f.FormatItems(&buf, csvItems()); err != nil {
+		t.Fatalf("FormatItems failed: %v", err)
+	}
+	if got, want := buf.String(), "{\"counter\":123}\n{\"counter\":124}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := (&NDJSONFormatter{}).FormatBundle(&buf, bundleEntries()); err != nil {
+		t.Fatalf("FormatBundle failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one record per item, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var record ContextDocument
+		if err := json.Unmarshal([]byte(line), &record); err != nil || record.Item.Item == nil {
+			t.Errorf("invalid record %q: %v", line[:40], err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// Details selects optional occurrence sections that are off by default
+type Details struct {
+	Request bool // Headers, params, GET and POST data, body and query string
+	Custom  bool // The occurrence's custom data
+}
+
+// WithDetails enables optional sections on the formatters that render them.
+// JSON output always includes all data.
+func WithDetails(f Formatter, d Details) Formatter {
+	switch f := f.(type) {
+	case *TableFormatter:
+		f.Details = d
+	case *CompactFormatter:
+		f.Details = d
+	case *MarkdownFormatter:
+		f.Details = d
+	}
+	return f
+}
+
+// detailGroup is a titled set of key/value pairs or a block of text
+type detailGroup struct {
+	Title string
+	Pairs [][2]string
+	Text  string
+}
+
+// requestGroups lists the request data that is present
+func requestGroups(req *api.Request) []detailGroup {
+	if req == nil {
+		return nil
+	}
+	var groups []detailGroup
+	if len(req.Headers) > 0 {
+		headers := make(map[string]interface{}, len(req.Headers))
+		for k, v := range req.Headers {
+			headers[k] = v
+		}
+		groups = append(groups, detailGroup{Title: "Headers", Pairs: sortedPairs(headers)})
+	}
+	for _, g := range []struct {
+		title  string
+		values map[string]interface{}
+	}{{"GET", req.GET}, {"POST", req.POST}, {"Params", req.Params}} {
+		if len(g.values) > 0 {
+			groups = append(groups, detailGroup{Title: g.title, Pairs: sortedPairs(g.values)})
+		}
+	}
+	if req.QueryString != "" {
+		groups = append(groups, detailGroup{Title: "Query String", Text: req.QueryString})
+	}
+	if req.Body != "" {
+		groups = append(groups, detailGroup{Title: "Body", Text: req.Body})
+	}
+	return groups
+}
+
+// customGroups lists custom data as a single group
+func customGroups(custom map[string]interface{}) []detailGroup {
+	if len(custom) == 0 {
+		return nil
+	}
+	return []detailGroup{{Title: "Custom", Pairs: sortedPairs(custom)}}
+}
+
+func sortedPairs(m map[string]interface{}) [][2]string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([][2]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = [2]string{k, displayValue(m[k])}
+	}
+	return pairs
+}
+
+// writeMarkdownDetails writes groups under a heading, one subsection each
+func writeMarkdownDetails(w io.Writer, heading string, groups []detailGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "## %s\n", heading)
+	for _, g := range groups {
+		if len(groups) > 1 || g.Text != "" {
+			fmt.Fprintf(w, "### %s\n", g.Title)
+		}
+		for _, p := range g.Pairs {
+			fmt.Fprintf(w, "- **%s:** %s\n", p[0], p[1])
+		}
+		if g.Text != "" {
+			fmt.Fprintf(w, "```\n%s\n```\n", g.Text)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// writeCompactDetails writes each group on one line
+func writeCompactDetails(w io.Writer, groups []detailGroup) {
+	for _, g := range groups {
+		if g.Text != "" {
+			fmt.Fprintf(w, "%s: %s\n", g.Title, g.Text)
+			continue
+		}
+		parts := make([]string, len(g.Pairs))
+		for i, p := range g.Pairs {
+			parts[i] = p[0] + "=" + p[1]
+		}
+		fmt.Fprintf(w, "%s: %s\n", g.Title, strings.Join(parts, " | "))
+	}
+}
+
+// writeDetails writes groups under a bold heading with indented pairs
+func (f *TableFormatter) writeDetails(w io.Writer, heading string, groups []detailGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s%s\n", colorBold, heading, colorReset)
+	for _, g := range groups {
+		indent := "  "
+		if len(groups) > 1 || g.Text != "" {
+			fmt.Fprintf(w, "  %s\n", f.color(colorCyan, g.Title+":"))
+			indent = "    "
+		}
+		for _, p := range g.Pairs {
+			fmt.Fprintf(w, "%s%s: %s\n", indent, p[0], p[1])
+		}
+		if g.Text != "" {
+			for _, line := range strings.Split(g.Text, "\n") {
+				fmt.Fprintf(w, "%s%s\n", indent, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func detailedInstance() api.Instance {
+	inst := varyingInstances()[0]
+	inst.Data.Request.Headers = map[string]string{"Accept": "text/html", "Cookie": "[REDACTED]"}
+	inst.Data.Request.Body = `{"coupon":"SPRING"}`
+	return inst
+}
+
+func TestFormatInstanceDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Formatter
+		want []string
+	}{
+		{"table", &TableFormatter{}, []string{"Request Data", "    Accept: text/html", "    quantity: 1", `    {"coupon":"SPRING"}`, "Custom Data", "  tenant: acme"}},
+		{"compact", &CompactFormatter{}, []string{"Headers: Accept=text/html | Cookie=[REDACTED]", "POST: quantity=1", `Body: {"coupon":"SPRING"}`, "Custom: tenant=acme"}},
+		{"markdown", &MarkdownFormatter{}, []string{"## Request Data", "### Headers", "- **Cookie:** [REDACTED]", "### Body\n```\n{\"coupon\":\"SPRING\"}\n```", "## Custom Data\n- **tenant:** acme"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := detailedInstance()
+
+			var plain bytes.Buffer
+			if err := tt.f.FormatInstance(&plain, &inst); err != nil {
+				t.Fatalf("FormatInstance failed: %v", err)
+			}
+			if strings.Contains(plain.String(), "text/html") || strings.Contains(plain.String(), "acme") {
+				t.Error("expected request and custom data hidden by default")
+			}
+
+			var buf bytes.Buffer
+			f := WithDetails(tt.f, Details{Request: true, Custom: true})
+			if err := f.FormatInstance(&buf, &inst); err != nil {
+				t.Fatalf("FormatInstance failed: %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("expected %q in output:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestContextDetailsBudget(t *testing.T) {
+	instances := largeInstances(1)
+	headers := map[string]string{}
+	for _, name := range []string{"Accept", "Accept-Language", "Referer", "X-Request-Id", "X-Forwarded-For"} {
+		headers[name] = strings.Repeat("v", 200)
+	}
+	instances[0].Data.Request.Headers = headers
+	instances[0].Data.Custom = map[string]interface{}{"tenant": strings.Repeat("acme", 100)}
+
+	f := &MarkdownFormatter{Details: Details{Request: true, Custom: true}}
+	var full bytes.Buffer
+	if err := f.FormatContext(&full, sampleItem(), instances); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+	if !strings.Contains(full.String(), "## Request Data") || !strings.Contains(full.String(), "## Custom Data") {
+		t.Fatal("expected request and custom data without a budget")
+	}
+
+	// Budget for the context without vendor frames and custom data, plus the trailer
+	lim := fullLimits().withDetails(f.Details)
+	lim.vendorFrames = 0
+	lim.customData = false
+	var trimmed bytes.Buffer
+	if err := f.writeContext(&trimmed, sampleItem(), instances, lim); err != nil {
+		t.Fatalf("writeContext failed: %v", err)
+	}
+	f.MaxTokens = EstimateTokens(trimmed.String()) + 30
+
+	var buf bytes.Buffer
+	if err := f.FormatContext(&buf, sampleItem(), instances); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "## Custom Data") || !strings.Contains(out, "custom data") {
+		t.Errorf("expected custom data to be dropped and reported:\n%s", out)
+	}
+	if !strings.Contains(out, "## Request Data") {
+		t.Error("expected request data to be kept")
+	}
+}
@@ -29,8 +29,28 @@ const (
 	FormatJSON     Format = "json"
 	FormatCompact  Format = "compact"
 	FormatMarkdown Format = "markdown"
+	FormatCSV      Format = "csv"
+	FormatTSV      Format = "tsv"
+	FormatNDJSON   Format = "ndjson"
+	FormatTemplate Format = "template" // Used as "template=TEXT"
 )
 
+// Formats are the output formats besides template=TEXT
+var Formats = []Format{FormatTable, FormatJSON, FormatNDJSON, FormatCSV, FormatTSV, FormatCompact, FormatMarkdown}
+
+// Valid reports whether f is one of Formats or a template
+func (f Format) Valid() bool {
+	if _, ok := TemplateText(f); ok {
+		return true
+	}
+	for _, format := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 // Formatter is the interface for output formatters
 type Formatter interface {
 	FormatItems(w io.Writer, items []api.Item) error
@@ -43,6 +63,9 @@ type Formatter interface {
 
 // New creates a new formatter based on the format type
 func New(format Format, color bool) Formatter {
+	if text, ok := TemplateText(format); ok {
+		return &TemplateFormatter{Text: text, Color: color}
+	}
 	switch format {
 	case FormatJSON:
 		return &JSONFormatter{}
@@ -50,6 +73,12 @@ func New(format Format, color bool) Formatter {
 		return &CompactFormatter{}
 	case FormatMarkdown:
 		return &MarkdownFormatter{}
+	case FormatCSV:
+		return &CSVFormatter{}
+	case FormatTSV:
+		return &CSVFormatter{TSV: true}
+	case FormatNDJSON:
+		return &NDJSONFormatter{}
 	case FormatTable:
 		fallthrough
 	default:
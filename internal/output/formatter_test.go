@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/jq"
 )
 
 func sampleItems() []api.Item {
@@ -83,9 +84,35 @@ func TestJSONFormatter(t *testing.T) {
 			t.Fatalf("FormatItems failed: %v", err)
 		}
 
-		var items []api.Item
-		if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
-			t.Errorf("output is not valid JSON: %v", err)
+		var doc struct {
+			Schema string                   `json:"schema"`
+			Items  []map[string]interface{} `json:"items"`
+			Page   int                      `json:"page"`
+			More   *bool                    `json:"has_more"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("output is not valid JSON: %v", err)
+		}
+		if doc.Schema != SchemaVersion || len(doc.Items) != 1 || doc.Page != 1 || doc.More == nil {
+			t.Errorf("unexpected envelope %s", buf.String())
+		}
+		item := doc.Items[0]
+		if item["level"] != "error" {
+			t.Errorf("expected the level name, got %v", item["level"])
+		}
+		if ts, _ := item["last_occurrence_time"].(string); !strings.HasSuffix(ts, "Z") {
+			t.Errorf("expected an ISO 8601 UTC time, got %v", item["last_occurrence_time"])
+		}
+	})
+
+	t.Run("FormatInstances with page", func(t *testing.T) {
+		buf.Reset()
+		paged := WithPage(&JSONFormatter{}, Page{Number: 3, HasMore: true})
+		if err := paged.FormatInstances(&buf, sampleInstances()); err != nil {
+			t.Fatalf("FormatInstances failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), `"page":3,"has_more":true}`) || !strings.Contains(buf.String(), `"occurrences":[{"id":999`) {
+			t.Errorf("unexpected output %s", buf.String())
 		}
 	})
 
@@ -96,13 +123,94 @@ func TestJSONFormatter(t *testing.T) {
 			t.Fatalf("FormatItem failed: %v", err)
 		}
 
-		var item api.Item
-		if err := json.Unmarshal(buf.Bytes(), &item); err != nil {
+		var doc struct {
+			Item api.Item `json:"item"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
 			t.Errorf("output is not valid JSON: %v", err)
 		}
+		if doc.Item.Counter != 123 || doc.Item.Level.Int() != 40 {
+			t.Errorf("unexpected item %+v", doc.Item)
+		}
 	})
 }
 
+func TestJSONFormatterOptions(t *testing.T) {
+	query, err := jq.Parse(`.items[] | "\(.counter) \(.title)"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		f    *JSONFormatter
+		want string
+	}{
+		{"fields", &JSONFormatter{Fields: []string{"counter", "title", "missing"}},
+			`{"schema":"rollbar-cli/v1","items":[{"counter":123,"missing":null,"title":"Test Error"}],"page":1,"has_more":false}` + "\n"},
+		{"indent", &JSONFormatter{Indent: 1, Fields: []string{"counter"}},
+			"{\n \"schema\": \"rollbar-cli/v1\",\n \"items\": [\n  {\n   \"counter\": 123\n  }\n ],\n \"page\": 1,\n \"has_more\": false\n}\n"},
+		{"raw query strings", &JSONFormatter{Query: query}, "123 Test Error\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.f.FormatItems(&buf, sampleItems()); err != nil {
+				t.Fatalf("FormatItems failed: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONFormatterIndent(t *testing.T) {
+	tests := []struct {
+		indent int
+		want   string
+	}{
+		{0, "{\n  \"schema\": \"rollbar-cli/v1\",\n  \"item\""},
+		{CompactJSON, `{"schema":"rollbar-cli/v1","item"`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		f := &JSONFormatter{Indent: tt.indent}
+		if err := f.FormatContext(&buf, sampleItem(), sampleInstances()); err != nil {
+			t.Fatalf("FormatContext failed: %v", err)
+		}
+		if !strings.HasPrefix(buf.String(), tt.want) {
+			t.Errorf("indent %d: expected output to start with %q, got %q", tt.indent, tt.want, buf.String()[:20])
+		}
+	}
+}
+
+func TestJSONFormatterContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	f := &JSONFormatter{Indent: CompactJSON, Fields: []string{"item.counter", "item.level"}}
+	if err := f.FormatContext(&buf, sampleItem(), sampleInstances()); err != nil {
+		t.Fatalf("FormatContext failed: %v", err)
+	}
+	want := `{"schema":"rollbar-cli/v1","item":{"counter":123,"level":"error"}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	value := map[string]interface{}{
+		"counter": 1,
+		"data":    map[string]interface{}{"server": map[string]interface{}{"host": "web-1", "pid": 4}},
+	}
+	got := selectFields(value, []string{"data.server.host", "data.request.url"})
+	want := `{"data":{"request":{"url":null},"server":{"host":"web-1"}}}`
+	if b, _ := json.Marshal(got); string(b) != want {
+		t.Errorf("selectFields() = %s, want %s", b, want)
+	}
+}
+
 func TestTableFormatter(t *testing.T) {
 	f := &TableFormatter{Color: false}
 	var buf bytes.Buffer
@@ -294,6 +402,10 @@ func TestNewFormatter(t *testing.T) {
 		{FormatTable, "*output.TableFormatter"},
 		{FormatCompact, "*output.CompactFormatter"},
 		{FormatMarkdown, "*output.MarkdownFormatter"},
+		{FormatCSV, "*output.CSVFormatter"},
+		{FormatTSV, "*output.CSVFormatter"},
+		{FormatNDJSON, "*output.NDJSONFormatter"},
+		{"template={{.Title}}", "*output.TemplateFormatter"},
 		{"unknown", "*output.TableFormatter"}, // defaults to table
 	}
 
@@ -305,3 +417,26 @@ func TestNewFormatter(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteFrameCode(t *testing.T) {
+	var buf bytes.Buffer
+	writeFrameCode(&buf, api.Frame{
+		Lineno:  10,
+		Code:    "user.name",
+		Context: api.FrameContext{Pre: []string{"def show", "  user = find"}, Post: []string{"end"}},
+	})
+
+	want := "     8 | def show\n" +
+		"     9 |   user = find\n" +
+		"  > 10 | user.name\n" +
+		"    11 | end\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeFrameCode(&buf, api.Frame{Lineno: 3, Code: "raise"})
+	if buf.String() != "  > raise\n" {
+		t.Errorf("expected plain code line, got %q", buf.String())
+	}
+}
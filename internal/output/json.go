@@ -1,44 +1,272 @@
 package output
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/jq"
 )
 
-// JSONFormatter outputs data as JSON
-type JSONFormatter struct{}
+// CompactJSON is the JSONFormatter indent that compacts every document
+const CompactJSON = -1
+
+// JSONFormatter outputs data as JSON documents that carry the schema
+// version, e.g. {"schema":"rollbar-cli/v1","items":[...],"page":1,"has_more":false}
+type JSONFormatter struct {
+	// Indent is the number of spaces to indent by. Zero keeps the defaults:
+	// compact lists and items, indented context and reports.
+	Indent int
+	// Fields keeps only these dotted paths, e.g. "counter" or
+	// "data.server.host", of each record of a document
+	Fields []string
+	// Query filters the output with a jq program. String results are
+	// written raw, one per line, like jq -r.
+	Query *jq.Query
+	// Page is the page a list came from
+	Page Page
+}
+
+// write encodes doc, selecting the fields of its records (the value under the
+// records key, or doc itself when empty) and running the query when set.
+// pretty is whether doc is indented by default.
+func (f *JSONFormatter) write(w io.Writer, doc interface{}, records string, pretty bool) error {
+	indent := ""
+	switch {
+	case f.Indent > 0:
+		indent = strings.Repeat(" ", f.Indent)
+	case f.Indent == 0 && pretty:
+		indent = "  "
+	}
+
+	if len(f.Fields) == 0 && f.Query == nil {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", indent)
+		return enc.Encode(doc)
+	}
+
+	value, keys, err := toJSONValue(doc)
+	if err != nil {
+		return err
+	}
+	if len(f.Fields) > 0 {
+		value = selectRecordFields(value, records, f.Fields)
+	}
+	if f.Query == nil {
+		out, err := encodeObject(value, keys, indent)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, out)
+		return err
+	}
+
+	results, err := f.Query.Run(value)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		s, ok := r.(string)
+		if !ok {
+			if s, err = jq.Encode(r, indent); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectRecordFields applies selectFields to the records of a document,
+// keeping its schema
+func selectRecordFields(doc interface{}, records string, fields []string) interface{} {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return selectFields(doc, fields)
+	}
+	if records != "" {
+		obj[records] = selectFields(obj[records], fields)
+		return obj
+	}
+	selected := selectFields(obj, fields).(map[string]interface{})
+	if schema, ok := obj["schema"]; ok {
+		selected["schema"] = schema
+	}
+	return selected
+}
+
+// toJSONValue converts v to its generic JSON form, keeping numbers exact. For
+// an object it also returns the order of its keys.
+func toJSONValue(v interface{}) (interface{}, []string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if b[0] != '{' {
+		var value interface{}
+		err = dec.Decode(&value)
+		return value, nil, err
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+	obj := map[string]interface{}{}
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, _ := t.(string)
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		obj[key] = value
+		keys = append(keys, key)
+	}
+	return obj, keys, nil
+}
+
+// encodeObject encodes v, writing the keys of an object in the given order
+// and any others after them, sorted
+func encodeObject(v interface{}, keys []string, indent string) (string, error) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return jq.Encode(v, indent)
+	}
+
+	var order, rest []string
+	for _, k := range keys {
+		if _, ok := obj[k]; ok {
+			order = append(order, k)
+		}
+	}
+	for k := range obj {
+		if !slices.Contains(order, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	order = append(order, rest...)
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range order {
+		key, _ := jq.Encode(k, "")
+		value, err := jq.Encode(obj[k], "")
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(key + ":" + value)
+	}
+	b.WriteByte('}')
+	if indent == "" {
+		return b.String(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b.Bytes(), "", indent); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// selectFields keeps the given dotted paths of each element of an array, or
+// of an object. Missing paths are null.
+func selectFields(v interface{}, fields []string) interface{} {
+	if arr, ok := v.([]interface{}); ok {
+		out := make([]interface{}, len(arr))
+		for i, elem := range arr {
+			out[i] = selectFields(elem, fields)
+		}
+		return out
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+
+	out := map[string]interface{}{}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		var value interface{} = obj
+		for _, key := range path {
+			m, _ := value.(map[string]interface{})
+			value = m[key]
+		}
+
+		dst := out
+		for _, key := range path[:len(path)-1] {
+			next, ok := dst[key].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				dst[key] = next
+			}
+			dst = next
+		}
+		dst[path[len(path)-1]] = value
+	}
+	return out
+}
+
+// FormatValue writes any other result, such as a command's report, as
+// {"schema": ..., key: v}, indented by default
+func (f *JSONFormatter) FormatValue(w io.Writer, key string, v interface{}) error {
+	return f.write(w, map[string]interface{}{"schema": SchemaVersion, key: v}, key, true)
+}
+
+// page returns the page of a list, the first unless set with WithPage
+func (f *JSONFormatter) page() Page {
+	if f.Page.Number == 0 {
+		return Page{Number: 1, HasMore: f.Page.HasMore}
+	}
+	return f.Page
+}
 
 func (f *JSONFormatter) FormatItems(w io.Writer, items []api.Item) error {
-	return json.NewEncoder(w).Encode(items)
+	doc := ItemsDocument{Schema: SchemaVersion, Items: newItemRecords(items), Page: f.page()}
+	return f.write(w, doc, "items", false)
 }
 
 func (f *JSONFormatter) FormatItem(w io.Writer, item *api.Item) error {
-	return json.NewEncoder(w).Encode(item)
+	return f.write(w, ItemDocument{Schema: SchemaVersion, Item: newItemRecord(item)}, "item", false)
 }
 
 func (f *JSONFormatter) FormatInstances(w io.Writer, instances []api.Instance) error {
-	return json.NewEncoder(w).Encode(instances)
+	doc := OccurrencesDocument{Schema: SchemaVersion, Occurrences: newInstanceRecords(instances), Page: f.page()}
+	return f.write(w, doc, "occurrences", false)
 }
 
 func (f *JSONFormatter) FormatInstance(w io.Writer, instance *api.Instance) error {
-	return json.NewEncoder(w).Encode(instance)
+	doc := OccurrenceDocument{Schema: SchemaVersion, Occurrence: newInstanceRecord(instance)}
+	return f.write(w, doc, "occurrence", false)
 }
 
 func (f *JSONFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
-	data := struct {
-		Item      *api.Item      `json:"item"`
-		Instances []api.Instance `json:"instances"`
-	}{
-		Item:      item,
-		Instances: instances,
-	}
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(data)
+	doc := newContextData(item, instances)
+	doc.Schema = SchemaVersion
+	return f.write(w, doc, "", true)
+}
+
+func (f *JSONFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	doc := newBundleData(entries)
+	doc.Schema = SchemaVersion
+	return f.write(w, doc, "items", true)
 }
 
 func (f *JSONFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
-	return json.NewEncoder(w).Encode(info)
+	return f.write(w, ProjectDocument{Schema: SchemaVersion, Project: info}, "project", false)
 }
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/term"
+)
+
+// tableColumn is a column of a width-aware table
+type tableColumn struct {
+	header   string
+	right    bool // Right-aligned
+	max      int  // Widest the column gets unless wide (0 = as wide as its values)
+	flex     int  // Narrowest width of the column that shrinks to fit the terminal (0 = fixed)
+	priority int  // Columns are dropped from the highest priority up; 0 is never dropped
+}
+
+// tableLayout fits columns into a terminal
+type tableLayout struct {
+	width int  // Terminal width in cells (0 = don't fit)
+	wide  bool // Show whole values and ignore the terminal width
+}
+
+// columnWidths returns the width of each column, with -1 for dropped ones.
+// Values are measured in display cells, so wide characters and colors line up.
+func (l tableLayout) columnWidths(cols []tableColumn, rows [][]string) []int {
+	widths := make([]int, len(cols))
+	for i, c := range cols {
+		widths[i] = term.StringWidth(c.header)
+		for _, row := range rows {
+			if w := term.StringWidth(row[i]); w > widths[i] {
+				widths[i] = w
+			}
+		}
+		if !l.wide && c.max > 0 && widths[i] > c.max {
+			widths[i] = max(c.max, term.StringWidth(c.header))
+		}
+	}
+	if l.wide || l.width <= 0 {
+		return widths
+	}
+
+	// Drop the least important columns until the fixed ones leave the
+	// flexible column at least its minimum, then give it what is left
+	for {
+		used, flex := 0, -1
+		for i, c := range cols {
+			if widths[i] < 0 {
+				continue
+			}
+			if used > 0 {
+				used++ // Gap
+			}
+			if c.flex > 0 && flex < 0 {
+				flex = i
+				continue
+			}
+			used += widths[i]
+		}
+		if flex < 0 {
+			if used <= l.width || !dropColumn(cols, widths) {
+				return widths
+			}
+			continue
+		}
+		avail := l.width - used
+		if avail >= cols[flex].flex || !dropColumn(cols, widths) {
+			widths[flex] = min(widths[flex], max(avail, cols[flex].flex))
+			return widths
+		}
+	}
+}
+
+// dropColumn hides the visible column with the highest priority, reporting
+// whether there was one
+func dropColumn(cols []tableColumn, widths []int) bool {
+	drop := -1
+	for i, c := range cols {
+		if widths[i] >= 0 && c.priority > 0 && (drop < 0 || c.priority >= cols[drop].priority) {
+			drop = i
+		}
+	}
+	if drop < 0 {
+		return false
+	}
+	widths[drop] = -1
+	return true
+}
+
+// writeTable writes a header, a rule and the rows, truncating values to their
+// column and leaving out dropped columns
+func (l tableLayout) writeTable(w io.Writer, cols []tableColumn, rows [][]string) {
+	widths := l.columnWidths(cols, rows)
+	line := func(cells []string) string {
+		var parts []string
+		for i, c := range cols {
+			if widths[i] < 0 {
+				continue
+			}
+			cell := term.Truncate(cells[i], widths[i], "...")
+			if c.right {
+				cell = term.PadLeft(cell, widths[i])
+			} else {
+				cell = term.Pad(cell, widths[i])
+			}
+			parts = append(parts, cell)
+		}
+		return strings.TrimRight(strings.Join(parts, " "), " ")
+	}
+
+	headers := make([]string, len(cols))
+	total := -1
+	for i, c := range cols {
+		headers[i] = c.header
+		if widths[i] >= 0 {
+			total += widths[i] + 1
+		}
+	}
+	fmt.Fprintln(w, line(headers))
+	fmt.Fprintln(w, strings.Repeat("-", total))
+	for _, row := range rows {
+		fmt.Fprintln(w, line(row))
+	}
+}
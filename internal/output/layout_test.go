@@ -0,0 +1,117 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/term"
+)
+
+func TestTableLayoutWidths(t *testing.T) {
+	cols := []tableColumn{
+		{header: "#"},
+		{header: "TITLE", max: 50, flex: 20},
+		{header: "LEVEL", priority: 1},
+		{header: "STATUS", priority: 3},
+		{header: "LAST SEEN", priority: 2},
+	}
+	rows := [][]string{{"12345", strings.Repeat("x", 70), "critical", "resolved", "3 weeks ago"}}
+
+	tests := []struct {
+		name   string
+		layout tableLayout
+		want   []int
+	}{
+		{"unfitted", tableLayout{}, []int{5, 50, 8, 8, 11}},
+		{"wide", tableLayout{width: 40, wide: true}, []int{5, 70, 8, 8, 11}},
+		{"roomy terminal", tableLayout{width: 200}, []int{5, 50, 8, 8, 11}},
+		{"title shrinks first", tableLayout{width: 70}, []int{5, 34, 8, 8, 11}},
+		{"status dropped", tableLayout{width: 50}, []int{5, 23, 8, -1, 11}},
+		{"only essentials", tableLayout{width: 36}, []int{5, 21, 8, -1, -1}},
+		{"too narrow for the minimum", tableLayout{width: 10}, []int{5, 20, -1, -1, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.layout.columnWidths(cols, rows)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("widths = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTableFormatterFitsTerminal(t *testing.T) {
+	items := []api.Item{
+		{Counter: 1, Title: "データベース接続がタイムアウトしました。再試行してください。", LevelString: "error", Status: "active", TotalOccurrences: 5},
+		{Counter: 22, Title: "Ünïcödé failure in café ☕ handler", LevelString: "critical", Status: "active", TotalOccurrences: 120},
+	}
+
+	var buf bytes.Buffer
+	f := &TableFormatter{Color: true, Width: 45}
+	if err := f.FormatItems(&buf, items); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if strings.Contains(lines[0], "STATUS") || strings.Contains(lines[0], "LAST SEEN") {
+		t.Errorf("expected STATUS and LAST SEEN to be dropped at 45 columns: %q", lines[0])
+	}
+	levelCol := strings.Index(lines[0], "LEVEL")
+	for i, line := range lines {
+		if w := term.StringWidth(line); w > 45 {
+			t.Errorf("line is %d cells wide: %q", w, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line has a broken character: %q", line)
+		}
+		// Colored, wide-character rows line up with the header
+		if i < 2 {
+			continue
+		}
+		plain := stripANSI(line)
+		level := items[i-2].LevelString
+		if got := term.StringWidth(plain[:strings.Index(plain, " "+level)+1]); got != levelCol {
+			t.Errorf("LEVEL not aligned in %q: at %d, want %d", plain, got, levelCol)
+		}
+	}
+
+	buf.Reset()
+	f = &TableFormatter{Width: 45, Wide: true}
+	if err := f.FormatItems(&buf, items); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), items[0].Title) || !strings.Contains(buf.String(), "STATUS") {
+		t.Errorf("expected whole titles and every column with Wide:\n%s", buf.String())
+	}
+}
+
+func TestMarkdownFormatterTruncatesByRune(t *testing.T) {
+	items := []api.Item{{Counter: 1, Title: strings.Repeat("é", 80)}}
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{}).FormatItems(&buf, items); err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.ValidString(buf.String()) || !strings.Contains(buf.String(), strings.Repeat("é", 57)+"...") {
+		t.Errorf("unexpected title:\n%s", buf.String())
+	}
+}
+
+func stripANSI(s string) string {
+	var b strings.Builder
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			inEscape = !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'))
+		case r == '\033':
+			inEscape = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -1,35 +1,49 @@
 package output
 
 import (
+	"bytes"
 	"fmt"
 	"io"
-	"time"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
 )
 
 // MarkdownFormatter outputs data as markdown for documentation/AI context
-type MarkdownFormatter struct{}
+type MarkdownFormatter struct {
+	MaxTokens int // Token budget for FormatContext (0 = no budget)
+	Details   Details
+	Wide      bool // Don't shorten titles in item tables
+}
 
 func (f *MarkdownFormatter) FormatItems(w io.Writer, items []api.Item) error {
 	fmt.Fprintln(w, "# Rollbar Items")
 	fmt.Fprintln(w)
-	fmt.Fprintln(w, "| # | Title | Level | Status | Occurrences | Last Seen |")
-	fmt.Fprintln(w, "|---|-------|-------|--------|-------------|-----------|")
+	showOwners := hasOwners(items)
+	if showOwners {
+		fmt.Fprintln(w, "| # | Title | Level | Status | Occurrences | Last Seen | Owner |")
+		fmt.Fprintln(w, "|---|-------|-------|--------|-------------|-----------|-------|")
+	} else {
+		fmt.Fprintln(w, "| # | Title | Level | Status | Occurrences | Last Seen |")
+		fmt.Fprintln(w, "|---|-------|-------|--------|-------------|-----------|")
+	}
 
 	for _, item := range items {
 		title := item.Title
-		if len(title) > 60 {
-			title = title[:57] + "..."
+		if !f.Wide {
+			title = truncate(title, 60)
 		}
-		fmt.Fprintf(w, "| %d | %s | %s | %s | %d | %s |\n",
+		fmt.Fprintf(w, "| %d | %s | %s | %s | %d | %s |",
 			item.Counter,
 			title,
 			item.LevelString,
 			item.Status,
 			item.TotalOccurrences,
-			formatCompactTime(item.LastOccurrenceTime),
+			FormatCompactTime(item.LastOccurrenceTime),
 		)
+		if showOwners {
+			fmt.Fprintf(w, " %s |", ownersString(item.Owners))
+		}
+		fmt.Fprintln(w)
 	}
 
 	return nil
@@ -46,8 +60,8 @@ func (f *MarkdownFormatter) FormatItem(w io.Writer, item *api.Item) error {
 	fmt.Fprintf(w, "- **Framework:** %s\n", item.Framework)
 	fmt.Fprintf(w, "- **Platform:** %s\n", item.Platform)
 	fmt.Fprintf(w, "- **Total Occurrences:** %d\n", item.TotalOccurrences)
-	fmt.Fprintf(w, "- **First Seen:** %s\n", item.FirstOccurrenceTime.Format(time.RFC3339))
-	fmt.Fprintf(w, "- **Last Seen:** %s\n", item.LastOccurrenceTime.Format(time.RFC3339))
+	fmt.Fprintf(w, "- **First Seen:** %s\n", FormatTime(item.FirstOccurrenceTime))
+	fmt.Fprintf(w, "- **Last Seen:** %s\n", FormatTime(item.LastOccurrenceTime))
 
 	return nil
 }
@@ -58,14 +72,22 @@ func (f *MarkdownFormatter) FormatInstances(w io.Writer, instances []api.Instanc
 
 	for i, inst := range instances {
 		fmt.Fprintf(w, "## Occurrence %d (ID: %d)\n\n", i+1, inst.ID)
-		fmt.Fprintf(w, "- **Time:** %s\n", inst.Time.Format(time.RFC3339))
+		fmt.Fprintf(w, "- **Time:** %s\n", FormatTime(inst.Time))
 		fmt.Fprintf(w, "- **Level:** %s\n", inst.Data.Level)
 		fmt.Fprintf(w, "- **Environment:** %s\n", inst.Data.Environment)
 
-		if inst.Data.Body.Trace != nil {
-			fmt.Fprintf(w, "- **Exception:** %s: %s\n",
-				inst.Data.Body.Trace.Exception.Class,
-				inst.Data.Body.Trace.Exception.Message)
+		traces := inst.Data.Body.Traces()
+		for i, trace := range traces {
+			label := "Exception"
+			if i > 0 {
+				label = "Caused By"
+			}
+			fmt.Fprintf(w, "- **%s:** %s: %s\n", label, trace.Exception.Class, trace.Exception.Message)
+		}
+		if len(traces) == 0 {
+			if msg := headlineString(&inst.Data.Body); msg != "" {
+				fmt.Fprintf(w, "- **Message:** %s\n", msg)
+			}
 		}
 		fmt.Fprintln(w)
 	}
@@ -77,28 +99,31 @@ func (f *MarkdownFormatter) FormatInstance(w io.Writer, instance *api.Instance)
 	fmt.Fprintf(w, "# Occurrence %d\n\n", instance.ID)
 
 	fmt.Fprintln(w, "## Summary")
-	fmt.Fprintf(w, "- **Time:** %s\n", instance.Time.Format(time.RFC3339))
+	fmt.Fprintf(w, "- **Time:** %s\n", FormatTime(instance.Time))
 	fmt.Fprintf(w, "- **Level:** %s\n", instance.Data.Level)
 	fmt.Fprintf(w, "- **Environment:** %s\n", instance.Data.Environment)
 	fmt.Fprintln(w)
 
-	if instance.Data.Body.Trace != nil {
-		fmt.Fprintln(w, "## Exception")
-		fmt.Fprintf(w, "- **Class:** %s\n", instance.Data.Body.Trace.Exception.Class)
-		fmt.Fprintf(w, "- **Message:** %s\n", instance.Data.Body.Trace.Exception.Message)
+	for i, trace := range instance.Data.Body.Traces() {
+		if i == 0 {
+			fmt.Fprintln(w, "## Exception")
+			fmt.Fprintf(w, "- **Class:** %s\n", trace.Exception.Class)
+		} else {
+			fmt.Fprintf(w, "## Caused By: %s\n", trace.Exception.Class)
+		}
+		fmt.Fprintf(w, "- **Message:** %s\n", trace.Exception.Message)
 		fmt.Fprintln(w)
 
-		if len(instance.Data.Body.Trace.Frames) > 0 {
-			fmt.Fprintln(w, "## Stack Trace")
-			fmt.Fprintln(w, "```")
-			for _, frame := range instance.Data.Body.Trace.Frames {
-				fmt.Fprintf(w, "%s:%d in %s()\n", frame.Filename, frame.Lineno, frame.Method)
+		if len(trace.Frames) > 0 {
+			if i == 0 {
+				fmt.Fprintln(w, "## Stack Trace")
 			}
-			fmt.Fprintln(w, "```")
-			fmt.Fprintln(w)
+			writeMarkdownFrames(w, trace.Frames, fullLimits())
 		}
 	}
 
+	writeMarkdownCrashReport(w, &instance.Data.Body)
+
 	if instance.Data.Request != nil && instance.Data.Request.URL != "" {
 		fmt.Fprintln(w, "## Request")
 		fmt.Fprintf(w, "- **Method:** %s\n", instance.Data.Request.Method)
@@ -112,6 +137,13 @@ func (f *MarkdownFormatter) FormatInstance(w io.Writer, instance *api.Instance)
 		fmt.Fprintln(w)
 	}
 
+	if f.Details.Request {
+		writeMarkdownDetails(w, "Request Data", requestGroups(instance.Data.Request))
+	}
+	if f.Details.Custom {
+		writeMarkdownDetails(w, "Custom Data", customGroups(instance.Data.Custom))
+	}
+
 	if instance.Data.Person != nil && instance.Data.Person.ID != "" {
 		fmt.Fprintln(w, "## Person")
 		fmt.Fprintf(w, "- **ID:** %s\n", instance.Data.Person.ID)
@@ -142,6 +174,19 @@ func (f *MarkdownFormatter) FormatInstance(w io.Writer, instance *api.Instance)
 }
 
 func (f *MarkdownFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
+	if f.MaxTokens > 0 {
+		full := fullLimits().withDetails(f.Details)
+		return writeBudgetedContext(w, f.MaxTokens, full,
+			func(w io.Writer, lim contextLimits) error { return f.writeContext(w, item, instances, lim) },
+			func(lim contextLimits) []string { return omissions(instances, full, lim) },
+			func(omitted []string) string {
+				return fmt.Sprintf("---\n_Omitted to fit %d tokens: %s._\n", f.MaxTokens, joinOmitted(omitted))
+			})
+	}
+	return f.writeContext(w, item, instances, fullLimits().withDetails(f.Details))
+}
+
+func (f *MarkdownFormatter) writeContext(w io.Writer, item *api.Item, instances []api.Instance, lim contextLimits) error {
 	fmt.Fprintf(w, "# Bug Report: %s\n\n", item.Title)
 
 	fmt.Fprintln(w, "## Summary")
@@ -149,8 +194,8 @@ func (f *MarkdownFormatter) FormatContext(w io.Writer, item *api.Item, instances
 	fmt.Fprintf(w, "- **Level:** %s\n", item.LevelString)
 	fmt.Fprintf(w, "- **Status:** %s\n", item.Status)
 	fmt.Fprintf(w, "- **Total Occurrences:** %d\n", item.TotalOccurrences)
-	fmt.Fprintf(w, "- **First Seen:** %s\n", item.FirstOccurrenceTime.Format(time.RFC3339))
-	fmt.Fprintf(w, "- **Last Seen:** %s\n", item.LastOccurrenceTime.Format(time.RFC3339))
+	fmt.Fprintf(w, "- **First Seen:** %s\n", FormatTime(item.FirstOccurrenceTime))
+	fmt.Fprintf(w, "- **Last Seen:** %s\n", FormatTime(item.LastOccurrenceTime))
 	fmt.Fprintf(w, "- **Environment:** %s\n", item.Environment)
 	fmt.Fprintf(w, "- **Framework:** %s\n", item.Framework)
 	fmt.Fprintln(w)
@@ -158,48 +203,63 @@ func (f *MarkdownFormatter) FormatContext(w io.Writer, item *api.Item, instances
 	if len(instances) > 0 {
 		inst := instances[0]
 
-		if inst.Data.Body.Trace != nil {
-			fmt.Fprintln(w, "## Exception Details")
-			fmt.Fprintf(w, "- **Type:** %s\n", inst.Data.Body.Trace.Exception.Class)
-			fmt.Fprintf(w, "- **Message:** %s\n", inst.Data.Body.Trace.Exception.Message)
+		for i, trace := range inst.Data.Body.Traces() {
+			if i == 0 {
+				fmt.Fprintln(w, "## Exception Details")
+				fmt.Fprintf(w, "- **Type:** %s\n", trace.Exception.Class)
+			} else {
+				fmt.Fprintf(w, "## Caused By: %s\n", trace.Exception.Class)
+			}
+			fmt.Fprintf(w, "- **Message:** %s\n", trace.Exception.Message)
 			fmt.Fprintln(w)
 
-			if len(inst.Data.Body.Trace.Frames) > 0 {
-				fmt.Fprintln(w, "## Stack Trace")
-				fmt.Fprintln(w, "```")
-				for _, frame := range inst.Data.Body.Trace.Frames {
-					line := fmt.Sprintf("%s:%d in %s()", frame.Filename, frame.Lineno, frame.Method)
-					fmt.Fprintln(w, line)
-					if frame.Code != "" {
-						fmt.Fprintf(w, "  > %s\n", frame.Code)
-					}
-				}
-				fmt.Fprintln(w, "```")
-				fmt.Fprintln(w)
-
-				// Affected code location
-				topFrame := inst.Data.Body.Trace.Frames[0]
-				fmt.Fprintln(w, "## Affected Code Location")
-				fmt.Fprintf(w, "- **File:** %s\n", topFrame.Filename)
-				fmt.Fprintf(w, "- **Line:** %d\n", topFrame.Lineno)
-				fmt.Fprintf(w, "- **Function:** %s()\n", topFrame.Method)
-				fmt.Fprintln(w)
+			if len(trace.Frames) == 0 {
+				continue
+			}
+			if i > 0 {
+				writeMarkdownFrames(w, trace.Frames, lim)
+				continue
 			}
+
+			fmt.Fprintln(w, "## Stack Trace")
+			writeMarkdownFrames(w, trace.Frames, lim)
+
+			// Affected code location
+			topFrame := trace.Frames[0]
+			fmt.Fprintln(w, "## Affected Code Location")
+			fmt.Fprintf(w, "- **File:** %s\n", topFrame.Filename)
+			fmt.Fprintf(w, "- **Line:** %d\n", topFrame.Lineno)
+			fmt.Fprintf(w, "- **Function:** %s()\n", topFrame.Method)
+			fmt.Fprintln(w)
+		}
+
+		writeMarkdownCrashReport(w, &inst.Data.Body)
+
+		if lim.request && lim.requestData {
+			writeMarkdownDetails(w, "Request Data", requestGroups(inst.Data.Request))
+		}
+		if lim.customData {
+			writeMarkdownDetails(w, "Custom Data", customGroups(inst.Data.Custom))
+		}
+
+		if a := analyzeOccurrences(instances); lim.analysis && a != nil {
+			writeMarkdownAnalysis(w, a)
 		}
 
 		// Recent occurrences
-		fmt.Fprintf(w, "## Recent Occurrences (%d)\n\n", len(instances))
-		for i, occ := range instances {
-			fmt.Fprintf(w, "### Occurrence %d - %s\n", i+1, occ.Time.Format(time.RFC3339))
-			if occ.Data.Request != nil && occ.Data.Request.URL != "" {
+		shown := instances[:limitCount(lim.occurrences, len(instances))]
+		fmt.Fprintf(w, "## Recent Occurrences (%d)\n\n", len(shown))
+		for i, occ := range shown {
+			fmt.Fprintf(w, "### Occurrence %d - %s\n", i+1, FormatTime(occ.Time))
+			if lim.request && occ.Data.Request != nil && occ.Data.Request.URL != "" {
 				fmt.Fprintf(w, "- **Request:** %s %s\n", occ.Data.Request.Method, occ.Data.Request.URL)
 				if browser := getBrowser(&occ.Data); browser != "" {
 					fmt.Fprintf(w, "- **Browser:** %s\n", browser)
 				}
 			}
-			if occ.Data.Person != nil && occ.Data.Person.Email != "" {
+			if lim.person && occ.Data.Person != nil && occ.Data.Person.Email != "" {
 				fmt.Fprintf(w, "- **User:** %s\n", occ.Data.Person.Email)
-			} else if occ.Data.Person != nil && occ.Data.Person.ID != "" {
+			} else if lim.person && occ.Data.Person != nil && occ.Data.Person.ID != "" {
 				fmt.Fprintf(w, "- **User ID:** %s\n", occ.Data.Person.ID)
 			}
 			if occ.Data.Server != nil && occ.Data.Server.Host != "" {
@@ -212,6 +272,146 @@ func (f *MarkdownFormatter) FormatContext(w io.Writer, item *api.Item, instances
 	return nil
 }
 
+func (f *MarkdownFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	full := fullLimits().withDetails(f.Details)
+	if f.MaxTokens > 0 {
+		return writeBudgetedContext(w, f.MaxTokens, full,
+			func(w io.Writer, lim contextLimits) error { return f.writeBundle(w, entries, lim) },
+			func(lim contextLimits) []string { return bundleOmissions(entries, full, lim) },
+			func(omitted []string) string {
+				return fmt.Sprintf("---\n_Omitted to fit %d tokens: %s._\n", f.MaxTokens, joinOmitted(omitted))
+			})
+	}
+	return f.writeBundle(w, entries, full)
+}
+
+// writeBundle writes the shared summary and timeline, then each item's
+// context with its headings nested under the bundle
+func (f *MarkdownFormatter) writeBundle(w io.Writer, entries []ContextEntry, lim contextLimits) error {
+	s := summarizeBundle(entries)
+	fmt.Fprintf(w, "# Incident Context: %s\n\n", plural(len(entries), "item"))
+
+	fmt.Fprintln(w, "## Shared Summary")
+	fmt.Fprintln(w, "| # | Title | Level | Occurrences | First Seen | Last Seen |")
+	fmt.Fprintln(w, "|---|-------|-------|-------------|------------|-----------|")
+	for _, e := range entries {
+		fmt.Fprintf(w, "| %d | %s | %s | %d | %s | %s |\n",
+			e.Item.Counter,
+			truncate(e.Item.Title, 60),
+			e.Item.LevelString,
+			e.Item.TotalOccurrences,
+			FormatCompactTime(e.Item.FirstOccurrenceTime),
+			FormatCompactTime(e.Item.LastOccurrenceTime),
+		)
+	}
+	fmt.Fprintln(w)
+	if s.Start != nil {
+		fmt.Fprintf(w, "- **Window:** %s to %s (%s)\n", FormatTime(*s.Start), FormatTime(*s.End), plural(s.Occurrences, "occurrence"))
+	}
+	for _, field := range []struct {
+		label  string
+		values []sharedValue
+	}{{"Hosts", s.Hosts}, {"Code Versions", s.CodeVersions}, {"People", s.People}} {
+		if len(field.values) > 0 {
+			fmt.Fprintf(w, "- **%s:** %s\n", field.label, describeShared(field.values))
+		}
+	}
+	fmt.Fprintln(w)
+
+	if len(s.Timeline) > 0 {
+		fmt.Fprintln(w, "## Timeline")
+		if s.TimelineOmitted > 0 {
+			fmt.Fprintf(w, "_%s earlier not shown._\n", plural(s.TimelineOmitted, "occurrence"))
+		}
+		for _, ev := range s.Timeline {
+			fmt.Fprintf(w, "- %s **#%d**%s\n", FormatTime(ev.Time), ev.Item, timelineDetail(ev))
+		}
+		fmt.Fprintln(w)
+	}
+
+	for i, l := range bundleLimits(entries, lim) {
+		var buf bytes.Buffer
+		if err := f.writeContext(&buf, entries[i].Item, entries[i].Instances, l); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "---")
+		fmt.Fprintln(w)
+		if _, err := io.WriteString(w, demoteHeadings(buf.String())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdownAnalysis writes what is constant and what varies across occurrences
+func writeMarkdownAnalysis(w io.Writer, a *occurrenceAnalysis) {
+	fmt.Fprintf(w, "## Occurrence Analysis (%d occurrences)\n\n", a.Occurrences)
+	if len(a.Constant) > 0 {
+		fmt.Fprintln(w, "**Constant:**")
+		for _, v := range a.Constant {
+			fmt.Fprintf(w, "- **%s:** %s\n", v.Field, truncate(v.Top[0].Value, 60))
+		}
+		fmt.Fprintln(w)
+	}
+	if len(a.Varying) > 0 {
+		fmt.Fprintln(w, "**Varies:**")
+		for _, v := range a.Varying {
+			fmt.Fprintf(w, "- **%s** (%s): %s\n", v.Field, distinctLabel(v), describeValues(v))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writeMarkdownFrames writes app frames and then vendor frames as separate
+// code blocks, each capped by lim. Vendor frames are left out when lim hides
+// them alongside app frames, and referenced when an earlier item showed them.
+func writeMarkdownFrames(w io.Writer, frames []api.Frame, lim contextLimits) {
+	app, vendor := separateFrames(frames)
+	if len(app) > 0 {
+		writeMarkdownFrameGroup(w, "App frames", app, lim.appFrames, lim.code)
+	}
+	if (len(app) > 0 && !lim.vendorWithApp) || lim.vendorFrames == 0 {
+		return
+	}
+	vendor, shared, sharedWith := lim.unseenVendor(vendor)
+	if len(vendor) > 0 {
+		writeMarkdownFrameGroup(w, "Vendor frames", vendor, lim.vendorFrames, lim.code)
+	}
+	if shared > 0 {
+		fmt.Fprintf(w, "_%s shared with #%d, shown above._\n\n", plural(shared, "vendor frame"), sharedWith)
+	}
+}
+
+func writeMarkdownFrameGroup(w io.Writer, label string, frames []api.Frame, limit int, code bool) {
+	shown := limitCount(limit, len(frames))
+	fmt.Fprintf(w, "**%s**\n", label)
+	fmt.Fprintln(w, "```")
+	for _, frame := range frames[:shown] {
+		fmt.Fprintf(w, "%s:%d in %s()\n", frame.Filename, frame.Lineno, frame.Method)
+		if code {
+			writeFrameCode(w, frame)
+		}
+		writeFrameBlame(w, frame)
+	}
+	if n := len(frames) - shown; n > 0 {
+		fmt.Fprintf(w, "... %s omitted\n", plural(n, "frame"))
+	}
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w)
+}
+
+func writeMarkdownCrashReport(w io.Writer, body *api.Body) {
+	raw := crashReport(body)
+	if raw == "" {
+		return
+	}
+	fmt.Fprintln(w, "## Crash Report")
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w, raw)
+	fmt.Fprintln(w, "```")
+	fmt.Fprintln(w)
+}
+
 func (f *MarkdownFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
 	fmt.Fprintln(w, "# Rollbar Project Info")
 	fmt.Fprintln(w)
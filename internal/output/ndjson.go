@@ -0,0 +1,66 @@
+package output
+
+import (
+	"io"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/jq"
+)
+
+// NDJSONFormatter writes one compact JSON record per line, writing each
+// record as soon as it is encoded. Lists are split into their elements, which
+// have the same layout as in JSON output; --fields and --jq apply to each
+// record.
+type NDJSONFormatter struct {
+	Fields []string
+	Query  *jq.Query
+}
+
+func (f *NDJSONFormatter) record(w io.Writer, v interface{}) error {
+	jf := JSONFormatter{Indent: CompactJSON, Fields: f.Fields, Query: f.Query}
+	return jf.write(w, v, "", false)
+}
+
+func (f *NDJSONFormatter) FormatItems(w io.Writer, items []api.Item) error {
+	for i := range items {
+		if err := f.record(w, newItemRecord(&items[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *NDJSONFormatter) FormatItem(w io.Writer, item *api.Item) error {
+	return f.record(w, newItemRecord(item))
+}
+
+func (f *NDJSONFormatter) FormatInstances(w io.Writer, instances []api.Instance) error {
+	for i := range instances {
+		if err := f.record(w, newInstanceRecord(&instances[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *NDJSONFormatter) FormatInstance(w io.Writer, instance *api.Instance) error {
+	return f.record(w, newInstanceRecord(instance))
+}
+
+func (f *NDJSONFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
+	return f.record(w, newContextData(item, instances))
+}
+
+// FormatBundle writes each item's context as its own record
+func (f *NDJSONFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	for _, e := range entries {
+		if err := f.record(w, newContextData(e.Item, e.Instances)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *NDJSONFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
+	return f.record(w, info)
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// SchemaVersion identifies the layout of JSON output. Fields may be added
+// within a version; removing or changing one needs a new version.
+const SchemaVersion = "rollbar-cli/v1"
+
+// ItemRecord is an item in JSON output: the Rollbar item with its level as a
+// name and its occurrence times in ISO 8601
+type ItemRecord struct {
+	*api.Item
+	LevelName       string     `json:"level" description:"Level name" enum:"debug,info,warning,error,critical,unknown"`
+	FirstOccurrence *time.Time `json:"first_occurrence_time,omitempty" description:"First occurrence (ISO 8601, UTC)"`
+	LastOccurrence  *time.Time `json:"last_occurrence_time,omitempty" description:"Last occurrence (ISO 8601, UTC)"`
+}
+
+func newItemRecord(item *api.Item) ItemRecord {
+	level := item.LevelString
+	if level == "" {
+		level = api.LevelToString(item.Level.Int())
+	}
+	return ItemRecord{
+		Item:            item,
+		LevelName:       level,
+		FirstOccurrence: utcTime(item.FirstOccurrenceTime),
+		LastOccurrence:  utcTime(item.LastOccurrenceTime),
+	}
+}
+
+func newItemRecords(items []api.Item) []ItemRecord {
+	records := make([]ItemRecord, len(items))
+	for i := range items {
+		records[i] = newItemRecord(&items[i])
+	}
+	return records
+}
+
+// InstanceRecord is an occurrence in JSON output, with its time in ISO 8601
+type InstanceRecord struct {
+	*api.Instance
+	OccurredAt *time.Time `json:"time,omitempty" description:"Occurrence time (ISO 8601, UTC)"`
+}
+
+func newInstanceRecord(inst *api.Instance) InstanceRecord {
+	return InstanceRecord{Instance: inst, OccurredAt: utcTime(inst.Time)}
+}
+
+func newInstanceRecords(instances []api.Instance) []InstanceRecord {
+	records := make([]InstanceRecord, len(instances))
+	for i := range instances {
+		records[i] = newInstanceRecord(&instances[i])
+	}
+	return records
+}
+
+// utcTime returns t in UTC, or nil when unknown
+func utcTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// Page is the page of results a list came from
+type Page struct {
+	Number  int  `json:"page" description:"Page number, starting at 1"`
+	HasMore bool `json:"has_more" description:"The API returned a full page (for several levels, a full page for any of them), so the next page may have more"`
+}
+
+// WithPage records the page a list came from on formatters that report it
+func WithPage(f Formatter, p Page) Formatter {
+	if f, ok := f.(*JSONFormatter); ok {
+		f.Page = p
+	}
+	return f
+}
+
+// The JSON documents, one per kind of output. Each carries the schema version.
+
+type ItemsDocument struct {
+	Schema string       `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Items  []ItemRecord `json:"items"`
+	Page
+}
+
+type ItemDocument struct {
+	Schema string     `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Item   ItemRecord `json:"item"`
+}
+
+type OccurrencesDocument struct {
+	Schema      string           `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Occurrences []InstanceRecord `json:"occurrences"`
+	Page
+}
+
+type OccurrenceDocument struct {
+	Schema     string         `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Occurrence InstanceRecord `json:"occurrence"`
+}
+
+// ContextDocument is an item's context. Inside a bundle it has no schema.
+type ContextDocument struct {
+	Schema         string              `json:"schema,omitempty" enum:"rollbar-cli/v1"`
+	Item           ItemRecord          `json:"item"`
+	ExceptionChain []chainLink         `json:"exception_chain,omitempty" description:"Latest occurrence's exceptions, outermost first"`
+	CrashReport    string              `json:"crash_report,omitempty"`
+	Analysis       *occurrenceAnalysis `json:"analysis,omitempty" description:"Fields that stay constant or vary across the occurrences"`
+	Instances      []InstanceRecord    `json:"instances"`
+}
+
+func newContextData(item *api.Item, instances []api.Instance) ContextDocument {
+	data := ContextDocument{
+		Item:      newItemRecord(item),
+		Analysis:  analyzeOccurrences(instances),
+		Instances: newInstanceRecords(instances),
+	}
+	// The latest occurrence's cause chain, outermost first, with frames split
+	// into app and vendor code
+	if len(instances) > 0 {
+		data.ExceptionChain = exceptionChain(&instances[0].Data.Body)
+		data.CrashReport = crashReport(&instances[0].Data.Body)
+	}
+	return data
+}
+
+// BundleDocument is the context of several items
+type BundleDocument struct {
+	Schema  string            `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Summary *bundleSummary    `json:"summary"`
+	Items   []ContextDocument `json:"items"`
+}
+
+func newBundleData(entries []ContextEntry) BundleDocument {
+	data := BundleDocument{
+		Summary: summarizeBundle(entries),
+		Items:   make([]ContextDocument, len(entries)),
+	}
+	for i, e := range entries {
+		data.Items[i] = newContextData(e.Item, e.Instances)
+	}
+	return data
+}
+
+type ProjectDocument struct {
+	Schema  string           `json:"schema" enum:"rollbar-cli/v1" required:"true"`
+	Project *api.ProjectInfo `json:"project"`
+}
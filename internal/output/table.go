@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/term"
 )
 
 // ANSI color codes
@@ -24,7 +25,14 @@ const (
 
 // TableFormatter outputs data as human-readable tables
 type TableFormatter struct {
-	Color bool
+	Color   bool
+	Details Details
+	Width   int  // Terminal width that list rows are fitted to (0 = don't fit)
+	Wide    bool // Show whole titles and messages and every column
+}
+
+func (f *TableFormatter) layout() tableLayout {
+	return tableLayout{width: f.Width, wide: f.Wide}
 }
 
 func (f *TableFormatter) color(code, text string) string {
@@ -68,6 +76,9 @@ func formatRelativeTime(t time.Time) string {
 	if t.IsZero() {
 		return "unknown"
 	}
+	if timeFormat != TimeRelative {
+		return FormatTime(t)
+	}
 	d := time.Since(t)
 	switch {
 	case d < time.Minute:
@@ -99,11 +110,9 @@ func formatRelativeTime(t time.Time) string {
 	}
 }
 
+// truncate shortens s to maxLen display cells, ending it with "..." when cut
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	return term.Truncate(s, maxLen, "...")
 }
 
 func (f *TableFormatter) FormatItems(w io.Writer, items []api.Item) error {
@@ -112,26 +121,55 @@ func (f *TableFormatter) FormatItems(w io.Writer, items []api.Item) error {
 		return nil
 	}
 
-	// Header
-	fmt.Fprintf(w, "%-7s %-50s %-10s %-10s %8s %-15s\n",
-		"#", "TITLE", "LEVEL", "STATUS", "OCC", "LAST SEEN")
-	fmt.Fprintln(w, strings.Repeat("-", 110))
+	cols := []tableColumn{
+		{header: "#"},
+		{header: "TITLE", max: 50, flex: 20},
+		{header: "LEVEL", priority: 1},
+		{header: "STATUS", priority: 4},
+		{header: "OCC", right: true, priority: 2},
+		{header: "LAST SEEN", priority: 3},
+	}
+	showOwners := hasOwners(items)
+	if showOwners {
+		cols = append(cols, tableColumn{header: "OWNER", max: 25, priority: 5})
+	}
 
-	for _, item := range items {
-		title := truncate(item.Title, 50)
-		fmt.Fprintf(w, "%-7d %-50s %-10s %-10s %8d %-15s\n",
-			item.Counter,
-			title,
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		rows[i] = []string{
+			fmt.Sprintf("%d", item.Counter),
+			item.Title,
 			f.levelColor(item.LevelString),
 			f.statusColor(item.Status),
-			item.TotalOccurrences,
+			fmt.Sprintf("%d", item.TotalOccurrences),
 			formatRelativeTime(item.LastOccurrenceTime),
-		)
+		}
+		if showOwners {
+			rows[i] = append(rows[i], ownersString(item.Owners))
+		}
 	}
+	f.layout().writeTable(w, cols, rows)
 
 	return nil
 }
 
+// hasOwners reports whether any item has CODEOWNERS owners set
+func hasOwners(items []api.Item) bool {
+	for _, item := range items {
+		if item.Owners != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func ownersString(owners []string) string {
+	if len(owners) == 0 {
+		return "-"
+	}
+	return strings.Join(owners, ",")
+}
+
 func (f *TableFormatter) FormatItem(w io.Writer, item *api.Item) error {
 	fmt.Fprintf(w, "%s Item #%d: %s\n\n",
 		f.color(colorBold, ""),
@@ -146,12 +184,8 @@ func (f *TableFormatter) FormatItem(w io.Writer, item *api.Item) error {
 	fmt.Fprintf(w, "Platform:    %s\n", item.Platform)
 	fmt.Fprintln(w)
 	fmt.Fprintf(w, "Total Occurrences: %d\n", item.TotalOccurrences)
-	fmt.Fprintf(w, "First Seen:        %s (%s)\n",
-		item.FirstOccurrenceTime.Format(time.RFC3339),
-		formatRelativeTime(item.FirstOccurrenceTime))
-	fmt.Fprintf(w, "Last Seen:         %s (%s)\n",
-		item.LastOccurrenceTime.Format(time.RFC3339),
-		formatRelativeTime(item.LastOccurrenceTime))
+	fmt.Fprintf(w, "First Seen:        %s\n", formatTimeDetail(item.FirstOccurrenceTime))
+	fmt.Fprintf(w, "Last Seen:         %s\n", formatTimeDetail(item.LastOccurrenceTime))
 
 	return nil
 }
@@ -162,25 +196,23 @@ func (f *TableFormatter) FormatInstances(w io.Writer, instances []api.Instance)
 		return nil
 	}
 
-	fmt.Fprintf(w, "%-20s %-12s %-40s %-20s\n",
-		"ID", "LEVEL", "MESSAGE", "TIME")
-	fmt.Fprintln(w, strings.Repeat("-", 100))
-
-	for _, inst := range instances {
-		msg := ""
-		if inst.Data.Body.Trace != nil {
-			msg = truncate(inst.Data.Body.Trace.Exception.Message, 40)
-		} else if inst.Data.Body.Message != nil {
-			msg = truncate(inst.Data.Body.Message.Body, 40)
-		}
-
-		fmt.Fprintf(w, "%-20d %-12s %-40s %-20s\n",
-			inst.ID,
+	cols := []tableColumn{
+		{header: "ID"},
+		{header: "LEVEL", priority: 1},
+		{header: "MESSAGE", max: 40, flex: 20},
+		{header: "TIME", priority: 2},
+	}
+	rows := make([][]string, len(instances))
+	for i, inst := range instances {
+		_, msg := headline(&inst.Data.Body)
+		rows[i] = []string{
+			fmt.Sprintf("%d", inst.ID),
 			f.levelColor(inst.Data.Level),
 			msg,
 			formatRelativeTime(inst.Time),
-		)
+		}
 	}
+	f.layout().writeTable(w, cols, rows)
 
 	return nil
 }
@@ -190,26 +222,30 @@ func (f *TableFormatter) FormatInstance(w io.Writer, instance *api.Instance) err
 
 	fmt.Fprintf(w, "Level:       %s\n", f.levelColor(instance.Data.Level))
 	fmt.Fprintf(w, "Environment: %s\n", instance.Data.Environment)
-	fmt.Fprintf(w, "Time:        %s (%s)\n",
-		instance.Time.Format(time.RFC3339),
-		formatRelativeTime(instance.Time))
+	fmt.Fprintf(w, "Time:        %s\n", formatTimeDetail(instance.Time))
 
-	if instance.Data.Body.Trace != nil {
+	for i, trace := range instance.Data.Body.Traces() {
+		heading := "Exception"
+		if i > 0 {
+			heading = "Caused By"
+		}
 		fmt.Fprintln(w)
-		fmt.Fprintf(w, "%sException%s\n", colorBold, colorReset)
-		fmt.Fprintf(w, "  Class:   %s\n", instance.Data.Body.Trace.Exception.Class)
-		fmt.Fprintf(w, "  Message: %s\n", instance.Data.Body.Trace.Exception.Message)
+		fmt.Fprintf(w, "%s%s%s\n", colorBold, heading, colorReset)
+		fmt.Fprintf(w, "  Class:   %s\n", trace.Exception.Class)
+		fmt.Fprintf(w, "  Message: %s\n", trace.Exception.Message)
 
-		if len(instance.Data.Body.Trace.Frames) > 0 {
+		if len(trace.Frames) > 0 {
 			fmt.Fprintln(w)
 			fmt.Fprintf(w, "%sStack Trace%s\n", colorBold, colorReset)
-			for i, frame := range instance.Data.Body.Trace.Frames {
-				if i >= 10 {
-					fmt.Fprintf(w, "  ... and %d more frames\n", len(instance.Data.Body.Trace.Frames)-10)
-					break
-				}
-				fmt.Fprintf(w, "  %s:%d in %s()\n", frame.Filename, frame.Lineno, frame.Method)
-			}
+			f.writeFrames(w, trace.Frames)
+		}
+	}
+
+	if raw := crashReport(&instance.Data.Body); raw != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%sCrash Report%s\n", colorBold, colorReset)
+		for _, line := range strings.Split(raw, "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
 		}
 	}
 
@@ -219,6 +255,13 @@ func (f *TableFormatter) FormatInstance(w io.Writer, instance *api.Instance) err
 		fmt.Fprintf(w, "  %s %s\n", instance.Data.Request.Method, instance.Data.Request.URL)
 	}
 
+	if f.Details.Request {
+		f.writeDetails(w, "Request Data", requestGroups(instance.Data.Request))
+	}
+	if f.Details.Custom {
+		f.writeDetails(w, "Custom Data", customGroups(instance.Data.Custom))
+	}
+
 	if instance.Data.Person != nil && instance.Data.Person.ID != "" {
 		fmt.Fprintln(w)
 		fmt.Fprintf(w, "%sPerson%s\n", colorBold, colorReset)
@@ -232,21 +275,60 @@ func (f *TableFormatter) FormatInstance(w io.Writer, instance *api.Instance) err
 	return nil
 }
 
+// writeFrames lists app frames first, then vendor frames. Vendor frames are
+// collapsed to a count when the trace has app frames.
+func (f *TableFormatter) writeFrames(w io.Writer, frames []api.Frame) {
+	app, vendor := separateFrames(frames)
+	list := app
+	if len(app) == 0 {
+		list = vendor
+	}
+	for i, frame := range list {
+		if i >= 10 {
+			fmt.Fprintf(w, "  ... and %d more frames\n", len(list)-10)
+			break
+		}
+		fmt.Fprintf(w, "  %s:%d in %s()\n", frame.Filename, frame.Lineno, frame.Method)
+	}
+	if len(app) > 0 && len(vendor) > 0 {
+		fmt.Fprintf(w, "  %s\n", f.color(colorGray, fmt.Sprintf("+ %s", plural(len(vendor), "vendor frame"))))
+	}
+}
+
 func (f *TableFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
 	// Just use item formatter for table output
 	if err := f.FormatItem(w, item); err != nil {
 		return err
 	}
 
+	if a := analyzeOccurrences(instances); a != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "%sOccurrence Analysis (%d)%s\n", colorBold, a.Occurrences, colorReset)
+		for _, v := range a.Constant {
+			fmt.Fprintf(w, "  %-14s %s\n", v.Field+":", truncate(v.Top[0].Value, 60))
+		}
+		for _, v := range a.Varying {
+			fmt.Fprintf(w, "  %-14s %s %s\n", v.Field+":", describeValues(v), f.color(colorGray, "["+distinctLabel(v)+"]"))
+		}
+	}
+
 	if len(instances) > 0 {
 		fmt.Fprintln(w)
 		fmt.Fprintf(w, "%sRecent Occurrences%s\n", colorBold, colorReset)
 		for _, inst := range instances {
-			fmt.Fprintf(w, "\n  %s [%s]\n", inst.Time.Format(time.RFC3339), inst.Data.Level)
-			if inst.Data.Body.Trace != nil {
-				fmt.Fprintf(w, "    %s: %s\n",
-					inst.Data.Body.Trace.Exception.Class,
-					inst.Data.Body.Trace.Exception.Message)
+			fmt.Fprintf(w, "\n  %s [%s]\n", FormatTime(inst.Time), inst.Data.Level)
+			traces := inst.Data.Body.Traces()
+			for i, trace := range traces {
+				prefix := ""
+				if i > 0 {
+					prefix = "caused by "
+				}
+				fmt.Fprintf(w, "    %s%s: %s\n", prefix, trace.Exception.Class, trace.Exception.Message)
+			}
+			if len(traces) == 0 {
+				if msg := headlineString(&inst.Data.Body); msg != "" {
+					fmt.Fprintf(w, "    %s\n", msg)
+				}
 			}
 		}
 	}
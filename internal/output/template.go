@@ -0,0 +1,139 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/term"
+)
+
+// TemplateFormatter renders output with a Go text/template, given as
+// -o template='{{.Counter}} {{.Title}}' or with --template-file. Lists execute
+// the template once per element; everything else executes it once. Each
+// execution ends with a newline unless the template writes one.
+type TemplateFormatter struct {
+	Text  string
+	Color bool // Whether levelColor emits ANSI colors
+
+	tmpl *template.Template
+}
+
+// TemplateText returns the template of a "template=..." format
+func TemplateText(format Format) (string, bool) {
+	return strings.CutPrefix(string(format), string(FormatTemplate)+"=")
+}
+
+// ParseTemplate parses text with the template helper functions:
+//
+//	ago        relative time, e.g. {{ago .LastOccurrenceTime}} -> "3 hours ago"
+//	levelColor a level colored like the table output
+//	truncate   shorten to n display cells, e.g. {{.Title | truncate 40}}
+//	json       encode a value as JSON
+//	join       join strings, e.g. {{.Owners | join ", "}}
+func ParseTemplate(text string, color bool) (*template.Template, error) {
+	table := &TableFormatter{Color: color}
+	funcs := template.FuncMap{
+		"ago":        func(t time.Time) string { return formatRelativeTime(t) },
+		"levelColor": table.levelColor,
+		"truncate": func(n int, s string) (string, error) {
+			if n < 0 {
+				return "", fmt.Errorf("truncate: length must be 0 or more, got %d", n)
+			}
+			if n < 4 {
+				// Too short for "..."
+				return term.Truncate(s, n, ""), nil
+			}
+			return truncate(s, n), nil
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"join": func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	}
+	return template.New("output").Funcs(funcs).Parse(text)
+}
+
+// execute runs the template against data once
+func (f *TemplateFormatter) execute(w io.Writer, data interface{}) error {
+	if f.tmpl == nil {
+		tmpl, err := ParseTemplate(f.Text, f.Color)
+		if err != nil {
+			return err
+		}
+		f.tmpl = tmpl
+	}
+
+	var b strings.Builder
+	if err := f.tmpl.Execute(&b, data); err != nil {
+		return err
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+func (f *TemplateFormatter) FormatItems(w io.Writer, items []api.Item) error {
+	for i := range items {
+		if err := f.execute(w, &items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *TemplateFormatter) FormatItem(w io.Writer, item *api.Item) error {
+	return f.execute(w, item)
+}
+
+func (f *TemplateFormatter) FormatInstances(w io.Writer, instances []api.Instance) error {
+	for i := range instances {
+		if err := f.execute(w, &instances[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *TemplateFormatter) FormatInstance(w io.Writer, instance *api.Instance) error {
+	return f.execute(w, instance)
+}
+
+// FormatContext executes the template with .Item, .ExceptionChain,
+// .CrashReport, .Analysis and .Instances, as in the JSON context
+func (f *TemplateFormatter) FormatContext(w io.Writer, item *api.Item, instances []api.Instance) error {
+	return f.execute(w, newContextData(item, instances))
+}
+
+// FormatBundle executes the template with .Summary and .Items, each item
+// holding the same fields as FormatContext
+func (f *TemplateFormatter) FormatBundle(w io.Writer, entries []ContextEntry) error {
+	return f.execute(w, newBundleData(entries))
+}
+
+func (f *TemplateFormatter) FormatProjectInfo(w io.Writer, info *api.ProjectInfo) error {
+	return f.execute(w, info)
+}
+
+// CheckTemplate validates a format that selects the template formatter
+func CheckTemplate(format Format) error {
+	if format == FormatTemplate {
+		return fmt.Errorf("-o template needs a template: -o template='{{.Title}}' or --template-file FILE")
+	}
+	text, ok := TemplateText(format)
+	if !ok {
+		return nil
+	}
+	if _, err := ParseTemplate(text, false); err != nil {
+		return fmt.Errorf("invalid output template: %w", err)
+	}
+	return nil
+}
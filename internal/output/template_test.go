@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	items := sampleItems()
+	items[0].LastOccurrenceTime = time.Now().Add(-3 * time.Hour)
+	items[0].Owners = []string{"@acme/billing", "@alice"}
+	items = append(items, items[0])
+	items[1].Counter, items[1].Title = 124, "A much longer error title"
+
+	tests := []struct {
+		name string
+		text string
+		run  func(f *TemplateFormatter, w *bytes.Buffer) error
+		want string
+	}{
+		{
+			"items once per element",
+			"{{.Counter}} {{.Title}}",
+			func(f *TemplateFormatter, w *bytes.Buffer) error { return f.FormatItems(w, items) },
+			"123 Test Error\n124 A much longer error title\n",
+		},
+		{
+			"helpers",
+			`{{.Title | truncate 10}} {{ago .LastOccurrenceTime}} {{levelColor .LevelString}} {{.Owners | join ","}}`,
+			func(f *TemplateFormatter, w *bytes.Buffer) error { return f.FormatItem(w, &items[0]) },
+			"Test Error 3 hours ago error @acme/billing,@alice\n",
+		},
+		{
+			"json and trailing newline kept",
+			"{{json .Owners}}\n",
+			func(f *TemplateFormatter, w *bytes.Buffer) error { return f.FormatItem(w, &items[0]) },
+			`["@acme/billing","@alice"]` + "\n",
+		},
+		{
+			"context",
+			"#{{.Item.Counter}} {{len .Instances}} {{(index .ExceptionChain 0).Class}}",
+			func(f *TemplateFormatter, w *bytes.Buffer) error {
+				return f.FormatContext(w, &items[0], sampleInstances())
+			},
+			"#123 1 TestError\n",
+		},
+		{
+			"bundle",
+			"{{range .Items}}{{.Item.Counter}} {{end}}{{.Summary.Occurrences}}",
+			func(f *TemplateFormatter, w *bytes.Buffer) error { return f.FormatBundle(w, bundleEntries()) },
+			"101 102 4\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.run(&TemplateFormatter{Text: tt.text}, &buf); err != nil {
+				t.Fatalf("template failed: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateLevelColor(t *testing.T) {
+	var buf bytes.Buffer
+	f := &TemplateFormatter{Text: "{{levelColor .LevelString}}", Color: true}
+	if err := f.FormatItem(&buf, sampleItem()); err != nil {
+		t.Fatalf("template failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\033[") {
+		t.Errorf("expected ANSI color, got %q", buf.String())
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	tests := []struct {
+		format  Format
+		wantErr bool
+	}{
+		{FormatTable, false},
+		{"template={{.Title}}", false},
+		{"template={{.Title", true},
+		{"template={{nope .Title}}", true},
+		{FormatTemplate, true},
+	}
+	for _, tt := range tests {
+		if err := CheckTemplate(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("CheckTemplate(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+
+	var buf bytes.Buffer
+	f := &TemplateFormatter{Text: "{{.Missing}}"}
+	if err := f.FormatInstance(&buf, &api.Instance{}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestTemplateTruncate(t *testing.T) {
+	item := sampleItem()
+	item.Title = "日本語のエラー"
+	for text, want := range map[string]string{
+		"{{.Title | truncate 3}}": "日",
+		"{{.Title | truncate 0}}": "",
+		"{{.Title | truncate 9}}": "日本語...",
+	} {
+		var buf bytes.Buffer
+		if err := (&TemplateFormatter{Text: text}).FormatItem(&buf, item); err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		if got := strings.TrimSuffix(buf.String(), "\n"); got != want {
+			t.Errorf("%s = %q, want %q", text, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := (&TemplateFormatter{Text: "{{.Title | truncate -1}}"}).FormatItem(&buf, item)
+	if err == nil || !strings.Contains(err.Error(), "length must be 0 or more") {
+		t.Errorf("expected a clear error for a negative length, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// TicketFormats are the issue trackers a ticket can be written for
+var TicketFormats = []string{"github", "gitlab", "jira"}
+
+// Ticket limits
+const (
+	ticketTitleMax  = 250 // Below GitHub's 256 and Jira's 255 characters
+	ticketAppFrames = 10
+)
+
+// Ticket is an issue for an item, in a tracker's markup
+type Ticket struct {
+	Title  string   `json:"title" required:"true"`
+	Body   string   `json:"body" description:"GitHub or GitLab Markdown, or Jira wiki markup" required:"true"`
+	Labels []string `json:"labels" description:"rollbar, level:<level> and env:<environment>" required:"true"`
+}
+
+// TicketOptions are the tracker settings of a ticket
+type TicketOptions struct {
+	Format      string // github, gitlab or jira
+	Link        string // Item page on Rollbar; omitted when empty
+	JiraProject string // Project key of a Jira payload
+	JiraType    string // Issue type of a Jira payload (default Bug)
+}
+
+// NewTicket builds an issue from an item's context: its summary, a link to
+// Rollbar, the latest occurrence's exception chain and the occurrence analysis
+func NewTicket(item *api.Item, instances []api.Instance, opts TicketOptions) (*Ticket, error) {
+	var m markup
+	switch opts.Format {
+	case "github", "gitlab":
+		m = markdownMarkup{}
+	case "jira":
+		m = jiraMarkup{}
+	default:
+		return nil, fmt.Errorf("unknown ticket format %q (available: %s)", opts.Format, strings.Join(TicketFormats, ", "))
+	}
+
+	doc := newContextData(item, instances)
+	title := item.Title
+	if len(instances) > 0 {
+		if h := headlineString(&instances[0].Data.Body); h != "" {
+			title = h
+		}
+	}
+	title = truncate(fmt.Sprintf("[Rollbar #%d] %s", item.Counter, strings.Join(strings.Fields(title), " ")), ticketTitleMax)
+
+	labels := []string{"rollbar", "level:" + doc.Item.LevelName}
+	if item.Environment != "" {
+		labels = append(labels, "env:"+strings.Join(strings.Fields(item.Environment), "-"))
+	}
+
+	return &Ticket{Title: title, Body: ticketBody(m, &doc, opts.Link), Labels: labels}, nil
+}
+
+func ticketBody(m markup, doc *ContextDocument, link string) string {
+	var b strings.Builder
+	item := doc.Item.Item
+
+	b.WriteString(m.heading("Summary"))
+	ref := fmt.Sprintf("#%d", item.Counter)
+	if link != "" {
+		ref = m.link(ref, link)
+	}
+	b.WriteString(m.field("Rollbar item", ref))
+	b.WriteString(m.field("Level", doc.Item.LevelName))
+	if item.Environment != "" {
+		b.WriteString(m.field("Environment", m.text(oneLine(item.Environment))))
+	}
+	b.WriteString(m.field("Status", item.Status))
+	b.WriteString(m.field("Occurrences", fmt.Sprintf("%d", item.TotalOccurrences)))
+	if !item.FirstOccurrenceTime.IsZero() {
+		b.WriteString(m.field("First seen", item.FirstOccurrenceTime.UTC().Format(time.RFC3339)))
+	}
+	if !item.LastOccurrenceTime.IsZero() {
+		b.WriteString(m.field("Last seen", item.LastOccurrenceTime.UTC().Format(time.RFC3339)))
+	}
+	if len(doc.Instances) > 0 {
+		if version := doc.Instances[0].Data.ResolvedCodeVersion(); version != "" {
+			b.WriteString(m.field("Code version", m.code(version)))
+		}
+		if req := doc.Instances[0].Data.Request; req != nil && req.URL != "" {
+			b.WriteString(m.field("Request", m.code(strings.TrimSpace(req.Method+" "+req.URL))))
+		}
+	}
+	b.WriteString("\n")
+
+	for i, exc := range doc.ExceptionChain {
+		heading := "Exception"
+		if i > 0 {
+			heading = "Caused by"
+		}
+		b.WriteString(m.heading(fmt.Sprintf("%s: %s", heading, m.text(oneLine(exc.Class)))))
+		if exc.Message != "" {
+			b.WriteString(m.quote(m.text(exc.Message)))
+		}
+		if len(exc.AppFrames) > 0 {
+			var lines []string
+			for _, f := range exc.AppFrames[:limitCount(ticketAppFrames, len(exc.AppFrames))] {
+				lines = append(lines, fmt.Sprintf("%s:%d in %s()", f.Filename, f.Lineno, f.Method))
+			}
+			if n := len(exc.AppFrames) - ticketAppFrames; n > 0 {
+				lines = append(lines, fmt.Sprintf("... %s omitted", plural(n, "app frame")))
+			}
+			if n := len(exc.VendorFrames); n > 0 {
+				lines = append(lines, fmt.Sprintf("... %s not shown", plural(n, "vendor frame")))
+			}
+			b.WriteString(m.block(lines))
+		}
+	}
+	if doc.CrashReport != "" {
+		b.WriteString(m.heading("Crash report"))
+		b.WriteString(m.block(strings.Split(doc.CrashReport, "\n")))
+	}
+
+	if a := doc.Analysis; a != nil && len(a.Varying) > 0 {
+		b.WriteString(m.heading(fmt.Sprintf("What varies across %d occurrences", a.Occurrences)))
+		for _, v := range a.Varying {
+			b.WriteString(m.field(m.text(oneLine(v.Field)), m.text(oneLine(fmt.Sprintf("%s: %s", distinctLabel(v), describeValues(v))))))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.footer(fmt.Sprintf("Created from Rollbar item #%d by rollbar-cli.", item.Counter)))
+	return b.String()
+}
+
+// oneLine collapses whitespace so that a payload value can't start a new
+// heading or list item
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Payload returns the tracker's create-issue request body: GitHub's
+// POST /repos/{owner}/{repo}/issues, GitLab's POST /projects/{id}/issues or
+// Jira's POST /rest/api/2/issue
+func (t *Ticket) Payload(opts TicketOptions) interface{} {
+	switch opts.Format {
+	case "gitlab":
+		return map[string]interface{}{
+			"title":       t.Title,
+			"description": t.Body,
+			"labels":      strings.Join(t.Labels, ","),
+		}
+	case "jira":
+		issueType := opts.JiraType
+		if issueType == "" {
+			issueType = "Bug"
+		}
+		return map[string]interface{}{
+			"fields": map[string]interface{}{
+				"project":     map[string]string{"key": opts.JiraProject},
+				"issuetype":   map[string]string{"name": issueType},
+				"summary":     t.Title,
+				"description": t.Body,
+				"labels":      t.Labels,
+			},
+		}
+	default:
+		return map[string]interface{}{
+			"title":  t.Title,
+			"body":   t.Body,
+			"labels": t.Labels,
+		}
+	}
+}
+
+// markup renders the pieces of a ticket body in a tracker's syntax. Links and
+// code are inline; the other pieces end with a newline. Payload values can
+// contain anything, so code and blocks are sized or escaped to fit their
+// content, and other payload text goes through text first.
+type markup interface {
+	text(s string) string // Escapes markup and neutralises mentions and references
+	heading(text string) string
+	field(label, value string) string
+	link(text, url string) string
+	code(text string) string
+	quote(text string) string
+	block(lines []string) string
+	footer(text string) string
+}
+
+// markdownMarkup is GitHub and GitLab flavoured Markdown
+type markdownMarkup struct{}
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+		"<", `\<`, ">", `\>`, "!", `\!`, "|", `\|`, "~", `\~`,
+	)
+	// referencePattern matches @mentions, #123 issue and !123 merge request references
+	referencePattern = regexp.MustCompile(`([@#!])([A-Za-z0-9])`)
+)
+
+// text escapes Markdown and breaks up references with a zero-width space so
+// that a quoted message can't ping people or cross-link issues
+func (markdownMarkup) text(s string) string {
+	s = referencePattern.ReplaceAllString(s, "$1\u200b$2")
+	return markdownEscaper.Replace(s)
+}
+
+func (markdownMarkup) heading(text string) string { return "## " + text + "\n\n" }
+func (markdownMarkup) field(label, value string) string {
+	return fmt.Sprintf("- **%s:** %s\n", label, value)
+}
+func (markdownMarkup) link(text, url string) string { return fmt.Sprintf("[%s](%s)", text, url) }
+func (markdownMarkup) code(text string) string {
+	fence := "`"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + text + fence
+}
+func (markdownMarkup) quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ") + "\n\n"
+}
+func (markdownMarkup) block(lines []string) string {
+	content := strings.Join(lines, "\n")
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + content + "\n" + fence + "\n\n"
+}
+func (markdownMarkup) footer(text string) string { return "---\n_" + text + "_\n" }
+
+// jiraMarkup is Jira wiki markup
+type jiraMarkup struct{}
+
+var (
+	jiraEscaper = strings.NewReplacer(
+		`\`, `\\`, "{", `\{`, "}", `\}`, "[", `\[`, "]", `\]`, "|", `\|`, "!", `\!`,
+	)
+	// noformatPattern matches the only text that ends a {noformat} block
+	noformatPattern = regexp.MustCompile(`(?i)\{(noformat)`)
+)
+
+// text escapes macros, links, mentions ([~user]) and images
+func (jiraMarkup) text(s string) string { return jiraEscaper.Replace(s) }
+
+func (jiraMarkup) heading(text string) string { return "h2. " + text + "\n\n" }
+func (jiraMarkup) field(label, value string) string {
+	return fmt.Sprintf("* *%s:* %s\n", label, value)
+}
+func (jiraMarkup) link(text, url string) string { return fmt.Sprintf("[%s|%s]", text, url) }
+func (jiraMarkup) code(text string) string      { return "{{" + jiraEscaper.Replace(text) + "}}" }
+func (jiraMarkup) quote(text string) string     { return "{quote}\n" + text + "\n{quote}\n\n" }
+func (jiraMarkup) block(lines []string) string {
+	// Nothing is escaped inside {noformat}, so a closing tag is broken up
+	content := noformatPattern.ReplaceAllString(strings.Join(lines, "\n"), "{ $1")
+	return "{noformat}\n" + content + "\n{noformat}\n\n"
+}
+func (jiraMarkup) footer(text string) string { return "----\n_" + text + "_\n" }
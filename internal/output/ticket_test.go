@@ -0,0 +1,143 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func TestNewTicket(t *testing.T) {
+	item := sampleItem()
+	item.Counter = 42
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"github", []string{
+			"## Summary\n",
+			"- **Rollbar item:** [#42](https://rollbar.com/acme/web/items/42/)\n",
+			"- **Level:** error\n",
+			"## Exception: ServiceError\n\n> could not load user\n",
+			"```\n/app/app/services/users.rb:12 in load()\n... 1 vendor frame not shown\n```\n",
+			"## Caused by: KeyError\n",
+			"_Created from Rollbar item #42 by rollbar-cli._\n",
+		}},
+		{"jira", []string{
+			"h2. Summary\n",
+			"* *Rollbar item:* [#42|https://rollbar.com/acme/web/items/42/]\n",
+			"h2. Exception: ServiceError\n\n{quote}\ncould not load user\n{quote}\n",
+			"{noformat}\n/app/app/services/users.rb:12 in load()\n",
+			"h2. Caused by: KeyError\n",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			ticket, err := NewTicket(item, []api.Instance{chainedInstance()}, TicketOptions{
+				Format: tt.format,
+				Link:   "https://rollbar.com/acme/web/items/42/",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ticket.Title != "[Rollbar #42] ServiceError: could not load user" {
+				t.Errorf("unexpected title %q", ticket.Title)
+			}
+			if got := strings.Join(ticket.Labels, ","); got != "rollbar,level:error,env:production" {
+				t.Errorf("unexpected labels %q", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(ticket.Body, want) {
+					t.Errorf("body missing %q:\n%s", want, ticket.Body)
+				}
+			}
+		})
+	}
+
+	t.Run("without link", func(t *testing.T) {
+		ticket, err := NewTicket(item, nil, TicketOptions{Format: "gitlab"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(ticket.Body, "- **Rollbar item:** #42\n") {
+			t.Errorf("expected an unlinked item reference:\n%s", ticket.Body)
+		}
+		if ticket.Title != "[Rollbar #42] Test Error" {
+			t.Errorf("expected the item title without occurrences, got %q", ticket.Title)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		if _, err := NewTicket(item, nil, TicketOptions{Format: "trello"}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestTicketPayload(t *testing.T) {
+	ticket := &Ticket{Title: "T", Body: "B", Labels: []string{"rollbar", "level:error"}}
+
+	tests := []struct {
+		opts TicketOptions
+		want string
+	}{
+		{TicketOptions{Format: "github"}, `{"body":"B","labels":["rollbar","level:error"],"title":"T"}`},
+		{TicketOptions{Format: "gitlab"}, `{"description":"B","labels":"rollbar,level:error","title":"T"}`},
+		{TicketOptions{Format: "jira", JiraProject: "WEB"},
+			`{"fields":{"description":"B","issuetype":{"name":"Bug"},"labels":["rollbar","level:error"],"project":{"key":"WEB"},"summary":"T"}}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(ticket.Payload(tt.opts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s payload:\n got %s\nwant %s", tt.opts.Format, got, tt.want)
+		}
+	}
+}
+
+func TestTicketEscapesPayload(t *testing.T) {
+	inst := api.Instance{Data: api.InstanceData{Body: api.Body{Trace: &api.Trace{
+		Exception: api.Exception{
+			Class:   "Evil\n## Injected",
+			Message: "hi @octocat see #123 and !45\n```\n</details>[click](https://evil.example)![x](https://evil.example/p.png)\n{quote}{noformat}[~admin]",
+		},
+		Frames: []api.Frame{{Filename: "/app/x```y{noformat}.rb", Lineno: 1, Method: "run"}},
+	}}}}
+
+	item := sampleItem()
+	item.Counter = 42
+
+	github, err := NewTicket(item, []api.Instance{inst}, TicketOptions{Format: "github"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"@octocat", "#123", "!45", "\n## Injected", "[click](", "![x]", "</details>"} {
+		if strings.Contains(github.Body, bad) {
+			t.Errorf("markdown body contains %q:\n%s", bad, github.Body)
+		}
+	}
+	if !strings.Contains(github.Body, "````\n/app/x```y{noformat}.rb:1 in run()\n") || !strings.Contains(github.Body, "\n````\n") {
+		t.Errorf("expected a fence longer than the backticks in the frame:\n%s", github.Body)
+	}
+
+	jira, err := NewTicket(item, []api.Instance{inst}, TicketOptions{Format: "jira"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(jira.Body, "{quote}"); n != 2 {
+		t.Errorf("expected only the quote's own tags, found %d:\n%s", n, jira.Body)
+	}
+	if n := strings.Count(jira.Body, "{noformat}"); n != 2 {
+		t.Errorf("expected only the block's own tags, found %d:\n%s", n, jira.Body)
+	}
+	for _, bad := range []string{"[~admin]", "[click]", "![x]"} {
+		if strings.Contains(jira.Body, bad) {
+			t.Errorf("jira body contains %q:\n%s", bad, jira.Body)
+		}
+	}
+}
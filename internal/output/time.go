@@ -0,0 +1,66 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat selects how times are shown in table, compact and markdown output
+type TimeFormat string
+
+// Time formats
+const (
+	TimeRelative TimeFormat = "relative" // "3 hours ago" in lists, RFC 3339 with it in details
+	TimeISO      TimeFormat = "iso"      // RFC 3339 with the zone offset
+	TimeUnix     TimeFormat = "unix"     // Seconds since the epoch
+)
+
+// TimeFormats are the accepted --time-format values
+var TimeFormats = []TimeFormat{TimeRelative, TimeISO, TimeUnix}
+
+// Time display settings, set once from the command line by SetTimeDisplay
+var (
+	timeLocation = time.Local
+	timeFormat   = TimeRelative
+)
+
+// SetTimeDisplay sets the zone absolute times are shown in and the time format
+// of human-readable output. JSON, CSV and other machine formats are always UTC.
+func SetTimeDisplay(loc *time.Location, format TimeFormat) error {
+	valid := false
+	for _, f := range TimeFormats {
+		valid = valid || f == format
+	}
+	if !valid {
+		names := make([]string, len(TimeFormats))
+		for i, f := range TimeFormats {
+			names[i] = string(f)
+		}
+		return fmt.Errorf("unknown time format %q (available: %s)", format, strings.Join(names, ", "))
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	timeLocation, timeFormat = loc, format
+	return nil
+}
+
+// FormatTime returns t as an absolute time in the display zone: RFC 3339, or
+// seconds since the epoch with --time-format unix
+func FormatTime(t time.Time) string {
+	if timeFormat == TimeUnix {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	return t.In(timeLocation).Format(time.RFC3339)
+}
+
+// formatTimeDetail returns t for detail views, with the relative time after it
+// unless an absolute format was asked for
+func formatTimeDetail(t time.Time) string {
+	if timeFormat != TimeRelative {
+		return FormatTime(t)
+	}
+	return fmt.Sprintf("%s (%s)", FormatTime(t), formatRelativeTime(t))
+}
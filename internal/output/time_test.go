@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func TestTimeDisplay(t *testing.T) {
+	defer func(loc *time.Location, f TimeFormat) { timeLocation, timeFormat = loc, f }(timeLocation, timeFormat)
+
+	seen := time.Date(2026, 3, 1, 14, 5, 0, 0, time.UTC)
+	items := []api.Item{{Counter: 7, Title: "boom", LevelString: "error", LastOccurrenceTime: seen, FirstOccurrenceTime: seen}}
+	tests := []struct {
+		loc    *time.Location
+		format TimeFormat
+		want   string
+	}{
+		{time.UTC, TimeISO, "2026-03-01T14:05:00Z"},
+		{time.FixedZone("EST", -5*3600), TimeISO, "2026-03-01T09:05:00-05:00"},
+		{time.UTC, TimeUnix, "1772373900"},
+		{time.UTC, TimeRelative, "ago"},
+	}
+	for _, tt := range tests {
+		if err := SetTimeDisplay(tt.loc, tt.format); err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range []Formatter{&TableFormatter{}, &CompactFormatter{}, &MarkdownFormatter{}} {
+			var buf bytes.Buffer
+			if err := f.FormatItems(&buf, items); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("%T with %s in %s: expected %q in\n%s", f, tt.format, tt.loc, tt.want, buf.String())
+			}
+		}
+	}
+
+	if err := SetTimeDisplay(time.UTC, "rfc2822"); err == nil {
+		t.Error("expected an error for an unknown time format")
+	}
+}
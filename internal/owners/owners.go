@@ -0,0 +1,234 @@
+// Package owners parses CODEOWNERS files (GitHub and GitLab syntax) and
+// matches repository paths against them.
+package owners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are the places CODEOWNERS is looked for, relative to the repository root
+var Locations = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// Rule is a single CODEOWNERS line
+type Rule struct {
+	Pattern string   `json:"pattern"`
+	Owners  []string `json:"owners"`
+	Section string   `json:"section,omitempty"` // GitLab section, empty for GitHub files
+	Line    int      `json:"line"`
+
+	re *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	Path  string
+	Rules []Rule
+}
+
+// Match is the result of matching a path against a CODEOWNERS file
+type Match struct {
+	Owners []string `json:"owners"`
+	Rules  []Rule   `json:"rules"` // The rule that applied in each section
+}
+
+// Find returns the path of the CODEOWNERS file under root, if any
+func Find(root string) (string, bool) {
+	for _, loc := range Locations {
+		path := filepath.Join(root, loc)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Load reads and parses a CODEOWNERS file
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	file.Path = path
+	return file, nil
+}
+
+// sectionHeader matches GitLab section headers: [Name], ^[Optional], [Name][2] @default
+var sectionHeader = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Parse parses CODEOWNERS content. Rules inside a GitLab section without
+// owners of their own inherit the section's default owners.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+	section := ""
+	var defaults []string
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if m := sectionHeader.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			defaults = fields(stripComment(m[2]))
+			continue
+		}
+
+		parts := fields(stripComment(line))
+		if len(parts) == 0 {
+			continue
+		}
+		rule := Rule{Pattern: parts[0], Owners: parts[1:], Section: section, Line: lineNo}
+		if len(rule.Owners) == 0 && section != "" {
+			rule.Owners = defaults
+		}
+		re, err := compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		rule.re = re
+		file.Rules = append(file.Rules, rule)
+	}
+	return file, scanner.Err()
+}
+
+// stripComment removes a trailing comment; "\#" is a literal hash
+func stripComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] != '\\') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// fields splits on whitespace, keeping "\ " escaped spaces inside a field
+func fields(s string) []string {
+	var out []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && (s[i+1] == ' ' || s[i+1] == '#'):
+			cur.WriteByte(s[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+// compile converts a gitignore-style CODEOWNERS pattern into a regexp over
+// slash-separated paths relative to the repository root. Patterns with a
+// leading or inner slash are anchored to the root; others match at any depth.
+// A pattern matches a path or anything beneath it, except that a wildcard in
+// the last segment matches only at that level; a trailing slash matches only
+// directory contents.
+func compile(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.HasPrefix(p, "/") || strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if i+1 < len(p) && p[i+1] == '*' {
+				i++
+				if i+1 < len(p) && p[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	lastSegment := p[strings.LastIndex(p, "/")+1:]
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case lastSegment != "**" && strings.ContainsAny(lastSegment, "*?"):
+		// "docs/*" owns the files in docs, not those in its subdirectories
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// Match finds the owners of path (relative to the repository root). The last
+// matching rule wins within each section; owners from all sections are
+// combined, as GitLab does. A GitHub file has a single section.
+func (f *File) Match(path string) Match {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+	path = strings.TrimPrefix(path, "/")
+
+	var order []string
+	last := map[string]Rule{}
+	for _, rule := range f.Rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if _, ok := last[rule.Section]; !ok {
+			order = append(order, rule.Section)
+		}
+		last[rule.Section] = rule
+	}
+
+	m := Match{Owners: []string{}, Rules: []Rule{}}
+	seen := map[string]bool{}
+	for _, section := range order {
+		rule := last[section]
+		m.Rules = append(m.Rules, rule)
+		for _, owner := range rule.Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				m.Owners = append(m.Owners, owner)
+			}
+		}
+	}
+	return m
+}
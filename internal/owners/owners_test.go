@@ -0,0 +1,129 @@
+package owners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchGitHub(t *testing.T) {
+	file, err := Parse(strings.NewReader(`
+# Default owners
+*                       @acme/platform
+
+*.js                    @acme/frontend
+/app/models/            @acme/data   # models
+/app/models/billing/    @acme/billing @alice
+docs/*                  @acme/docs
+apps/                   @acme/apps
+/lib/**/tasks           @acme/ops
+/vendored/
+/path\ with\ space/     @acme/spaces
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"README.md", "@acme/platform"},
+		{"web/src/index.js", "@acme/frontend"},
+		{"app/models/user.rb", "@acme/data"},
+		{"./app/models/user.rb", "@acme/data"},
+		{"app/models/billing/invoice.rb", "@acme/billing,@alice"},
+		{"other/app/models/user.rb", "@acme/platform"},
+		{"docs/guide.md", "@acme/docs"},
+		{"docs/api/reference.md", "@acme/platform"},
+		{"services/apps/main.go", "@acme/apps"},
+		{"lib/a/b/tasks/cleanup.rb", "@acme/ops"},
+		{"lib/tasks/cleanup.rb", "@acme/ops"},
+		{"vendored/lib.rb", ""},
+		{"path with space/file.rb", "@acme/spaces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m := file.Match(tt.path)
+			if got := strings.Join(m.Owners, ","); got != tt.want {
+				t.Errorf("Match(%q) owners = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	m := file.Match("app/models/user.rb")
+	if len(m.Rules) != 1 || m.Rules[0].Pattern != "/app/models/" || m.Rules[0].Line != 6 {
+		t.Errorf("unexpected rules: %+v", m.Rules)
+	}
+}
+
+func TestMatchGitLabSections(t *testing.T) {
+	file, err := Parse(strings.NewReader(`
+[Backend] @acme/backend
+app/
+app/models/billing/ @acme/billing
+
+^[Security][2] @acme/security
+app/controllers/sessions_controller.rb
+
+[Docs]
+*.md @acme/docs
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"app/models/user.rb", "@acme/backend"},
+		{"app/models/billing/invoice.rb", "@acme/billing"},
+		{"app/controllers/sessions_controller.rb", "@acme/backend,@acme/security"},
+		{"app/README.md", "@acme/backend,@acme/docs"},
+		{"lib/util.rb", ""},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(file.Match(tt.path).Owners, ","); got != tt.want {
+			t.Errorf("Match(%q) owners = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	m := file.Match("app/controllers/sessions_controller.rb")
+	if len(m.Rules) != 2 || m.Rules[1].Section != "Security" {
+		t.Errorf("unexpected rules: %+v", m.Rules)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	if _, ok := Find(root); ok {
+		t.Fatal("expected no CODEOWNERS in an empty directory")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "CODEOWNERS"), []byte("* @root\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".github", "CODEOWNERS"), []byte("* @github\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok := Find(root)
+	if !ok || path != filepath.Join(root, ".github", "CODEOWNERS") {
+		t.Errorf("expected .github/CODEOWNERS to take precedence, got %s", path)
+	}
+
+	file, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := strings.Join(file.Match("main.go").Owners, ","); got != "@github" {
+		t.Errorf("unexpected owners %q", got)
+	}
+}
@@ -0,0 +1,220 @@
+// Package redact masks secrets and personal data in occurrence request and
+// custom data before it is displayed.
+package redact
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// DefaultKeys are the key patterns masked by default. A key matches when it
+// contains a pattern, ignoring case, dashes and underscores, so "cookie" also
+// covers Set-Cookie and "token" covers access_token and X-CSRF-Token.
+var DefaultKeys = []string{
+	"authorization", "cookie", "session",
+	"token", "secret", "apikey", "password", "passwd", "pwd",
+	"card", "ccnum", "cvv", "cvc",
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+)
+
+// Policy decides which values are masked
+type Policy struct {
+	Keys  []string // Key patterns whose values are masked
+	Allow []string // Keys shown even when they match a pattern (exact, ignoring case)
+}
+
+// Default returns the default policy extended with extra key patterns and
+// allowed keys
+func Default(extraKeys, allow []string) *Policy {
+	return &Policy{
+		Keys:  append(append([]string(nil), DefaultKeys...), extraKeys...),
+		Allow: allow,
+	}
+}
+
+// normalizeKey lowercases a key and drops separators
+func normalizeKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "", ".", "").Replace(strings.ToLower(key))
+}
+
+// MatchKey reports whether values under key are masked
+func (p *Policy) MatchKey(key string) bool {
+	for _, allowed := range p.Allow {
+		if strings.EqualFold(allowed, key) {
+			return false
+		}
+	}
+	k := normalizeKey(key)
+	for _, pattern := range p.Keys {
+		if pattern = normalizeKey(pattern); pattern != "" && strings.Contains(k, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// String masks emails, card numbers, bearer credentials and JWTs inside s
+func (p *Policy) String(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Mask)
+	s = jwtPattern.ReplaceAllString(s, Mask)
+	s = emailPattern.ReplaceAllString(s, Mask)
+	return cardPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if luhn(m) {
+			return Mask
+		}
+		return m
+	})
+}
+
+// Value masks a decoded JSON value, recursing into objects and arrays
+func (p *Policy) Value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return p.Map(v)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = p.Value(child)
+		}
+		return out
+	case string:
+		return p.String(v)
+	}
+	return v
+}
+
+// Map returns a copy of m with matching keys and sensitive values masked
+func (p *Policy) Map(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if p.MatchKey(k) {
+			out[k] = Mask
+		} else {
+			out[k] = p.Value(v)
+		}
+	}
+	return out
+}
+
+// Headers returns a copy of headers with matching names and sensitive values masked
+func (p *Policy) Headers(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if p.MatchKey(k) {
+			out[k] = Mask
+		} else {
+			out[k] = p.String(v)
+		}
+	}
+	return out
+}
+
+// Query masks a URL query string ("a=1&b=2"), keeping the order of its pairs
+func (p *Policy) Query(query string) string {
+	if query == "" {
+		return ""
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		k, v, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			key = k
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			value = v
+		}
+		switch {
+		case p.MatchKey(key):
+			pairs[i] = k + "=" + Mask
+		case p.String(value) != value:
+			pairs[i] = k + "=" + p.String(value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// URL masks the query string of a URL
+func (p *Policy) URL(rawURL string) string {
+	base, query, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return p.String(rawURL)
+	}
+	fragment := ""
+	if i := strings.Index(query, "#"); i >= 0 {
+		query, fragment = query[:i], query[i:]
+	}
+	return p.String(base) + "?" + p.Query(query) + fragment
+}
+
+// Body masks a request body, treating it as JSON or a form when it parses as one
+func (p *Policy) Body(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var v interface{}
+		if err := json.Unmarshal([]byte(trimmed), &v); err == nil {
+			if b, err := json.Marshal(p.Value(v)); err == nil {
+				return string(b)
+			}
+		}
+	}
+	if strings.Contains(trimmed, "=") && !strings.ContainsAny(trimmed, " \n") {
+		return p.Query(trimmed)
+	}
+	return p.String(body)
+}
+
+// Instance masks an occurrence's request and custom data in place
+func (p *Policy) Instance(inst *api.Instance) {
+	if req := inst.Data.Request; req != nil {
+		req.URL = p.URL(req.URL)
+		req.Headers = p.Headers(req.Headers)
+		req.Params = p.Map(req.Params)
+		req.GET = p.Map(req.GET)
+		req.POST = p.Map(req.POST)
+		req.Body = p.Body(req.Body)
+		req.QueryString = p.Query(req.QueryString)
+	}
+	inst.Data.Custom = p.Map(inst.Data.Custom)
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+// Sparkline size in pixels
+const (
+	sparkWidth  = 120
+	sparkHeight = 24
+)
+
+// Sparkline draws counts as an inline SVG polyline
+func Sparkline(counts []int) template.HTML {
+	peak := 0
+	for _, c := range counts {
+		if c > peak {
+			peak = c
+		}
+	}
+	points := make([]string, len(counts))
+	for i, c := range counts {
+		x := 0.0
+		if len(counts) > 1 {
+			x = float64(i) * sparkWidth / float64(len(counts)-1)
+		}
+		y := float64(sparkHeight - 1)
+		if peak > 0 {
+			y = 1 + float64(sparkHeight-2)*(1-float64(c)/float64(peak))
+		}
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+	return template.HTML(fmt.Sprintf(
+		`<svg class="spark" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="occurrences over time"><polyline fill="none" stroke="currentColor" stroke-width="1.5" points="%s"/></svg>`,
+		sparkWidth, sparkHeight, sparkWidth, sparkHeight, strings.Join(points, " ")))
+}
+
+var funcs = template.FuncMap{
+	"sparkline": Sparkline,
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+	"isApp": output.IsAppFrame,
+	// share is a percentage of total, for breakdown bars
+	"share": func(n, total int) string {
+		if total == 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%.1f", 100*float64(n)/float64(total))
+	},
+	"breakdown": func(rows []Breakdown, total int) breakdownTable {
+		return breakdownTable{Rows: rows, Total: total}
+	},
+}
+
+// breakdownTable is the data of the breakdown template
+type breakdownTable struct {
+	Rows  []Breakdown
+	Total int
+}
+
+var page = template.Must(template.New("report").Funcs(funcs).Parse(pageTemplate))
+
+// WriteHTML renders the report as a single HTML page with no external assets
+func WriteHTML(w io.Writer, r *Report) error {
+	return page.Execute(w, r)
+}
+
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 14px/1.45 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 1100px; margin: 2em auto; padding: 0 1em; }
+h1 { margin-bottom: 0.2em; }
+.muted { color: #656d76; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; margin: 1.5em 0; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: 0.8em 1.2em; min-width: 9em; }
+.card .n { font-size: 1.8em; font-weight: 600; }
+.breakdowns { display: flex; gap: 2em; flex-wrap: wrap; }
+.breakdowns > div { flex: 1; min-width: 300px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.35em 0.6em; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+td.num, th.num { text-align: right; font-variant-numeric: tabular-nums; }
+.bar { background: #ddf4ff; height: 0.8em; border-radius: 2px; }
+.level { display: inline-block; padding: 0 0.5em; border-radius: 1em; font-size: 0.85em; background: #eaeef2; }
+.level-critical { background: #ffebe9; color: #82071e; }
+.level-error { background: #fff1e5; color: #953800; }
+.level-warning { background: #fff8c5; color: #7d4e00; }
+.new { font-size: 0.8em; color: #1a7f37; font-weight: 600; }
+.spark { color: #0969da; vertical-align: middle; }
+details { margin: 0.3em 0; }
+summary { cursor: pointer; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; font-size: 12px; }
+pre .app { font-weight: 600; }
+pre .vendor { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">{{date .Since}} to {{date .Until}}{{if .Environment}} &middot; environment {{.Environment}}{{end}}</p>
+
+<div class="cards">
+  <div class="card"><div class="n">{{.Items}}</div>items with occurrences</div>
+  <div class="card"><div class="n">{{.Occurrences}}{{if .Partial}}+{{end}}</div>occurrences in window</div>
+  <div class="card"><div class="n">{{.New}}</div>new</div>
+  <div class="card"><div class="n">{{.Resolved}}</div>resolved</div>
+</div>
+
+<div class="breakdowns">
+  <div>
+    <h2>By level</h2>
+    {{template "breakdown" (breakdown .Levels .Occurrences)}}
+  </div>
+  <div>
+    <h2>By environment</h2>
+    {{template "breakdown" (breakdown .Envs .Occurrences)}}
+  </div>
+</div>
+
+<h2>Top items by occurrences</h2>
+{{- if not .Top}}
+<p class="muted">No items had occurrences in this window.</p>
+{{- else}}
+<table>
+<tr><th class="num">#</th><th>Item</th><th>Level</th><th>Environment</th><th class="num">In window</th><th class="num">Lifetime</th><th>Recent</th><th>Last seen</th></tr>
+{{- range .Top}}
+<tr>
+  <td class="num">{{.Item.Counter}}</td>
+  <td>
+    {{.Item.Title}}{{if .New}} <span class="new">NEW</span>{{end}}
+    {{- if .Traces}}
+    <details>
+      <summary>Stack trace</summary>
+      {{- range $i, $t := .Traces}}
+      <pre><strong>{{if $i}}Caused by {{end}}{{.Exception.Class}}: {{.Exception.Message}}</strong>
+{{range .Frames}}<span class="{{if isApp .}}app{{else}}vendor{{end}}">  {{.Filename}}:{{.Lineno}}{{if .Method}} in {{.Method}}{{end}}</span>
+{{end}}</pre>
+      {{- end}}
+    </details>
+    {{- else if .Message}}
+    <details><summary>Message</summary><pre>{{.Message}}</pre></details>
+    {{- end}}
+  </td>
+  <td><span class="level level-{{.Level}}">{{.Level}}</span></td>
+  <td>{{.Item.Environment}}</td>
+  <td class="num">{{.Occurrences}}{{if .Partial}}+{{end}}</td>
+  <td class="num">{{.Item.TotalOccurrences}}</td>
+  <td>{{sparkline .Recent}}</td>
+  <td>{{date .Item.LastOccurrenceTime}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+
+<p class="muted">Generated by rollbar-cli. Sparklines plot each item's most recent occurrences across the window.</p>
+</body>
+</html>
+{{define "breakdown"}}
+<table>
+<tr><th>Name</th><th class="num">Items</th><th class="num">Occurrences</th><th></th></tr>
+{{- range .Rows}}
+<tr><td>{{.Name}}</td><td class="num">{{.Items}}</td><td class="num">{{.Occurrences}}</td><td style="width:40%"><div class="bar" style="width: {{share .Occurrences $.Total}}%"></div></td></tr>
+{{- end}}
+</table>
+{{end}}
+`
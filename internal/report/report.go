@@ -0,0 +1,207 @@
+// Package report summarizes the items and occurrences of a time window for
+// reliability reviews and renders the summary as a self-contained HTML page.
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// DefaultBuckets is the number of points in each sparkline
+const DefaultBuckets = 24
+
+// Entry is an item with its most recent occurrences, newest first, back to
+// the start of the window
+type Entry struct {
+	Item      *api.Item
+	Instances []api.Instance
+	Truncated bool // Instances stop inside the window, so its counts are lower bounds
+}
+
+// Options describe the report window
+type Options struct {
+	Title       string
+	Environment string // Environment filter, for the heading
+	Since       time.Time
+	Until       time.Time
+	Top         int // Items listed in detail (0 = all)
+	Buckets     int // Sparkline points (0 = DefaultBuckets)
+}
+
+// Report is the summary of a window
+type Report struct {
+	Title       string
+	Environment string
+	Since       time.Time
+	Until       time.Time
+	Items       int  // Items with occurrences in the window
+	Occurrences int  // Their occurrences in the window
+	Partial     bool // Some counts are lower bounds
+	New         int  // Items first seen in the window
+	Resolved    int  // Items seen in the window that are now resolved
+	Levels      []Breakdown
+	Envs        []Breakdown
+	Top         []TopItem
+}
+
+// Breakdown counts the items and occurrences in the window sharing a level or
+// environment
+type Breakdown struct {
+	Name        string
+	Items       int
+	Occurrences int
+}
+
+// TopItem is an item listed in detail
+type TopItem struct {
+	Item        *api.Item
+	Level       string
+	New         bool
+	Recent      []int // Occurrences per bucket of the window, oldest first
+	Occurrences int   // Occurrences in the window
+	Partial     bool  // Occurrences is a lower bound
+	Class       string
+	Message     string
+	Traces      []api.Trace // Latest occurrence's traces, outermost first
+	Occurred    time.Time   // Latest occurrence
+}
+
+// Build summarizes entries. Occurrences are counted inside the window from
+// each entry's instances, not from the items' lifetime totals, and items are
+// ranked by that count.
+func Build(entries []Entry, opts Options) *Report {
+	if opts.Buckets <= 0 {
+		opts.Buckets = DefaultBuckets
+	}
+	r := &Report{
+		Title:       opts.Title,
+		Environment: opts.Environment,
+		Since:       opts.Since,
+		Until:       opts.Until,
+	}
+
+	levels := map[string]*Breakdown{}
+	envs := map[string]*Breakdown{}
+	count := func(m map[string]*Breakdown, name string, occ int) {
+		b, ok := m[name]
+		if !ok {
+			b = &Breakdown{Name: name}
+			m[name] = b
+		}
+		b.Items++
+		b.Occurrences += occ
+	}
+
+	var ranked []Entry
+	counts := map[*api.Item]int{}
+	for _, e := range entries {
+		item := e.Item
+		_, n := bucket(e.Instances, opts.Since, opts.Until, opts.Buckets)
+		if n == 0 {
+			continue
+		}
+		counts[item] = n
+		ranked = append(ranked, e)
+		r.Items++
+		r.Occurrences += n
+		r.Partial = r.Partial || e.Truncated
+		if isNew(item, opts.Since) {
+			r.New++
+		}
+		if item.Status == "resolved" {
+			r.Resolved++
+		}
+		count(levels, levelName(item), n)
+		env := item.Environment
+		if env == "" {
+			env = "(none)"
+		}
+		count(envs, env, n)
+	}
+	r.Levels = sortBreakdowns(levels)
+	r.Envs = sortBreakdowns(envs)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Item, ranked[j].Item
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a.TotalOccurrences > b.TotalOccurrences
+	})
+	if opts.Top > 0 && len(ranked) > opts.Top {
+		ranked = ranked[:opts.Top]
+	}
+	for _, e := range ranked {
+		r.Top = append(r.Top, newTopItem(e, opts))
+	}
+	return r
+}
+
+func newTopItem(e Entry, opts Options) TopItem {
+	t := TopItem{
+		Item:  e.Item,
+		Level: levelName(e.Item),
+		New:   isNew(e.Item, opts.Since),
+	}
+	t.Recent, t.Occurrences = bucket(e.Instances, opts.Since, opts.Until, opts.Buckets)
+	t.Partial = e.Truncated
+	if len(e.Instances) > 0 {
+		latest := &e.Instances[0]
+		t.Occurred = latest.Time
+		t.Traces = latest.Data.Body.Traces()
+		if len(t.Traces) > 0 {
+			t.Class = t.Traces[0].Exception.Class
+			t.Message = t.Traces[0].Exception.Message
+		} else if latest.Data.Body.Message != nil {
+			t.Message = latest.Data.Body.Message.Body
+		}
+	}
+	return t
+}
+
+// bucket counts the occurrences in each of n equal slices of [since, until)
+func bucket(instances []api.Instance, since, until time.Time, n int) ([]int, int) {
+	counts := make([]int, n)
+	span := until.Sub(since)
+	if span <= 0 {
+		return counts, 0
+	}
+	sampled := 0
+	for _, inst := range instances {
+		if inst.Time.Before(since) || !inst.Time.Before(until) {
+			continue
+		}
+		i := int(int64(inst.Time.Sub(since)) * int64(n) / int64(span))
+		counts[i]++
+		sampled++
+	}
+	return counts, sampled
+}
+
+func isNew(item *api.Item, since time.Time) bool {
+	return !item.FirstOccurrenceTime.IsZero() && !item.FirstOccurrenceTime.Before(since)
+}
+
+func levelName(item *api.Item) string {
+	if item.LevelString != "" {
+		return item.LevelString
+	}
+	return api.LevelToString(item.Level.Int())
+}
+
+// sortBreakdowns orders breakdowns by occurrences, then name
+func sortBreakdowns(m map[string]*Breakdown) []Breakdown {
+	out := make([]Breakdown, 0, len(m))
+	for _, b := range m {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Occurrences != out[j].Occurrences {
+			return out[i].Occurrences > out[j].Occurrences
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
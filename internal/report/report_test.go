@@ -0,0 +1,128 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+var (
+	since = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	until = since.Add(24 * time.Hour)
+)
+
+func entries() []Entry {
+	return []Entry{
+		{
+			Item:      &api.Item{Counter: 1, Title: "Timeout", LevelString: "warning", Status: "resolved", Environment: "production", TotalOccurrences: 5, FirstOccurrenceTime: since.Add(-48 * time.Hour)},
+			Instances: []api.Instance{{Time: since.Add(time.Hour)}},
+		},
+		{
+			Item: &api.Item{Counter: 2, Title: "NoMethodError: undefined method <name>", LevelString: "error", Status: "active", Environment: "production", TotalOccurrences: 30, FirstOccurrenceTime: since.Add(time.Hour)},
+			Instances: []api.Instance{
+				{Time: since.Add(23 * time.Hour), Data: api.InstanceData{Body: api.Body{TraceChain: []api.Trace{
+					{Exception: api.Exception{Class: "NoMethodError", Message: "undefined method <name>"}, Frames: []api.Frame{{Filename: "/app/app/models/user.rb", Lineno: 42, Method: "name"}}},
+					{Exception: api.Exception{Class: "KeyError", Message: "key not found"}},
+				}}}},
+				{Time: since.Add(22*time.Hour + 30*time.Minute)},
+				{Time: since.Add(2 * time.Hour)},
+				{Time: since.Add(-time.Hour)}, // Before the window
+			},
+		},
+		{
+			Item:      &api.Item{Counter: 3, Title: "Cache miss", LevelString: "info", Status: "active", Environment: "staging", TotalOccurrences: 12, FirstOccurrenceTime: since.Add(2 * time.Hour)},
+			Instances: []api.Instance{{Time: since.Add(5 * time.Hour)}, {Time: since.Add(3 * time.Hour)}},
+			Truncated: true,
+		},
+		{
+			// A large lifetime backlog, but quiet in the window
+			Item:      &api.Item{Counter: 4, Title: "Old backlog", LevelString: "error", Status: "active", Environment: "production", TotalOccurrences: 10000, FirstOccurrenceTime: since.Add(-90 * 24 * time.Hour)},
+			Instances: []api.Instance{{Time: since.Add(-2 * time.Hour)}},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := Build(entries(), Options{Title: "Weekly", Since: since, Until: until, Top: 2})
+
+	if r.Items != 3 || r.Occurrences != 6 || r.New != 2 || r.Resolved != 1 || !r.Partial {
+		t.Errorf("unexpected totals: items %d, occurrences %d, new %d, resolved %d, partial %v", r.Items, r.Occurrences, r.New, r.Resolved, r.Partial)
+	}
+
+	wantLevels := []Breakdown{{"error", 1, 3}, {"info", 1, 2}, {"warning", 1, 1}}
+	if len(r.Levels) != len(wantLevels) {
+		t.Fatalf("levels = %+v", r.Levels)
+	}
+	for i, want := range wantLevels {
+		if r.Levels[i] != want {
+			t.Errorf("level %d = %+v, want %+v", i, r.Levels[i], want)
+		}
+	}
+	if len(r.Envs) != 2 || r.Envs[0] != (Breakdown{"production", 2, 4}) {
+		t.Errorf("environments = %+v", r.Envs)
+	}
+
+	if len(r.Top) != 2 || r.Top[0].Item.Counter != 2 || r.Top[1].Item.Counter != 3 {
+		t.Fatalf("unexpected top items: %+v", r.Top)
+	}
+	top := r.Top[0]
+	if !top.New || top.Class != "NoMethodError" || len(top.Traces) != 2 {
+		t.Errorf("unexpected top item: %+v", top)
+	}
+	if top.Occurrences != 3 || len(top.Recent) != DefaultBuckets {
+		t.Fatalf("%d occurrences in %d buckets", top.Occurrences, len(top.Recent))
+	}
+	if second := r.Top[1]; second.Occurrences != 2 || !second.Partial {
+		t.Errorf("expected a lower bound of 2 for #3, got %d (partial %v)", second.Occurrences, second.Partial)
+	}
+	if top.Recent[2] != 1 || top.Recent[22] != 1 || top.Recent[23] != 1 {
+		t.Errorf("unexpected buckets %v", top.Recent)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	got := string(Sparkline([]int{0, 2, 1}))
+	if !strings.Contains(got, `points="0.0,23.0 60.0,1.0 120.0,12.0"`) {
+		t.Errorf("unexpected sparkline %s", got)
+	}
+	// A window without occurrences is a flat line
+	if got := string(Sparkline([]int{0, 0})); !strings.Contains(got, `points="0.0,23.0 120.0,23.0"`) {
+		t.Errorf("unexpected empty sparkline %s", got)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	r := Build(entries(), Options{Title: "Weekly <review>", Environment: "production", Since: since, Until: until})
+	if err := WriteHTML(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Weekly &lt;review&gt;</title>",
+		"undefined method &lt;name&gt;",
+		"<details>",
+		"Caused by KeyError: key not found",
+		`<span class="app">  /app/app/models/user.rb:42 in name</span>`,
+		"<svg",
+		`style="width: 50.0%"`,
+		`<div class="n">6+</div>occurrences in window`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(out, "Old backlog") {
+		t.Error("an item without occurrences in the window was listed")
+	}
+	// Self-contained: no scripts, stylesheets or images fetched
+	for _, bad := range []string{"<script", "<link", "src="} {
+		if strings.Contains(out, bad) {
+			t.Errorf("report references an external asset: %q", bad)
+		}
+	}
+}
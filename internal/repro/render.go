@@ -0,0 +1,212 @@
+package repro
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Render writes the reproduction in the given format
+func Render(r *Request, format string) (string, error) {
+	switch format {
+	case "curl":
+		return renderCurl(r), nil
+	case "httpie":
+		return renderHTTPie(r), nil
+	case "go-test":
+		return renderGoTest(r)
+	case "rspec":
+		return renderRSpec(r), nil
+	}
+	return "", fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// header returns the comment lines describing the reproduction
+func (r *Request) header(prefix string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s Reproduces Rollbar occurrence %d", prefix, r.Occurrence)
+	if title, _, _ := strings.Cut(r.Title, "\n"); title != "" {
+		fmt.Fprintf(&b, ": %s", title)
+	}
+	b.WriteString("\n")
+	if len(r.Env) > 0 {
+		fmt.Fprintf(&b, "%s Redacted values are read from the environment: %s\n", prefix, strings.Join(r.Env, ", "))
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s for POSIX shells, expanding placeholders as
+// double-quoted environment variables
+func shellQuote(s string) string {
+	var b strings.Builder
+	for i, seg := range segments(s) {
+		switch {
+		case i%2 == 1:
+			b.WriteString(`"$` + seg + `"`)
+		case seg != "":
+			b.WriteString("'" + strings.ReplaceAll(seg, "'", `'\''`) + "'")
+		}
+	}
+	if b.Len() == 0 {
+		return "''"
+	}
+	return b.String()
+}
+
+func renderCurl(r *Request) string {
+	var b strings.Builder
+	b.WriteString(r.header("#"))
+	b.WriteString("curl")
+	if r.Method != "GET" {
+		b.WriteString(" -X " + shellQuote(r.Method))
+	}
+	b.WriteString(" " + shellQuote(r.URL))
+	for _, h := range r.Headers {
+		b.WriteString(" \\\n  -H " + shellQuote(h[0]+": "+h[1]))
+	}
+	if r.Body != "" {
+		b.WriteString(" \\\n  --data-raw " + shellQuote(r.Body))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func renderHTTPie(r *Request) string {
+	var b strings.Builder
+	b.WriteString(r.header("#"))
+	b.WriteString("http " + shellQuote(r.Method) + " " + shellQuote(r.URL))
+	for _, h := range r.Headers {
+		b.WriteString(" \\\n  " + shellQuote(h[0]+":"+h[1]))
+	}
+	if r.Body != "" {
+		b.WriteString(" \\\n  --raw " + shellQuote(r.Body))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// goExpr renders s as a Go string expression, reading placeholders with os.Getenv
+func goExpr(s string, usesEnv *bool) string {
+	var parts []string
+	for i, seg := range segments(s) {
+		switch {
+		case i%2 == 1:
+			parts = append(parts, fmt.Sprintf("os.Getenv(%q)", seg))
+			*usesEnv = true
+		case seg != "":
+			parts = append(parts, strconv.Quote(seg))
+		}
+	}
+	if len(parts) == 0 {
+		return `""`
+	}
+	return strings.Join(parts, " + ")
+}
+
+func renderGoTest(r *Request) (string, error) {
+	usesEnv := false
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// TestReproOccurrence%d replays the request that failed.\n", r.Occurrence)
+	fmt.Fprintf(&body, "func TestReproOccurrence%d(t *testing.T) {\n", r.Occurrence)
+	reqBody := "nil"
+	if r.Body != "" {
+		reqBody = "strings.NewReader(" + goExpr(r.Body, &usesEnv) + ")"
+	}
+	fmt.Fprintf(&body, "req, err := http.NewRequest(%q, %s, %s)\n", r.Method, goExpr(r.URL, &usesEnv), reqBody)
+	body.WriteString("if err != nil {\nt.Fatal(err)\n}\n")
+	for _, h := range r.Headers {
+		fmt.Fprintf(&body, "req.Header.Set(%q, %s)\n", h[0], goExpr(h[1], &usesEnv))
+	}
+	body.WriteString(`
+resp, err := http.DefaultClient.Do(req)
+if err != nil {
+t.Fatal(err)
+}
+defer resp.Body.Close()
+if resp.StatusCode >= 500 {
+t.Fatalf("%s %s: %s", req.Method, req.URL, resp.Status)
+}
+}
+`)
+
+	var src bytes.Buffer
+	src.WriteString(r.header("//"))
+	fmt.Fprintf(&src, "// Save as repro_test.go and run: go test -run TestReproOccurrence%d\n", r.Occurrence)
+	src.WriteString("package repro\n\nimport (\n\"net/http\"\n")
+	if usesEnv {
+		src.WriteString("\"os\"\n")
+	}
+	if r.Body != "" {
+		src.WriteString("\"strings\"\n")
+	}
+	src.WriteString("\"testing\"\n)\n\n")
+	src.Write(body.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("generating Go test: %w", err)
+	}
+	return string(out), nil
+}
+
+// rubyExpr renders s as a Ruby string expression, reading placeholders with ENV.fetch
+func rubyExpr(s string) string {
+	segs := segments(s)
+	if len(segs) == 3 && segs[0] == "" && segs[2] == "" {
+		return fmt.Sprintf("ENV.fetch(%q)", segs[1])
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, seg := range segs {
+		if i%2 == 1 {
+			fmt.Fprintf(&b, "#{ENV.fetch(%q)}", seg)
+			continue
+		}
+		b.WriteString(strings.NewReplacer(
+			`\`, `\\`, `"`, `\"`, "#", `\#`, "\n", `\n`, "\r", `\r`, "\t", `\t`,
+		).Replace(seg))
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// rspecMethods are the request spec helpers; other methods use process
+var rspecMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true}
+
+func renderRSpec(r *Request) string {
+	// Request specs run against the app in-process, so use the path unless
+	// the request was pointed at a server with --base-url
+	target := r.URL
+	if !r.Rebased {
+		if u, err := url.Parse(r.URL); err == nil {
+			target = u.RequestURI()
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(r.header("#"))
+	b.WriteString("require \"rails_helper\"\n\n")
+	fmt.Fprintf(&b, "RSpec.describe \"Rollbar occurrence %d\", type: :request do\n", r.Occurrence)
+	b.WriteString("  it \"does not return a server error\" do\n")
+	if rspecMethods[r.Method] {
+		fmt.Fprintf(&b, "    %s %s", strings.ToLower(r.Method), rubyExpr(target))
+	} else {
+		fmt.Fprintf(&b, "    process %s, %s", rubyExpr(strings.ToLower(r.Method)), rubyExpr(target))
+	}
+	if r.Body != "" {
+		fmt.Fprintf(&b, ",\n      params: %s", rubyExpr(r.Body))
+	}
+	if len(r.Headers) > 0 {
+		b.WriteString(",\n      headers: {\n")
+		for _, h := range r.Headers {
+			fmt.Fprintf(&b, "        %s => %s,\n", rubyExpr(h[0]), rubyExpr(h[1]))
+		}
+		b.WriteString("      }")
+	}
+	b.WriteString("\n\n    expect(response).not_to have_http_status(:server_error)\n")
+	b.WriteString("  end\nend\n")
+	return b.String()
+}
@@ -0,0 +1,280 @@
+// Package repro turns an occurrence's HTTP request into a ready-to-run
+// reproduction: a curl or HTTPie command, a Go test or an RSpec request spec.
+package repro
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/redact"
+)
+
+// Formats are the supported output formats
+var Formats = []string{"curl", "httpie", "go-test", "rspec"}
+
+// placeholderMark delimits an environment variable name inside a value. A
+// private-use rune is never escaped by encoding/json and won't appear in
+// real request data.
+const placeholderMark = "\uE000"
+
+// methodPattern matches the HTTP methods a reproduction is written for
+var methodPattern = regexp.MustCompile(`^[A-Z][A-Z0-9-]*$`)
+
+// skippedHeaders are recomputed by the client or tied to the original connection
+var skippedHeaders = map[string]bool{
+	"content-length":    true,
+	"connection":        true,
+	"host":              true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// Request is the reproducible part of an occurrence's request. Header and
+// body values may contain placeholders for redacted values.
+type Request struct {
+	Method  string
+	URL     string
+	Headers [][2]string // Sorted by name
+	Body    string
+
+	Occurrence int64
+	Title      string   // Exception headline, for comments
+	Env        []string // Environment variables that replace redacted values
+	Rebased    bool     // URL was pointed at Options.BaseURL
+}
+
+// Options control how a Request is built
+type Options struct {
+	BaseURL string // Replaces the scheme and host of the request URL
+}
+
+// FromInstance builds a Request from an occurrence. Values masked by the
+// redact package become environment variable placeholders named after their
+// key, e.g. a redacted Authorization header reads $AUTHORIZATION.
+func FromInstance(inst *api.Instance, opts Options) (*Request, error) {
+	req := inst.Data.Request
+	if req == nil || req.URL == "" {
+		return nil, fmt.Errorf("occurrence %d has no request data", inst.ID)
+	}
+
+	r := &Request{Method: strings.ToUpper(req.Method), Occurrence: inst.ID}
+	if r.Method == "" {
+		r.Method = "GET"
+	}
+	// The method is written into shell and Ruby code, so it must be a plain token
+	if !methodPattern.MatchString(r.Method) {
+		return nil, fmt.Errorf("occurrence %d has an invalid request method %q", inst.ID, req.Method)
+	}
+	if traces := inst.Data.Body.Traces(); len(traces) > 0 {
+		e := traces[0].Exception
+		r.Title = strings.TrimSpace(e.Class + ": " + e.Message)
+	} else if inst.Data.Body.Message != nil {
+		r.Title = inst.Data.Body.Message.Body
+	}
+	env := map[string]bool{}
+
+	rawURL := req.URL
+	if !strings.Contains(rawURL, "?") {
+		if req.QueryString != "" {
+			rawURL += "?" + strings.TrimPrefix(req.QueryString, "?")
+		} else if len(req.GET) > 0 {
+			rawURL += "?" + encodeForm(req.GET)
+		}
+	}
+	if opts.BaseURL != "" {
+		rebased, err := rebase(rawURL, opts.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		rawURL = rebased
+		r.Rebased = true
+	}
+	r.URL = placeholdQuery(rawURL, env)
+
+	contentType := ""
+	for name, value := range req.Headers {
+		if skippedHeaders[strings.ToLower(name)] {
+			continue
+		}
+		if strings.EqualFold(name, "Content-Type") {
+			contentType = value
+		}
+		r.Headers = append(r.Headers, [2]string{name, placehold(value, name, env)})
+	}
+	sort.Slice(r.Headers, func(i, j int) bool { return r.Headers[i][0] < r.Headers[j][0] })
+
+	switch {
+	case req.Body != "":
+		r.Body = placeholdBody(req.Body, env)
+	case len(req.POST) > 0 && strings.Contains(contentType, "json"):
+		body, err := marshalNoEscape(placeholdValue(req.POST, "", env))
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	case len(req.POST) > 0:
+		r.Body = placeholdQuery("?"+encodeForm(req.POST), env)[1:]
+		if contentType == "" {
+			r.Headers = append(r.Headers, [2]string{"Content-Type", "application/x-www-form-urlencoded"})
+		}
+	}
+
+	for name := range env {
+		r.Env = append(r.Env, name)
+	}
+	sort.Strings(r.Env)
+	return r, nil
+}
+
+// rebase points rawURL at base, keeping its path and query
+func rebase(rawURL, base string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil || b.Scheme == "" || b.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q: want scheme://host[:port][/path]", base)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parsing request URL: %w", err)
+	}
+	u.Scheme, u.Host, u.User = b.Scheme, b.Host, b.User
+	u.Path = strings.TrimSuffix(b.Path, "/") + u.Path
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// encodeForm encodes params as a form, rendering nested values as JSON
+func encodeForm(params map[string]interface{}) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var value string
+		switch v := params[k].(type) {
+		case string:
+			value = v
+		default:
+			b, _ := json.Marshal(v)
+			value = string(b)
+		}
+		// Keep masks readable so they can be swapped for placeholders
+		escaped := strings.ReplaceAll(url.QueryEscape(value), url.QueryEscape(redact.Mask), redact.Mask)
+		pairs = append(pairs, url.QueryEscape(k)+"="+escaped)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// EnvName converts a header or parameter name into an environment variable name
+func EnvName(key string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(key) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := strings.Trim(b.String(), "_")
+	if name == "" {
+		name = "REDACTED"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "V_" + name
+	}
+	return name
+}
+
+// placehold replaces masked text in a value under key with a placeholder
+func placehold(value, key string, env map[string]bool) string {
+	if !strings.Contains(value, redact.Mask) {
+		return value
+	}
+	name := EnvName(key)
+	env[name] = true
+	return strings.ReplaceAll(value, redact.Mask, placeholderMark+name+placeholderMark)
+}
+
+// placeholdQuery replaces masked query parameter values with placeholders
+func placeholdQuery(rawURL string, env map[string]bool) string {
+	base, query, ok := strings.Cut(rawURL, "?")
+	if !ok || !strings.Contains(query, redact.Mask) {
+		return rawURL
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			key = k
+		}
+		pairs[i] = k + "=" + placehold(v, key, env)
+	}
+	return base + "?" + strings.Join(pairs, "&")
+}
+
+// placeholdValue replaces masked strings in a decoded JSON value, naming each
+// placeholder after the nearest key
+func placeholdValue(v interface{}, key string, env map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			out[k] = placeholdValue(child, k, env)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = placeholdValue(child, key, env)
+		}
+		return out
+	case string:
+		return placehold(v, key, env)
+	}
+	return v
+}
+
+// placeholdBody replaces masks in a JSON, form or text body
+func placeholdBody(body string, env map[string]bool) string {
+	if !strings.Contains(body, redact.Mask) {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err == nil {
+		enc, err := marshalNoEscape(placeholdValue(v, "", env))
+		if err == nil {
+			return enc
+		}
+	}
+	if strings.Contains(body, "=") && !strings.ContainsAny(body, " \n") {
+		return placeholdQuery("?"+body, env)[1:]
+	}
+	return placehold(body, "", env)
+}
+
+func marshalNoEscape(v interface{}) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// segments splits a value into literal text (even indices) and placeholder
+// names (odd indices)
+func segments(s string) []string {
+	return strings.Split(s, placeholderMark)
+}
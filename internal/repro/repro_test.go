@@ -0,0 +1,175 @@
+package repro
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/redact"
+)
+
+func checkoutInstance() *api.Instance {
+	inst := &api.Instance{
+		ID: 123,
+		Data: api.InstanceData{
+			Body: api.Body{Trace: &api.Trace{Exception: api.Exception{Class: "KeyError", Message: "key not found: :sku"}}},
+			Request: &api.Request{
+				Method: "post",
+				URL:    "https://shop.example.com/checkout?session_token=abc&step=2",
+				Headers: map[string]string{
+					"Authorization":  "Bearer secret",
+					"Content-Type":   "application/json",
+					"Content-Length": "42",
+					"Accept":         "application/json",
+				},
+				Body: `{"coupon":"it's","password":"hunter2"}`,
+			},
+		},
+	}
+	redact.Default(nil, nil).Instance(inst)
+	return inst
+}
+
+func TestFromInstance(t *testing.T) {
+	r, err := FromInstance(checkoutInstance(), Options{BaseURL: "http://localhost:3000"})
+	if err != nil {
+		t.Fatalf("FromInstance failed: %v", err)
+	}
+
+	if r.Method != "POST" || !r.Rebased {
+		t.Errorf("unexpected request %+v", r)
+	}
+	if want := "http://localhost:3000/checkout?session_token=" + placeholderMark + "SESSION_TOKEN" + placeholderMark + "&step=2"; r.URL != want {
+		t.Errorf("URL = %q, want %q", r.URL, want)
+	}
+	if got := strings.Join(r.Env, ","); got != "AUTHORIZATION,PASSWORD,SESSION_TOKEN" {
+		t.Errorf("Env = %s", got)
+	}
+	for _, h := range r.Headers {
+		if h[0] == "Content-Length" {
+			t.Error("expected Content-Length to be dropped")
+		}
+	}
+
+	if _, err := FromInstance(&api.Instance{ID: 1}, Options{}); err == nil {
+		t.Error("expected an error for an occurrence without a request")
+	}
+	if _, err := FromInstance(checkoutInstance(), Options{BaseURL: "localhost"}); err == nil {
+		t.Error("expected an error for a base URL without a scheme")
+	}
+}
+
+func TestRender(t *testing.T) {
+	r, err := FromInstance(checkoutInstance(), Options{BaseURL: "http://localhost:3000"})
+	if err != nil {
+		t.Fatalf("FromInstance failed: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"curl", []string{
+			"# Reproduces Rollbar occurrence 123: KeyError: key not found: :sku\n",
+			"# Redacted values are read from the environment: AUTHORIZATION, PASSWORD, SESSION_TOKEN\n",
+			`curl -X 'POST' 'http://localhost:3000/checkout?session_token='"$SESSION_TOKEN"'&step=2'`,
+			`-H 'Authorization: '"$AUTHORIZATION"`,
+			`--data-raw '{"coupon":"it'\''s","password":"'"$PASSWORD"'"}'`,
+		}},
+		{"httpie", []string{
+			`http 'POST' 'http://localhost:3000/checkout?session_token='"$SESSION_TOKEN"'&step=2'`,
+			`'Accept:application/json'`,
+			`--raw '{"coupon":"it'\''s","password":"'"$PASSWORD"'"}'`,
+		}},
+		{"go-test", []string{
+			"func TestReproOccurrence123(t *testing.T) {",
+			`http.NewRequest("POST", "http://localhost:3000/checkout?session_token="+os.Getenv("SESSION_TOKEN")+"&step=2"`,
+			`req.Header.Set("Authorization", os.Getenv("AUTHORIZATION"))`,
+		}},
+		{"rspec", []string{
+			`post "http://localhost:3000/checkout?session_token=#{ENV.fetch("SESSION_TOKEN")}&step=2",`,
+			`params: "{\"coupon\":\"it's\",\"password\":\"#{ENV.fetch("PASSWORD")}\"}",`,
+			`"Authorization" => ENV.fetch("AUTHORIZATION"),`,
+			"expect(response).not_to have_http_status(:server_error)",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out, err := Render(r, tt.format)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("expected %q in output:\n%s", s, out)
+				}
+			}
+			if strings.Contains(out, placeholderMark) || strings.Contains(out, redact.Mask) {
+				t.Errorf("expected all placeholders to be rendered:\n%s", out)
+			}
+			if tt.format == "go-test" {
+				if _, err := parser.ParseFile(token.NewFileSet(), "repro_test.go", out, 0); err != nil {
+					t.Errorf("generated Go does not parse: %v", err)
+				}
+			}
+		})
+	}
+
+	if _, err := Render(r, "wget"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestRSpecUsesPathWithoutBaseURL(t *testing.T) {
+	r, err := FromInstance(checkoutInstance(), Options{})
+	if err != nil {
+		t.Fatalf("FromInstance failed: %v", err)
+	}
+	out, _ := Render(r, "rspec")
+	if !strings.Contains(out, `post "/checkout?session_token=`) {
+		t.Errorf("expected a path in the request spec:\n%s", out)
+	}
+}
+
+func TestFromInstanceRejectsHostileMethods(t *testing.T) {
+	for _, method := range []string{"GET;touch /tmp/x", "$(id)", "get `id`", "POST\nrm -rf ~", "PATCH'"} {
+		inst := checkoutInstance()
+		inst.Data.Request.Method = method
+		if r, err := FromInstance(inst, Options{}); err == nil {
+			t.Errorf("FromInstance accepted method %q as %q", method, r.Method)
+		}
+	}
+
+	inst := checkoutInstance()
+	inst.Data.Request.Method = "m-search"
+	r, err := FromInstance(inst, Options{})
+	if err != nil {
+		t.Fatalf("FromInstance rejected M-SEARCH: %v", err)
+	}
+	out, _ := Render(r, "curl")
+	if !strings.Contains(out, "curl -X 'M-SEARCH' ") {
+		t.Errorf("expected a quoted method:\n%s", out)
+	}
+	out, _ = Render(r, "rspec")
+	if !strings.Contains(out, `process "m-search", `) {
+		t.Errorf("expected the method as a string:\n%s", out)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"Authorization": "AUTHORIZATION",
+		"X-Api-Key":     "X_API_KEY",
+		"user[email]":   "USER_EMAIL",
+		"2fa_code":      "V_2FA_CODE",
+		"":              "REDACTED",
+	}
+	for in, want := range tests {
+		if got := EnvName(in); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
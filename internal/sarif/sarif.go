@@ -0,0 +1,209 @@
+// Package sarif builds SARIF 2.1.0 logs of Rollbar items for code scanning tools.
+package sarif
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// SARIF version and schema written in every log
+const (
+	Version   = "2.1.0"
+	SchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SourceRoot is the uriBaseId of repository-relative locations
+const SourceRoot = "%SRCROOT%"
+
+// Log is a SARIF log
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is the output of one tool invocation
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule is a kind of result; there is one per exception class
+type Rule struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name,omitempty"`
+	ShortDescription Message `json:"shortDescription"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is one item at its source location
+type Result struct {
+	RuleID              string                 `json:"ruleId"`
+	RuleIndex           int                    `json:"ruleIndex"`
+	Level               string                 `json:"level"`
+	Message             Message                `json:"message"`
+	Locations           []Location             `json:"locations"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Properties          map[string]interface{} `json:"properties,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type Region struct {
+	StartLine   int              `json:"startLine"`
+	StartColumn int              `json:"startColumn,omitempty"`
+	Snippet     *ArtifactContent `json:"snippet,omitempty"`
+}
+
+type ArtifactContent struct {
+	Text string `json:"text"`
+}
+
+// Finding is an item located at a source line
+type Finding struct {
+	Item     *api.Item
+	Class    string // Exception class; empty for message items
+	Path     string // Frame path, slash-separated
+	Relative bool   // Path is relative to the repository root
+	Line     int
+	Column   int
+	Code     string // Source line, when known
+}
+
+// Level maps a Rollbar level to a SARIF result level
+func Level(level string) string {
+	switch level {
+	case "critical", "error":
+		return "error"
+	case "info", "debug":
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// New builds a log with one result per finding, in order
+func New(findings []Finding, toolVersion string) *Log {
+	run := Run{
+		Tool: Tool{Driver: Driver{
+			Name:           "rollbar-cli",
+			Version:        toolVersion,
+			InformationURI: "https://github.com/robzolkos/rollbar-cli",
+			Rules:          []Rule{},
+		}},
+		Results: []Result{},
+	}
+
+	rules := map[string]int{}
+	for _, f := range findings {
+		id := f.Class
+		if id == "" {
+			id = "message"
+		}
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			desc := id
+			if f.Class == "" {
+				desc = "Reported message"
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, Rule{ID: id, Name: id, ShortDescription: Message{Text: desc}})
+		}
+		run.Results = append(run.Results, newResult(f, id, index))
+	}
+
+	return &Log{Schema: SchemaURI, Version: Version, Runs: []Run{run}}
+}
+
+func newResult(f Finding, ruleID string, ruleIndex int) Result {
+	item := f.Item
+	level := item.LevelString
+	if level == "" {
+		level = api.LevelToString(item.Level.Int())
+	}
+
+	text := fmt.Sprintf("#%d %s (%d occurrences", item.Counter, item.Title, item.TotalOccurrences)
+	if item.Environment != "" {
+		text += " in " + item.Environment
+	}
+	text += ")"
+
+	loc := PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: fileURI(f.Path, f.Relative)}}
+	if f.Relative {
+		loc.ArtifactLocation.URIBaseID = SourceRoot
+	}
+	if f.Line > 0 {
+		loc.Region = &Region{StartLine: f.Line, StartColumn: f.Column}
+		if f.Code != "" {
+			loc.Region.Snippet = &ArtifactContent{Text: f.Code}
+		}
+	}
+
+	props := map[string]interface{}{
+		"counter":           item.Counter,
+		"level":             level,
+		"status":            item.Status,
+		"total_occurrences": item.TotalOccurrences,
+	}
+	if item.Environment != "" {
+		props["environment"] = item.Environment
+	}
+	if !item.LastOccurrenceTime.IsZero() {
+		props["last_occurrence_time"] = item.LastOccurrenceTime.UTC().Format(time.RFC3339)
+	}
+
+	return Result{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     Level(level),
+		Message:   Message{Text: text},
+		Locations: []Location{{PhysicalLocation: loc}},
+		// Keeps an item's alert stable as its line moves
+		PartialFingerprints: map[string]string{"rollbarItem/v1": strconv.Itoa(item.Counter)},
+		Properties:          props,
+	}
+}
+
+// fileURI turns a path into a URI reference: relative paths stay relative,
+// absolute ones become file URIs and URLs (browser frames) are kept
+func fileURI(p string, relative bool) string {
+	if u, err := url.Parse(p); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return p
+	}
+	if !relative && path.IsAbs(p) {
+		return (&url.URL{Scheme: "file", Path: p}).String()
+	}
+	return (&url.URL{Path: p}).String()
+}
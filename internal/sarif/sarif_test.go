@@ -0,0 +1,154 @@
+package sarif
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func TestLevel(t *testing.T) {
+	tests := map[string]string{
+		"critical": "error",
+		"error":    "error",
+		"warning":  "warning",
+		"info":     "note",
+		"debug":    "note",
+		"":         "warning",
+	}
+	for level, want := range tests {
+		if got := Level(level); got != want {
+			t.Errorf("Level(%q) = %q, want %q", level, got, want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	last := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	findings := []Finding{
+		{
+			Item:     &api.Item{Counter: 12, Title: "NoMethodError: undefined method 'name'", LevelString: "error", Status: "active", Environment: "production", TotalOccurrences: 40, LastOccurrenceTime: last},
+			Class:    "NoMethodError",
+			Path:     "app/models/user.rb",
+			Relative: true,
+			Line:     42,
+			Code:     "user.name",
+		},
+		{
+			Item:  &api.Item{Counter: 13, Title: "Timeout", LevelString: "warning", TotalOccurrences: 3},
+			Class: "Timeout::Error",
+			Path:  "/srv/app/lib/client.rb",
+			Line:  7,
+		},
+		{
+			Item:     &api.Item{Counter: 14, Title: "undefined method 'email'", LevelString: "critical"},
+			Class:    "NoMethodError",
+			Path:     "app/models/account.rb",
+			Relative: true,
+			Line:     9,
+			Column:   3,
+		},
+		{
+			Item: &api.Item{Counter: 15, Title: "Cache miss", LevelString: "info"},
+			Path: "https://example.com/assets/app.js",
+			Line: 1,
+		},
+	}
+
+	log := New(findings, "1.2.3")
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("driver version = %q", run.Tool.Driver.Version)
+	}
+
+	var ruleIDs []string
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+	}
+	if want := []string{"NoMethodError", "Timeout::Error", "message"}; !equal(ruleIDs, want) {
+		t.Errorf("rules = %v, want %v", ruleIDs, want)
+	}
+
+	tests := []struct {
+		ruleIndex int
+		level     string
+		uri       string
+		baseID    string
+		line, col int
+	}{
+		{0, "error", "app/models/user.rb", SourceRoot, 42, 0},
+		{1, "warning", "file:///srv/app/lib/client.rb", "", 7, 0},
+		{0, "error", "app/models/account.rb", SourceRoot, 9, 3},
+		{2, "note", "https://example.com/assets/app.js", "", 1, 0},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("expected %d results, got %d", len(tests), len(run.Results))
+	}
+	for i, tt := range tests {
+		r := run.Results[i]
+		loc := r.Locations[0].PhysicalLocation
+		if r.RuleIndex != tt.ruleIndex || r.Level != tt.level {
+			t.Errorf("result %d: rule %d level %q, want rule %d level %q", i, r.RuleIndex, r.Level, tt.ruleIndex, tt.level)
+		}
+		if loc.ArtifactLocation.URI != tt.uri || loc.ArtifactLocation.URIBaseID != tt.baseID {
+			t.Errorf("result %d: location %+v, want %q base %q", i, loc.ArtifactLocation, tt.uri, tt.baseID)
+		}
+		if loc.Region == nil || loc.Region.StartLine != tt.line || loc.Region.StartColumn != tt.col {
+			t.Errorf("result %d: region %+v, want line %d column %d", i, loc.Region, tt.line, tt.col)
+		}
+	}
+
+	first := run.Results[0]
+	if first.Message.Text != "#12 NoMethodError: undefined method 'name' (40 occurrences in production)" {
+		t.Errorf("message = %q", first.Message.Text)
+	}
+	if first.PartialFingerprints["rollbarItem/v1"] != "12" {
+		t.Errorf("fingerprints = %v", first.PartialFingerprints)
+	}
+	if first.Properties["last_occurrence_time"] != "2026-01-15T10:00:00Z" {
+		t.Errorf("properties = %v", first.Properties)
+	}
+	if first.Locations[0].PhysicalLocation.Region.Snippet.Text != "user.name" {
+		t.Errorf("snippet = %+v", first.Locations[0].PhysicalLocation.Region.Snippet)
+	}
+
+	out, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["$schema"] != SchemaURI {
+		t.Errorf("$schema = %v", doc["$schema"])
+	}
+}
+
+func TestNewEmpty(t *testing.T) {
+	out, err := json.Marshal(New(nil, "dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Code scanning rejects null results and rules
+	want := `{"$schema":"` + SchemaURI + `","version":"2.1.0","runs":[{"tool":{"driver":{"name":"rollbar-cli","version":"dev","informationUri":"https://github.com/robzolkos/rollbar-cli","rules":[]}},"results":[]}]}`
+	if string(out) != want {
+		t.Errorf("got %s\nwant %s", out, want)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
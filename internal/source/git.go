@@ -0,0 +1,190 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a git commit as reported by blame or log
+type Commit struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email"`
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+	Files   []string  `json:"files,omitempty"` // Paths changed, relative to the repository root
+}
+
+// Short returns the abbreviated SHA
+func (c *Commit) Short() string {
+	if len(c.SHA) > 8 {
+		return c.SHA[:8]
+	}
+	return c.SHA
+}
+
+// Repo is a local git repository
+type Repo struct {
+	Root string // Top-level directory
+}
+
+// OpenRepo finds the git repository containing dir
+func OpenRepo(dir string) (*Repo, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository", dir)
+	}
+	return &Repo{Root: strings.TrimSpace(string(out))}, nil
+}
+
+func (r *Repo) git(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Root
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// Rel returns path relative to the repository root
+func (r *Repo) Rel(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Compare against the real root; the top-level path from git is resolved
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Revision resolves rev to a commit SHA, reporting whether it exists locally
+func (r *Repo) Revision(rev string) (string, bool) {
+	if rev == "" {
+		return "", false
+	}
+	out, err := r.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(out)), true
+}
+
+// Blame returns the last commit that touched line of path (relative to the
+// repository root), as of rev (HEAD when empty)
+func (r *Repo) Blame(path string, line int, rev string) (*Commit, error) {
+	args := []string{"blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line)}
+	if rev != "" {
+		args = append(args, rev)
+	}
+	out, err := r.git(append(args, "--", path)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(out)
+}
+
+func parseBlame(out []byte) (*Commit, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("empty blame output")
+	}
+	sha, _, _ := strings.Cut(scanner.Text(), " ")
+	c := &Commit{SHA: sha}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			break // The line content ends the header
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			c.Author = value
+		case "author-mail":
+			c.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+				c.Time = time.Unix(ts, 0)
+			}
+		case "summary":
+			c.Summary = value
+		}
+	}
+	return c, scanner.Err()
+}
+
+// LogOptions selects commits for Log
+type LogOptions struct {
+	From   string    // Exclude commits reachable from this revision
+	To     string    // Tip revision (HEAD when empty)
+	Since  time.Time // Only commits after this time
+	Before time.Time // Only commits before this time
+}
+
+// Log lists non-merge commits with the files they changed, newest first
+func (r *Repo) Log(opts LogOptions) ([]Commit, error) {
+	args := []string{"log", "--no-merges", "--name-only", "--format=%x1e%H%x1f%an%x1f%ae%x1f%at%x1f%s"}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.Format(time.RFC3339))
+	}
+	if !opts.Before.IsZero() {
+		args = append(args, "--until="+opts.Before.Format(time.RFC3339))
+	}
+	to := opts.To
+	if to == "" {
+		to = "HEAD"
+	}
+	if opts.From != "" {
+		args = append(args, opts.From+".."+to)
+	} else {
+		args = append(args, to)
+	}
+
+	out, err := r.git(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(string(out)), nil
+}
+
+func parseLog(out string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		header, files, _ := strings.Cut(record, "\n")
+		fields := strings.SplitN(header, "\x1f", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		c := Commit{SHA: fields[0], Author: fields[1], Email: fields[2], Summary: fields[4]}
+		if ts, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			c.Time = time.Unix(ts, 0)
+		}
+		for _, f := range strings.Split(files, "\n") {
+			if f = strings.TrimSpace(f); f != "" {
+				c.Files = append(c.Files, f)
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
@@ -0,0 +1,156 @@
+package source
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRepo creates a git repository with deterministic commit times
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git(time.Time{}, "init", "-q")
+	return r
+}
+
+func (r *testRepo) git(at time.Time, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Jane Doe", "GIT_AUTHOR_EMAIL=jane@example.com",
+		"GIT_COMMITTER_NAME=Jane Doe", "GIT_COMMITTER_EMAIL=jane@example.com")
+	if !at.IsZero() {
+		date := at.Format(time.RFC3339)
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *testRepo) commit(at time.Time, msg string, files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		writeFile(r.t, filepath.Join(r.dir, name), content)
+		r.git(at, "add", name)
+	}
+	r.git(at, "commit", "-q", "-m", msg)
+	return r.git(at, "rev-parse", "HEAD")
+}
+
+func TestRepoBlameAndLog(t *testing.T) {
+	r := newTestRepo(t)
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	first := r.commit(base, "Add user model", map[string]string{
+		"app/models/user.rb": "class User\n  def name\n    @name\n  end\nend\n",
+	})
+	second := r.commit(base.Add(24*time.Hour), "Use display name", map[string]string{
+		"app/models/user.rb": "class User\n  def name\n    profile.display_name\n  end\nend\n",
+	})
+	third := r.commit(base.Add(48*time.Hour), "Update README", map[string]string{
+		"README.md": "docs\n",
+	})
+
+	repo, err := OpenRepo(r.dir)
+	if err != nil {
+		t.Fatalf("OpenRepo failed: %v", err)
+	}
+
+	rel, err := repo.Rel(filepath.Join(r.dir, "app/models/user.rb"))
+	if err != nil || rel != "app/models/user.rb" {
+		t.Fatalf("Rel = %q, %v", rel, err)
+	}
+
+	c, err := repo.Blame(rel, 3, "")
+	if err != nil {
+		t.Fatalf("Blame failed: %v", err)
+	}
+	if c.SHA != second || c.Author != "Jane Doe" || c.Summary != "Use display name" {
+		t.Errorf("unexpected blame: %+v", c)
+	}
+	if !c.Time.Equal(base.Add(24 * time.Hour)) {
+		t.Errorf("unexpected blame time: %v", c.Time)
+	}
+
+	c, err = repo.Blame(rel, 3, first)
+	if err != nil || c.SHA != first {
+		t.Errorf("expected blame at %s to be %s, got %+v (%v)", first, first, c, err)
+	}
+
+	if sha, ok := repo.Revision(second[:8]); !ok || sha != second {
+		t.Errorf("Revision(%s) = %s, %v", second[:8], sha, ok)
+	}
+	if _, ok := repo.Revision("does-not-exist"); ok {
+		t.Error("expected unknown revision to fail")
+	}
+
+	commits, err := repo.Log(LogOptions{From: first, To: third})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].SHA != third || commits[1].SHA != second {
+		t.Fatalf("unexpected commits: %+v", commits)
+	}
+	if strings.Join(commits[1].Files, ",") != "app/models/user.rb" {
+		t.Errorf("unexpected files: %v", commits[1].Files)
+	}
+
+	commits, err = repo.Log(LogOptions{Since: base.Add(time.Hour), Before: base.Add(36 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Log failed: %v", err)
+	}
+	if len(commits) != 1 || commits[0].SHA != second {
+		t.Errorf("expected only %s in the window, got %+v", second, commits)
+	}
+}
+
+func TestRankSuspects(t *testing.T) {
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	blamed := Commit{SHA: "aaa", Time: now.Add(-48 * time.Hour), Files: []string{"app/models/user.rb"}}
+	toucher := Commit{SHA: "bbb", Time: now.Add(-24 * time.Hour), Files: []string{"app/controllers/users_controller.rb"}}
+	deepToucher := Commit{SHA: "ccc", Time: now.Add(-12 * time.Hour), Files: []string{"lib/tasks/cleanup.rb"}}
+	unrelated := Commit{SHA: "ddd", Time: now.Add(-time.Hour), Files: []string{"README.md"}}
+
+	frames := []FrameRef{
+		{Path: "app/models/user.rb", Line: 3, Blame: &blamed},
+		{Path: "app/controllers/users_controller.rb", Line: 10},
+		{Path: "app/models/user.rb", Line: 8},
+		{Path: "app/jobs/a.rb", Line: 1},
+		{Path: "app/jobs/b.rb", Line: 1},
+		{Path: "lib/tasks/cleanup.rb", Line: 1},
+	}
+
+	suspects := RankSuspects([]Commit{unrelated, deepToucher, toucher, blamed}, frames)
+
+	var order []string
+	for _, s := range suspects {
+		order = append(order, s.Commit.SHA)
+	}
+	if got := strings.Join(order, ","); got != "aaa,bbb,ccc,ddd" {
+		t.Errorf("unexpected order %s", got)
+	}
+	if suspects[0].Score != 15 {
+		t.Errorf("expected blamed score 15, got %d", suspects[0].Score)
+	}
+	if len(suspects[0].Reasons) != 1 || !strings.Contains(suspects[0].Reasons[0], "app/models/user.rb:3") {
+		t.Errorf("unexpected reasons: %v", suspects[0].Reasons)
+	}
+	if suspects[3].Score != 0 || len(suspects[3].Reasons) != 0 {
+		t.Errorf("expected unrelated commit to score 0, got %+v", suspects[3])
+	}
+}
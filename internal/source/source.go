@@ -0,0 +1,203 @@
+// Package source maps stack frame paths to files in the local working tree and
+// fills in source lines that Rollbar did not capture.
+package source
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+// Resolver maps frame filenames to local files
+type Resolver struct {
+	Dir     string            // Local tree root (default ".")
+	PathMap map[string]string // Frame path prefix rewrites; the longest match wins
+
+	files map[string][]string // Cached file lines, nil for unreadable files
+}
+
+// NewResolver creates a resolver for the tree at dir
+func NewResolver(dir string, pathMap map[string]string) *Resolver {
+	if dir == "" {
+		dir = "."
+	}
+	return &Resolver{Dir: dir, PathMap: pathMap}
+}
+
+// Resolve returns the local path for a frame filename. Path map rewrites are
+// tried first, then the server root is stripped; remaining absolute paths are
+// not guessed at. The file must exist.
+func (r *Resolver) Resolve(filename, serverRoot string) (string, bool) {
+	rel, ok := r.Rewrite(filename, serverRoot)
+	if !ok {
+		return "", false
+	}
+	path := rel
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.Dir, rel)
+	}
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return path, true
+}
+
+// Rewrite maps a frame filename to a path relative to the tree (or absolute,
+// when a path map says so) without checking that it exists. Frame filenames
+// come from untrusted payloads, so results that would leave the tree, or the
+// directory a path map points at, are rejected.
+func (r *Resolver) Rewrite(filename, serverRoot string) (string, bool) {
+	if filename == "" {
+		return "", false
+	}
+
+	// Longest prefix first so that more specific mappings win
+	prefixes := make([]string, 0, len(r.PathMap))
+	for prefix := range r.PathMap {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(filename, prefix); ok {
+			target := r.PathMap[prefix]
+			rel, ok := within(target, rest)
+			if !ok {
+				return "", false
+			}
+			return filepath.Join(target, rel), true
+		}
+	}
+
+	if serverRoot != "" {
+		root := strings.TrimSuffix(serverRoot, "/") + "/"
+		if rest, ok := strings.CutPrefix(filename, root); ok {
+			return within(".", rest)
+		}
+	}
+
+	if filepath.IsAbs(filename) {
+		return "", false
+	}
+	return within(".", filename)
+}
+
+// within returns path cleaned and relative to base, reporting false when it
+// climbs out of base with ".."
+func within(base, path string) (string, bool) {
+	rel, err := filepath.Rel(base, filepath.Join(base, path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// lines returns the lines of a local file, cached per resolver
+func (r *Resolver) lines(path string) []string {
+	if r.files == nil {
+		r.files = map[string][]string{}
+	}
+	if lines, ok := r.files[path]; ok {
+		return lines
+	}
+
+	var lines []string
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if scanner.Err() != nil {
+			lines = nil
+		}
+		f.Close()
+	}
+	r.files[path] = lines
+	return lines
+}
+
+// Enrich fills in missing frame code and surrounding lines from local files,
+// with n lines of context on each side. Frames that already carry code or
+// context are left alone. It returns the number of frames enriched.
+func (r *Resolver) Enrich(inst *api.Instance, n int) int {
+	if n < 0 {
+		return 0
+	}
+	root := ""
+	if inst.Data.Server != nil {
+		root = inst.Data.Server.Root
+	}
+
+	enriched := 0
+	body := &inst.Data.Body
+	if body.Trace != nil {
+		enriched += r.enrichFrames(body.Trace.Frames, root, n)
+	}
+	for i := range body.TraceChain {
+		enriched += r.enrichFrames(body.TraceChain[i].Frames, root, n)
+	}
+	return enriched
+}
+
+func (r *Resolver) enrichFrames(frames []api.Frame, root string, n int) int {
+	enriched := 0
+	for i := range frames {
+		frame := &frames[i]
+		if frame.Lineno <= 0 || frame.Code != "" || len(frame.Context.Pre) > 0 || len(frame.Context.Post) > 0 {
+			continue
+		}
+		path, ok := r.Resolve(frame.Filename, root)
+		if !ok {
+			continue
+		}
+		lines := r.lines(path)
+		if frame.Lineno > len(lines) {
+			continue
+		}
+
+		idx := frame.Lineno - 1
+		start := max(idx-n, 0)
+		end := min(idx+n+1, len(lines))
+		frame.Code = lines[idx]
+		frame.Context.Pre = append([]string(nil), lines[start:idx]...)
+		frame.Context.Post = append([]string(nil), lines[idx+1:end]...)
+		enriched++
+	}
+	return enriched
+}
+
+// CheckVersion compares the git checkout at dir with an occurrence's code
+// version, which may be a full or abbreviated commit SHA or a tag. It returns
+// the local HEAD and whether it matches. An error means dir is not a git
+// checkout (or git is unavailable), so no comparison could be made.
+func CheckVersion(dir, codeVersion string) (head string, matches bool, err error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", false, err
+	}
+	head = strings.TrimSpace(string(out))
+	if codeVersion == "" {
+		return head, true, nil
+	}
+
+	// Abbreviated SHAs need enough digits to be meaningful
+	version := strings.ToLower(codeVersion)
+	if len(version) >= 7 && strings.HasPrefix(head, version) {
+		return head, true, nil
+	}
+
+	// Tags pointing at HEAD count as a match (e.g. code_version "v1.4.2")
+	if out, err := exec.Command("git", "-C", dir, "tag", "--points-at", "HEAD").Output(); err == nil {
+		for _, tag := range strings.Fields(string(out)) {
+			if tag == codeVersion {
+				return head, true, nil
+			}
+		}
+	}
+	return head, false, nil
+}
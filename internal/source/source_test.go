@@ -0,0 +1,204 @@
+package source
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app/models/user.rb"), "class User\nend\n")
+	writeFile(t, filepath.Join(dir, "lib/billing/charge.rb"), "module Billing\nend\n")
+
+	r := NewResolver(dir, map[string]string{
+		"/app/":             "./",
+		"/app/lib/billing/": "lib/billing/",
+		"webpack:///./src/": "src/",
+	})
+
+	tests := []struct {
+		name     string
+		filename string
+		root     string
+		want     string
+	}{
+		{"path map", "/app/app/models/user.rb", "", "app/models/user.rb"},
+		{"longest prefix wins", "/app/lib/billing/charge.rb", "", "lib/billing/charge.rb"},
+		{"server root", "/srv/deploy/current/app/models/user.rb", "/srv/deploy/current", "app/models/user.rb"},
+		{"relative", "app/models/user.rb", "", "app/models/user.rb"},
+		{"unknown absolute", "/usr/lib/ruby/3.2.0/set.rb", "", ""},
+		{"missing file", "/app/app/models/account.rb", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := r.Resolve(tt.filename, tt.root)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("expected no match, got %s", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected %s to resolve", tt.filename)
+			}
+			if want := filepath.Join(dir, tt.want); got != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestResolveTraversal(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "repo")
+	shared := filepath.Join(parent, "shared")
+	writeFile(t, filepath.Join(parent, "secret"), "token\n")
+	writeFile(t, filepath.Join(dir, "app/models/user.rb"), "class User\nend\n")
+	writeFile(t, filepath.Join(shared, "lib/util.rb"), "module Util\nend\n")
+
+	r := NewResolver(dir, map[string]string{
+		"/app/":    "./",
+		"/shared/": shared,
+	})
+	for _, tt := range []struct{ filename, root string }{
+		{"../secret", ""},
+		{"app/../../secret", ""},
+		{"/app/../secret", ""},
+		{"/app/app/../../secret", ""},
+		{"/srv/app/../secret", "/srv/app"},
+		{"/shared/../secret", ""},
+		{"/shared/lib/../../secret", ""},
+	} {
+		if got, ok := r.Resolve(tt.filename, tt.root); ok {
+			t.Errorf("Resolve(%q, %q) escaped the tree: %s", tt.filename, tt.root, got)
+		}
+	}
+
+	// A path map may point outside the tree explicitly
+	if got, ok := r.Resolve("/shared/lib/util.rb", ""); !ok || got != filepath.Join(shared, "lib/util.rb") {
+		t.Errorf("expected the mapped file, got %q, %v", got, ok)
+	}
+	// A server root with a leading slash left over stays inside the tree
+	if got, ok := r.Rewrite("/srv/app//etc/passwd", "/srv/app"); !ok || got != filepath.Join("etc", "passwd") {
+		t.Errorf("Rewrite = %q, %v", got, ok)
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "app/models/user.rb"), "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\n")
+
+	inst := api.Instance{Data: api.InstanceData{
+		Server: &api.Server{Root: "/app"},
+		Body: api.Body{TraceChain: []api.Trace{
+			{Frames: []api.Frame{
+				{Filename: "/app/app/models/user.rb", Lineno: 2},
+				{Filename: "/app/app/models/user.rb", Lineno: 5, Code: "captured by rollbar"},
+			}},
+			{Frames: []api.Frame{
+				{Filename: "/app/app/models/user.rb", Lineno: 6},
+				{Filename: "/app/app/models/user.rb", Lineno: 99},
+			}},
+		}},
+	}}
+
+	r := NewResolver(dir, nil)
+	if n := r.Enrich(&inst, 2); n != 2 {
+		t.Fatalf("expected 2 frames enriched, got %d", n)
+	}
+
+	first := inst.Data.Body.TraceChain[0].Frames[0]
+	if first.Code != "line 2" {
+		t.Errorf("expected code 'line 2', got %q", first.Code)
+	}
+	if strings.Join(first.Context.Pre, ",") != "line 1" {
+		t.Errorf("unexpected pre context: %v", first.Context.Pre)
+	}
+	if strings.Join(first.Context.Post, ",") != "line 3,line 4" {
+		t.Errorf("unexpected post context: %v", first.Context.Post)
+	}
+
+	if got := inst.Data.Body.TraceChain[0].Frames[1].Code; got != "captured by rollbar" {
+		t.Errorf("expected captured code to be kept, got %q", got)
+	}
+
+	last := inst.Data.Body.TraceChain[1].Frames[0]
+	if last.Code != "line 6" || len(last.Context.Post) != 0 {
+		t.Errorf("unexpected frame at end of file: %+v", last)
+	}
+	if inst.Data.Body.TraceChain[1].Frames[1].Code != "" {
+		t.Error("expected out-of-range line to be left alone")
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	writeFile(t, filepath.Join(dir, "README"), "hello\n")
+	git("add", "README")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.0.0")
+	head := git("rev-parse", "HEAD")
+
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{head, true},
+		{head[:7], true},
+		{"v1.0.0", true},
+		{"", true},
+		{head[:3], false},
+		{"0123456789abcdef0123456789abcdef01234567", false},
+		{"v2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		got, matches, err := CheckVersion(dir, tt.version)
+		if err != nil {
+			t.Fatalf("CheckVersion(%q): %v", tt.version, err)
+		}
+		if got != head {
+			t.Errorf("expected head %s, got %s", head, got)
+		}
+		if matches != tt.want {
+			t.Errorf("CheckVersion(%q) matches = %v, want %v", tt.version, matches, tt.want)
+		}
+	}
+
+	if _, _, err := CheckVersion(t.TempDir(), head); err == nil {
+		t.Error("expected an error outside a git checkout")
+	}
+}
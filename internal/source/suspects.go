@@ -0,0 +1,70 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FrameRef is an app frame mapped to a file in the repository
+type FrameRef struct {
+	Path  string  // Relative to the repository root
+	Line  int     // Line number in the file
+	Blame *Commit // Last commit touching the line, if known
+}
+
+// Suspect is a commit ranked by how likely it introduced an error
+type Suspect struct {
+	Commit  Commit   `json:"commit"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// frameWeight favours frames near the top of the stack, where errors surface
+func frameWeight(i int) int {
+	if i >= 4 {
+		return 1
+	}
+	return 5 - i
+}
+
+// RankSuspects scores candidate commits against the app frames of an error,
+// ordered top of stack first. A commit that last changed a frame's line scores
+// highest, one that touched a frame's file scores less, both weighted by frame
+// depth. Ties go to the most recent commit. Commits with no connection to the
+// frames are kept with a zero score, after the rest.
+func RankSuspects(commits []Commit, frames []FrameRef) []Suspect {
+	suspects := make([]Suspect, 0, len(commits))
+	for _, c := range commits {
+		s := Suspect{Commit: c, Reasons: []string{}}
+
+		files := make(map[string]bool, len(c.Files))
+		for _, f := range c.Files {
+			files[f] = true
+		}
+
+		touched := map[string]bool{}
+		for i, frame := range frames {
+			w := frameWeight(i)
+			if frame.Blame != nil && frame.Blame.SHA == c.SHA {
+				s.Score += 3 * w
+				s.Reasons = append(s.Reasons, fmt.Sprintf("last changed %s:%d", frame.Path, frame.Line))
+				touched[frame.Path] = true
+				continue
+			}
+			if files[frame.Path] && !touched[frame.Path] {
+				s.Score += w
+				s.Reasons = append(s.Reasons, fmt.Sprintf("touches %s", frame.Path))
+				touched[frame.Path] = true
+			}
+		}
+		suspects = append(suspects, s)
+	}
+
+	sort.SliceStable(suspects, func(i, j int) bool {
+		if suspects[i].Score != suspects[j].Score {
+			return suspects[i].Score > suspects[j].Score
+		}
+		return suspects[i].Commit.Time.After(suspects[j].Commit.Time)
+	})
+	return suspects
+}
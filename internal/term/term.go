@@ -0,0 +1,42 @@
+// Package term provides minimal terminal control without external dependencies.
+package term
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// ErrUnsupported is returned when terminal control is not available on this platform
+var ErrUnsupported = errors.New("terminal control is not supported on this platform")
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
+
+// Size returns the terminal width and height, falling back to $COLUMNS/$LINES
+// and then to 80x24 when the size cannot be determined
+func Size() (width, height int) {
+	width, height = 80, 24
+	if w, h, err := querySize(); err == nil && w > 0 && h > 0 {
+		return w, h
+	}
+	if c, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && c > 0 {
+		width = c
+	}
+	if l, err := strconv.Atoi(os.Getenv("LINES")); err == nil && l > 0 {
+		height = l
+	}
+	return width, height
+}
+
+// MakeRaw puts the terminal attached to f into raw mode and returns a function
+// that restores the previous state
+func MakeRaw(f *os.File) (restore func() error, err error) {
+	return makeRaw(f)
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+
+package term
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// stty runs stty against the controlling terminal
+func stty(args ...string) (string, error) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func querySize() (int, int, error) {
+	out, err := stty("size")
+	if err != nil {
+		return 0, 0, err
+	}
+	var rows, cols int
+	if _, err := fmt.Sscanf(out, "%d %d", &rows, &cols); err != nil {
+		return 0, 0, err
+	}
+	return cols, rows, nil
+}
+
+func makeRaw(f *os.File) (func() error, error) {
+	if !IsTerminal(f) {
+		return nil, fmt.Errorf("not a terminal")
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("saving terminal state: %w", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("entering raw mode: %w", err)
+	}
+
+	return func() error {
+		_, err := stty(state)
+		return err
+	}, nil
+}
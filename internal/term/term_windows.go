@@ -0,0 +1,13 @@
+//go:build windows
+
+package term
+
+import "os"
+
+func querySize() (int, int, error) {
+	return 0, 0, ErrUnsupported
+}
+
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,171 @@
+package term
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wideRanges are the East Asian Wide and Fullwidth ranges, and the emoji
+// blocks that terminals draw two cells wide
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo initials
+	{0x231A, 0x231B},   // Watch, hourglass
+	{0x2329, 0x232A},   // Angle brackets
+	{0x23E9, 0x23EC},   // Media controls
+	{0x23F0, 0x23F0},   // Alarm clock
+	{0x23F3, 0x23F3},   // Hourglass
+	{0x25FD, 0x25FE},   // Small squares
+	{0x2614, 0x2615},   // Umbrella, hot beverage
+	{0x2648, 0x2653},   // Zodiac
+	{0x267F, 0x267F},   // Wheelchair
+	{0x2693, 0x2693},   // Anchor
+	{0x26A1, 0x26A1},   // High voltage
+	{0x26AA, 0x26AB},   // Circles
+	{0x26BD, 0x26BE},   // Balls
+	{0x26C4, 0x26C5},   // Snowman, sun
+	{0x26CE, 0x26CE},   // Ophiuchus
+	{0x26D4, 0x26D4},   // No entry
+	{0x26EA, 0x26EA},   // Church
+	{0x26F2, 0x26F3},   // Fountain, golf
+	{0x26F5, 0x26F5},   // Sailboat
+	{0x26FA, 0x26FA},   // Tent
+	{0x26FD, 0x26FD},   // Fuel pump
+	{0x2705, 0x2705},   // Check mark
+	{0x270A, 0x270B},   // Fists
+	{0x2728, 0x2728},   // Sparkles
+	{0x274C, 0x274C},   // Cross mark
+	{0x274E, 0x274E},   // Cross mark
+	{0x2753, 0x2755},   // Question marks
+	{0x2757, 0x2757},   // Exclamation mark
+	{0x2795, 0x2797},   // Math signs
+	{0x27B0, 0x27B0},   // Curly loop
+	{0x27BF, 0x27BF},   // Double curly loop
+	{0x2B1B, 0x2B1C},   // Large squares
+	{0x2B50, 0x2B50},   // Star
+	{0x2B55, 0x2B55},   // Circle
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // Kana, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x16FE0, 0x18CFF}, // Tangut
+	{0x1B000, 0x1B2FF}, // Kana supplement
+	{0x1F004, 0x1F004}, // Mahjong tile
+	{0x1F0CF, 0x1F0CF}, // Playing card
+	{0x1F18E, 0x1F18E}, // AB button
+	{0x1F191, 0x1F19A}, // Squared words
+	{0x1F200, 0x1F2FF}, // Enclosed ideographs
+	{0x1F300, 0x1F64F}, // Pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // Transport and map
+	{0x1F7E0, 0x1F7EB}, // Colored shapes
+	{0x1F90C, 0x1F9FF}, // Supplemental symbols
+	{0x1FA70, 0x1FAFF}, // Symbols and pictographs extended-A
+	{0x20000, 0x3FFFD}, // CJK extensions B and later
+}
+
+// RuneWidth returns the number of terminal cells r occupies: 0 for combining
+// marks and format characters, 2 for wide characters and emoji, 1 otherwise
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0, r < 0x20, r == 0x7F:
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	}
+	for _, rng := range wideRanges {
+		if r < rng[0] {
+			break
+		}
+		if r <= rng[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// StringWidth returns the display width of s, ignoring ANSI escape sequences
+func StringWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		if inEscape {
+			inEscape = !isEscapeEnd(r)
+			continue
+		}
+		if r == '\033' {
+			inEscape = true
+			continue
+		}
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// Truncate shortens s to at most width cells, ending it with tail when cut.
+// ANSI escape sequences are kept so that colors are still reset.
+func Truncate(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	limit := width - StringWidth(tail)
+	if limit < 0 {
+		limit, tail = width, ""
+	}
+
+	var b strings.Builder
+	n := 0
+	inEscape, cut := false, false
+	for _, r := range s {
+		if inEscape {
+			b.WriteRune(r)
+			inEscape = !isEscapeEnd(r)
+			continue
+		}
+		if r == '\033' {
+			inEscape = true
+			b.WriteRune(r)
+			continue
+		}
+		if cut {
+			continue
+		}
+		w := RuneWidth(r)
+		if n+w > limit {
+			b.WriteString(tail)
+			cut = true
+			continue
+		}
+		n += w
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Pad fills s with spaces on the right to width cells
+func Pad(s string, width int) string {
+	if n := width - StringWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// PadLeft fills s with spaces on the left to width cells
+func PadLeft(s string, width int) string {
+	if n := width - StringWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+func isEscapeEnd(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
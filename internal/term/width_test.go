@@ -0,0 +1,63 @@
+package term
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"café", 4},
+		{"café", 4}, // Combining acute accent
+		{"日本語", 6},
+		{"ｆｕｌｌ", 8},
+		{"fire 🔥", 7},
+		{"\033[31merror\033[0m", 5},
+		{"한국어 OK", 9},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"NoMethodError: undefined", 10, "NoMetho..."},
+		{"日本語のエラーメッセージ", 10, "日本語..."},     // 3 wide runes + "..." = 9 cells; a 4th would be 11
+		{"ünïcödé strings", 8, "ünïcö..."}, // Bytes would split a rune here
+		{"🔥🔥🔥🔥", 5, "🔥..."},
+		{"\033[31mcritical\033[0m", 6, "\033[31mcri...\033[0m"},
+		{"abcdef", 2, "ab"},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.width, "...")
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if w := StringWidth(got); w > tt.width {
+			t.Errorf("Truncate(%q, %d) is %d cells wide", tt.s, tt.width, w)
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	if got := Pad("日本", 6); got != "日本  " {
+		t.Errorf("Pad = %q", got)
+	}
+	if got := PadLeft("\033[31m42\033[0m", 4); got != "  \033[31m42\033[0m" {
+		t.Errorf("PadLeft = %q", got)
+	}
+	if got := Pad("toolong", 3); got != "toolong" {
+		t.Errorf("Pad should not cut, got %q", got)
+	}
+}
@@ -0,0 +1,633 @@
+// Package tui implements the interactive triage interface behind 'rollbar tui'.
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+	"github.com/robzolkos/rollbar-cli/internal/term"
+)
+
+// Backend is the subset of the Rollbar API used by the TUI
+type Backend interface {
+	ListItems(opts api.ItemsOptions) ([]api.Item, int, error)
+	ListInstances(opts api.InstancesOptions) ([]api.Instance, error)
+	UpdateItemStatus(id int64, status string) (*api.Item, error)
+	AssignItem(id int64, userID int64) (*api.Item, error)
+}
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeContext
+	modeHelp
+	modePrompt
+)
+
+type promptKind int
+
+const (
+	promptResolve promptKind = iota
+	promptMute
+	promptAssign
+)
+
+// contextOccurrences is the number of occurrences included in the context view
+const contextOccurrences = 3
+
+// occurrences caches the loaded occurrence pages for one item
+type occurrences struct {
+	instances []api.Instance
+	page      int
+	exhausted bool
+	index     int
+}
+
+// Model holds the TUI state. It is independent of the terminal so it can be
+// driven by key events in tests.
+type Model struct {
+	backend Backend
+	opts    api.ItemsOptions
+
+	items    []api.Item
+	selected int
+	offset   int
+
+	occ map[int64]*occurrences
+
+	mode       mode
+	lines      []string
+	scroll     int
+	promptKind promptKind
+	input      string
+
+	status string
+	quit   bool
+}
+
+// NewModel creates a model listing items with the given filters
+func NewModel(backend Backend, opts api.ItemsOptions) *Model {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	return &Model{
+		backend: backend,
+		opts:    opts,
+		occ:     make(map[int64]*occurrences),
+	}
+}
+
+// Quit reports whether the user asked to exit
+func (m *Model) Quit() bool {
+	return m.quit
+}
+
+// Load fetches the current page of items and the latest occurrence of the selection
+func (m *Model) Load() error {
+	items, _, err := m.backend.ListItems(m.opts)
+	if err != nil {
+		return err
+	}
+	m.items = items
+	m.selected = 0
+	m.offset = 0
+	m.occ = make(map[int64]*occurrences)
+	m.loadOccurrences()
+	return nil
+}
+
+func (m *Model) current() *api.Item {
+	if m.selected < 0 || m.selected >= len(m.items) {
+		return nil
+	}
+	return &m.items[m.selected]
+}
+
+// loadOccurrences makes sure the selected item has its first occurrence page loaded
+func (m *Model) loadOccurrences() {
+	item := m.current()
+	if item == nil {
+		return
+	}
+	if _, ok := m.occ[item.ID.Int64()]; ok {
+		return
+	}
+	occ := &occurrences{}
+	m.occ[item.ID.Int64()] = occ
+	m.fetchOccurrencePage(item, occ)
+}
+
+func (m *Model) fetchOccurrencePage(item *api.Item, occ *occurrences) {
+	instances, err := m.backend.ListInstances(api.InstancesOptions{
+		ItemID: item.ID.Int64(),
+		Page:   occ.page + 1,
+	})
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	if len(instances) == 0 {
+		occ.exhausted = true
+		return
+	}
+	occ.page++
+	occ.instances = append(occ.instances, instances...)
+}
+
+func (m *Model) currentOccurrences() *occurrences {
+	item := m.current()
+	if item == nil {
+		return nil
+	}
+	return m.occ[item.ID.Int64()]
+}
+
+// HandleKey applies a single key press to the model
+func (m *Model) HandleKey(k Key) {
+	switch m.mode {
+	case modePrompt:
+		m.handlePromptKey(k)
+	case modeContext, modeHelp:
+		m.handlePagerKey(k)
+	default:
+		m.handleListKey(k)
+	}
+}
+
+func (m *Model) handleListKey(k Key) {
+	m.status = ""
+	switch k {
+	case KeyCtrlC, "q":
+		m.quit = true
+	case KeyUp, "k":
+		m.move(-1)
+	case KeyDown, "j":
+		m.move(1)
+	case KeyPageUp:
+		m.move(-10)
+	case KeyPageDown:
+		m.move(10)
+	case KeyHome, "g":
+		m.move(-len(m.items))
+	case KeyEnd, "G":
+		m.move(len(m.items))
+	case "n":
+		m.nextOccurrence()
+	case "p":
+		m.prevOccurrence()
+	case "]":
+		m.changePage(1)
+	case "[":
+		m.changePage(-1)
+	case "R":
+		if err := m.Load(); err != nil {
+			m.status = "Error: " + err.Error()
+		} else {
+			m.status = "Refreshed"
+		}
+	case "r":
+		m.startPrompt(promptResolve)
+	case "m":
+		m.startPrompt(promptMute)
+	case "a":
+		m.startPrompt(promptAssign)
+	case "c", KeyEnter:
+		m.openContext()
+	case "?":
+		m.lines = helpLines()
+		m.scroll = 0
+		m.mode = modeHelp
+	}
+}
+
+func (m *Model) handlePagerKey(k Key) {
+	switch k {
+	case KeyCtrlC:
+		m.quit = true
+	case KeyEsc, "q", KeyBackspace:
+		m.mode = modeList
+		m.lines = nil
+	case KeyUp, "k":
+		m.scroll--
+	case KeyDown, "j":
+		m.scroll++
+	case KeyPageUp:
+		m.scroll -= 20
+	case KeyPageDown, " ":
+		m.scroll += 20
+	case KeyHome, "g":
+		m.scroll = 0
+	case KeyEnd, "G":
+		m.scroll = len(m.lines)
+	}
+	if m.scroll > len(m.lines)-1 {
+		m.scroll = len(m.lines) - 1
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+}
+
+func (m *Model) handlePromptKey(k Key) {
+	switch k {
+	case KeyCtrlC, KeyEsc:
+		m.mode = modeList
+		m.status = "Cancelled"
+		return
+	case KeyBackspace:
+		if m.input != "" {
+			runes := []rune(m.input)
+			m.input = string(runes[:len(runes)-1])
+		}
+		return
+	case KeyEnter:
+		m.mode = modeList
+		m.submitPrompt()
+		return
+	}
+
+	if m.promptKind != promptAssign {
+		m.mode = modeList
+		if k == "y" || k == "Y" {
+			m.submitPrompt()
+		} else {
+			m.status = "Cancelled"
+		}
+		return
+	}
+	if len(k) == 1 && k[0] >= '0' && k[0] <= '9' {
+		m.input += string(k)
+	}
+}
+
+func (m *Model) move(delta int) {
+	if len(m.items) == 0 {
+		return
+	}
+	m.selected += delta
+	if m.selected < 0 {
+		m.selected = 0
+	}
+	if m.selected >= len(m.items) {
+		m.selected = len(m.items) - 1
+	}
+	m.loadOccurrences()
+}
+
+func (m *Model) changePage(delta int) {
+	if m.opts.Page+delta < 1 {
+		m.status = "Already on the first page"
+		return
+	}
+	m.opts.Page += delta
+	if err := m.Load(); err != nil {
+		m.opts.Page -= delta
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.status = fmt.Sprintf("Page %d", m.opts.Page)
+}
+
+func (m *Model) nextOccurrence() {
+	item := m.current()
+	occ := m.currentOccurrences()
+	if item == nil || occ == nil {
+		return
+	}
+	if occ.index+1 >= len(occ.instances) && !occ.exhausted {
+		m.fetchOccurrencePage(item, occ)
+	}
+	if occ.index+1 < len(occ.instances) {
+		occ.index++
+	} else {
+		m.status = "No older occurrences"
+	}
+}
+
+func (m *Model) prevOccurrence() {
+	occ := m.currentOccurrences()
+	if occ == nil {
+		return
+	}
+	if occ.index > 0 {
+		occ.index--
+	} else {
+		m.status = "Already at the latest occurrence"
+	}
+}
+
+func (m *Model) startPrompt(kind promptKind) {
+	if m.current() == nil {
+		return
+	}
+	m.promptKind = kind
+	m.input = ""
+	m.mode = modePrompt
+}
+
+func (m *Model) promptText() string {
+	item := m.current()
+	switch m.promptKind {
+	case promptResolve:
+		return fmt.Sprintf("Resolve #%d? (y/N) ", item.Counter)
+	case promptMute:
+		return fmt.Sprintf("Mute #%d? (y/N) ", item.Counter)
+	default:
+		return fmt.Sprintf("Assign #%d to user ID (0 to unassign): %s", item.Counter, m.input)
+	}
+}
+
+func (m *Model) submitPrompt() {
+	item := m.current()
+	if item == nil {
+		return
+	}
+
+	var updated *api.Item
+	var err error
+	var done string
+
+	switch m.promptKind {
+	case promptResolve:
+		updated, err = m.backend.UpdateItemStatus(item.ID.Int64(), "resolved")
+		done = "Resolved"
+	case promptMute:
+		updated, err = m.backend.UpdateItemStatus(item.ID.Int64(), "muted")
+		done = "Muted"
+	case promptAssign:
+		if m.input == "" {
+			m.status = "Cancelled"
+			return
+		}
+		userID, parseErr := strconv.ParseInt(m.input, 10, 64)
+		if parseErr != nil {
+			m.status = "Error: invalid user ID"
+			return
+		}
+		updated, err = m.backend.AssignItem(item.ID.Int64(), userID)
+		done = "Assigned"
+		if userID == 0 {
+			done = "Unassigned"
+		}
+	}
+
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	if updated != nil {
+		item.Status = updated.Status
+		item.AssignedUserID = updated.AssignedUserID
+	}
+	m.status = fmt.Sprintf("%s #%d", done, item.Counter)
+}
+
+func (m *Model) openContext() {
+	item := m.current()
+	occ := m.currentOccurrences()
+	if item == nil || occ == nil {
+		return
+	}
+
+	// Start the context at the occurrence being viewed
+	instances := occ.instances[occ.index:]
+	if len(instances) > contextOccurrences {
+		instances = instances[:contextOccurrences]
+	}
+
+	var buf bytes.Buffer
+	if err := (&output.MarkdownFormatter{}).FormatContext(&buf, item, instances); err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.lines = splitLines(buf.String())
+	m.scroll = 0
+	m.mode = modeContext
+}
+
+// View renders the model as exactly height lines, each at most width runes wide
+func (m *Model) View(width, height int) []string {
+	if width < 20 {
+		width = 20
+	}
+	if height < 8 {
+		height = 8
+	}
+
+	var lines []string
+	switch m.mode {
+	case modeContext, modeHelp:
+		lines = m.pagerView(height - 1)
+	default:
+		lines = m.listView(width, height-1)
+	}
+
+	lines = append(lines, m.statusLine())
+	for i := range lines {
+		lines[i] = fit(lines[i], width)
+	}
+	return lines
+}
+
+func (m *Model) listView(width, height int) []string {
+	lines := []string{m.header()}
+
+	// Split the screen between the item list and the detail pane
+	listHeight := (height - 2) * 2 / 5
+	if listHeight < 3 {
+		listHeight = 3
+	}
+	detailHeight := height - 2 - listHeight
+
+	if m.selected < m.offset {
+		m.offset = m.selected
+	}
+	if m.selected >= m.offset+listHeight {
+		m.offset = m.selected - listHeight + 1
+	}
+
+	for i := 0; i < listHeight; i++ {
+		idx := m.offset + i
+		switch {
+		case idx < len(m.items):
+			row := m.itemRow(&m.items[idx])
+			if idx == m.selected {
+				row = "\033[7m" + fit("> "+row, width) + "\033[0m"
+			} else {
+				row = "  " + row
+			}
+			lines = append(lines, row)
+		case idx == 0:
+			lines = append(lines, "  No items found.")
+		default:
+			lines = append(lines, "")
+		}
+	}
+
+	lines = append(lines, m.detailHeader(width))
+
+	detail := m.detailLines()
+	for i := 0; i < detailHeight; i++ {
+		if i < len(detail) {
+			lines = append(lines, detail[i])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	return lines
+}
+
+func (m *Model) header() string {
+	filters := []string{}
+	if m.opts.Status != "" {
+		filters = append(filters, "status="+m.opts.Status)
+	}
+	if m.opts.Level != "" {
+		filters = append(filters, "level="+m.opts.Level)
+	}
+	if m.opts.Environment != "" {
+		filters = append(filters, "env="+m.opts.Environment)
+	}
+	if m.opts.Query != "" {
+		filters = append(filters, "query="+m.opts.Query)
+	}
+	if !m.opts.DateFrom.IsZero() {
+		filters = append(filters, "from="+output.FormatTime(m.opts.DateFrom))
+	}
+	if !m.opts.DateTo.IsZero() {
+		filters = append(filters, "to="+output.FormatTime(m.opts.DateTo))
+	}
+	return fmt.Sprintf("\033[1mRollbar\033[0m  %s  page %d  %d items",
+		strings.Join(filters, " "), m.opts.Page, len(m.items))
+}
+
+func (m *Model) itemRow(item *api.Item) string {
+	return fmt.Sprintf("#%-6d %-8s %-8s %6d  %-8s %s",
+		item.Counter,
+		item.LevelString,
+		item.Status,
+		item.TotalOccurrences,
+		output.FormatCompactTime(item.LastOccurrenceTime),
+		item.Title,
+	)
+}
+
+func (m *Model) detailHeader(width int) string {
+	title := "Latest occurrence"
+	if occ := m.currentOccurrences(); occ != nil && len(occ.instances) > 0 {
+		more := ""
+		if !occ.exhausted {
+			more = "+"
+		}
+		title = fmt.Sprintf("Occurrence %d/%d%s (ID %d)",
+			occ.index+1, len(occ.instances), more, occ.instances[occ.index].ID)
+	}
+	line := "── " + title + " "
+	if n := width - term.StringWidth(line); n > 0 {
+		line += strings.Repeat("─", n)
+	}
+	return line
+}
+
+func (m *Model) detailLines() []string {
+	occ := m.currentOccurrences()
+	if occ == nil {
+		return nil
+	}
+	if len(occ.instances) == 0 {
+		return []string{"No occurrences found."}
+	}
+
+	var buf bytes.Buffer
+	inst := occ.instances[occ.index]
+	if err := (&output.CompactFormatter{}).FormatInstance(&buf, &inst); err != nil {
+		return []string{"Error: " + err.Error()}
+	}
+	return splitLines(buf.String())
+}
+
+func (m *Model) pagerView(height int) []string {
+	var lines []string
+	for i := 0; i < height; i++ {
+		idx := m.scroll + i
+		if idx < len(m.lines) {
+			lines = append(lines, m.lines[idx])
+		} else {
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
+
+func (m *Model) statusLine() string {
+	if m.mode == modePrompt {
+		return m.promptText()
+	}
+	if m.status != "" {
+		return m.status
+	}
+	if m.mode == modeContext || m.mode == modeHelp {
+		return "j/k scroll  space/pgdn page  q/esc back"
+	}
+	return "j/k move  n/p occurrence  c context  r resolve  m mute  a assign  [/] page  R refresh  ? help  q quit"
+}
+
+func helpLines() []string {
+	return []string{
+		"Keybindings",
+		"",
+		"  j, down       select next item",
+		"  k, up         select previous item",
+		"  pgdn, pgup    move ten items",
+		"  g, G          first / last item",
+		"  n, p          older / newer occurrence of the selected item",
+		"  ], [          next / previous page of items",
+		"  c, enter      open full context for the selected occurrence",
+		"  r             resolve the selected item",
+		"  m             mute the selected item",
+		"  a             assign the selected item to a user ID",
+		"  R             refresh the item list",
+		"  ?             show this help",
+		"  q             quit (or go back from a view)",
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	return strings.Split(strings.TrimRight(s, "\n"), "\n")
+}
+
+// fit truncates s to width display cells, ignoring ANSI escape sequences
+func fit(s string, width int) string {
+	var b strings.Builder
+	n := 0
+	inEscape := false
+	for _, r := range s {
+		if inEscape {
+			b.WriteRune(r)
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+			continue
+		}
+		if r == '\033' {
+			inEscape = true
+			b.WriteRune(r)
+			continue
+		}
+		rw := term.RuneWidth(r)
+		if n+rw > width {
+			n = width
+			continue
+		}
+		b.WriteRune(r)
+		n += rw
+	}
+	return b.String()
+}
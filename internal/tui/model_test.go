@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/output"
+)
+
+type fakeBackend struct {
+	items     []api.Item
+	instances map[int64][]api.Instance
+	updates   []string
+	listCalls int
+}
+
+func (b *fakeBackend) ListItems(opts api.ItemsOptions) ([]api.Item, int, error) {
+	b.listCalls++
+	if opts.Page > 1 {
+		return nil, opts.Page, nil
+	}
+	return b.items, 1, nil
+}
+
+func (b *fakeBackend) ListInstances(opts api.InstancesOptions) ([]api.Instance, error) {
+	all := b.instances[opts.ItemID]
+	// Two instances per page
+	start := (opts.Page - 1) * 2
+	if start >= len(all) {
+		return nil, nil
+	}
+	end := start + 2
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], nil
+}
+
+func (b *fakeBackend) UpdateItemStatus(id int64, status string) (*api.Item, error) {
+	b.updates = append(b.updates, fmt.Sprintf("%d:%s", id, status))
+	return &api.Item{ID: api.JSONInt64(id), Status: status}, nil
+}
+
+func (b *fakeBackend) AssignItem(id int64, userID int64) (*api.Item, error) {
+	b.updates = append(b.updates, fmt.Sprintf("%d:assign:%d", id, userID))
+	return &api.Item{ID: api.JSONInt64(id), Status: "active", AssignedUserID: userID}, nil
+}
+
+func newFakeBackend() *fakeBackend {
+	now := time.Now()
+	b := &fakeBackend{instances: map[int64][]api.Instance{}}
+	for i := 1; i <= 3; i++ {
+		b.items = append(b.items, api.Item{
+			ID:                 api.JSONInt64(i),
+			Counter:            100 + i,
+			Title:              fmt.Sprintf("Error number %d", i),
+			LevelString:        "error",
+			Status:             "active",
+			TotalOccurrences:   i * 10,
+			LastOccurrenceTime: now,
+		})
+		for j := 1; j <= 3; j++ {
+			b.instances[int64(i)] = append(b.instances[int64(i)], api.Instance{
+				ID:   int64(i*1000 + j),
+				Time: now,
+				Data: api.InstanceData{
+					Level: "error",
+					Body: api.Body{Trace: &api.Trace{
+						Exception: api.Exception{Class: "RuntimeError", Message: fmt.Sprintf("failure %d.%d", i, j)},
+					}},
+				},
+			})
+		}
+	}
+	return b
+}
+
+func press(m *Model, keys ...Key) {
+	for _, k := range keys {
+		m.HandleKey(k)
+	}
+}
+
+func TestModelNavigation(t *testing.T) {
+	b := newFakeBackend()
+	m := NewModel(b, api.ItemsOptions{Status: "active"})
+	if err := m.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	view := strings.Join(m.View(100, 30), "\n")
+	if !strings.Contains(view, "> #101") {
+		t.Errorf("expected first item to be selected, got:\n%s", view)
+	}
+	if !strings.Contains(view, "failure 1.1") {
+		t.Errorf("expected latest occurrence in detail pane, got:\n%s", view)
+	}
+
+	press(m, "j", "j", "j")
+	if got := m.current().Counter; got != 103 {
+		t.Errorf("expected selection to stop at #103, got #%d", got)
+	}
+
+	// Page through occurrences, loading the second page on demand
+	press(m, "n", "n")
+	view = strings.Join(m.View(100, 30), "\n")
+	if !strings.Contains(view, "failure 3.3") {
+		t.Errorf("expected third occurrence after paging, got:\n%s", view)
+	}
+	press(m, "n")
+	if m.status != "No older occurrences" {
+		t.Errorf("expected end-of-occurrences status, got %q", m.status)
+	}
+	press(m, "p")
+	if !strings.Contains(strings.Join(m.View(100, 30), "\n"), "failure 3.2") {
+		t.Error("expected previous occurrence after 'p'")
+	}
+}
+
+func TestModelActions(t *testing.T) {
+	b := newFakeBackend()
+	m := NewModel(b, api.ItemsOptions{})
+	if err := m.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Resolve requires confirmation
+	press(m, "r", "n")
+	if len(b.updates) != 0 {
+		t.Fatalf("expected no update after declining, got %v", b.updates)
+	}
+	press(m, "r", "y")
+	press(m, "j", "m", "y")
+	press(m, "a", "4", "2", KeyEnter)
+
+	want := []string{"1:resolved", "2:muted", "2:assign:42"}
+	if strings.Join(b.updates, ",") != strings.Join(want, ",") {
+		t.Errorf("expected updates %v, got %v", want, b.updates)
+	}
+	if m.items[0].Status != "resolved" {
+		t.Errorf("expected item status to be updated, got %q", m.items[0].Status)
+	}
+	if m.items[1].AssignedUserID != 42 {
+		t.Errorf("expected item to be assigned, got %d", m.items[1].AssignedUserID)
+	}
+
+	// Context view renders the markdown context and returns to the list
+	press(m, "c")
+	if m.mode != modeContext {
+		t.Fatal("expected context view")
+	}
+	if !strings.Contains(strings.Join(m.View(100, 30), "\n"), "# Bug Report") {
+		t.Error("expected markdown context in context view")
+	}
+	press(m, KeyEsc, "q")
+	if !m.Quit() {
+		t.Error("expected quit after 'q' in list view")
+	}
+}
+
+func TestViewFitsTerminal(t *testing.T) {
+	m := NewModel(newFakeBackend(), api.ItemsOptions{})
+	if err := m.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	lines := m.View(40, 12)
+	if len(lines) != 12 {
+		t.Errorf("expected 12 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		plain := fit(line, 1000)
+		for strings.Contains(plain, "\033[") {
+			i := strings.Index(plain, "\033[")
+			j := strings.IndexAny(plain[i:], "mKhlH")
+			plain = plain[:i] + plain[i+j+1:]
+		}
+		if n := len([]rune(plain)); n > 40 {
+			t.Errorf("line exceeds width (%d): %q", n, plain)
+		}
+	}
+}
+
+func TestItemRowTimeFormat(t *testing.T) {
+	defer output.SetTimeDisplay(time.Local, output.TimeRelative)
+	m := NewModel(newFakeBackend(), api.ItemsOptions{})
+	item := &api.Item{Counter: 7, Title: "Boom", LastOccurrenceTime: time.Now().Add(-3 * time.Hour)}
+
+	if row := m.itemRow(item); !strings.Contains(row, "3h ago") {
+		t.Errorf("expected a relative time, got %q", row)
+	}
+	if err := output.SetTimeDisplay(time.UTC, output.TimeUnix); err != nil {
+		t.Fatal(err)
+	}
+	if row, want := m.itemRow(item), fmt.Sprint(item.LastOccurrenceTime.Unix()); !strings.Contains(row, want) {
+		t.Errorf("expected %s with --time-format unix, got %q", want, row)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("j\x1b[Bq\r\x1b[5~\x7f\x03é"))
+	want := []Key{"j", KeyDown, "q", KeyEnter, KeyPageUp, KeyBackspace, KeyCtrlC, "é"}
+	if len(keys) != len(want) {
+		t.Fatalf("expected %v, got %v", want, keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("key %d: expected %q, got %q", i, want[i], keys[i])
+		}
+	}
+}
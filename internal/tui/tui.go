@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/robzolkos/rollbar-cli/internal/api"
+	"github.com/robzolkos/rollbar-cli/internal/term"
+)
+
+// Key is a single key press: either a printable character or one of the named keys
+type Key string
+
+// Named keys
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdn"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyEsc       Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyCtrlC     Key = "ctrl+c"
+)
+
+var escapeKeys = map[string]Key{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+}
+
+// parseKeys splits raw terminal input into key presses
+func parseKeys(buf []byte) []Key {
+	var keys []Key
+	for len(buf) > 0 {
+		if buf[0] == 0x1b {
+			matched := false
+			for seq, k := range escapeKeys {
+				if strings.HasPrefix(string(buf), seq) {
+					keys = append(keys, k)
+					buf = buf[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				// Lone escape, or a sequence we don't handle: drop the rest of it
+				keys = append(keys, KeyEsc)
+				if len(buf) > 1 && (buf[1] == '[' || buf[1] == 'O') {
+					return keys
+				}
+				buf = buf[1:]
+			}
+			continue
+		}
+
+		switch buf[0] {
+		case '\r', '\n':
+			keys = append(keys, KeyEnter)
+		case 0x7f, 0x08:
+			keys = append(keys, KeyBackspace)
+		case 0x03:
+			keys = append(keys, KeyCtrlC)
+		default:
+			r, size := utf8.DecodeRune(buf)
+			keys = append(keys, Key(string(r)))
+			buf = buf[size:]
+			continue
+		}
+		buf = buf[1:]
+	}
+	return keys
+}
+
+// Run starts the full-screen interface on the current terminal
+func Run(backend Backend, opts api.ItemsOptions) error {
+	if !term.IsTerminal(os.Stdin) || !term.IsTerminal(os.Stdout) {
+		return fmt.Errorf("tui requires an interactive terminal")
+	}
+
+	model := NewModel(backend, opts)
+	fmt.Fprint(os.Stderr, "Loading items...\n")
+	if err := model.Load(); err != nil {
+		return err
+	}
+
+	restore, err := term.MakeRaw(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("tui: %w", err)
+	}
+	defer restore()
+
+	out := bufio.NewWriter(os.Stdout)
+	// Alternate screen, hidden cursor
+	fmt.Fprint(out, "\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Fprint(out, "\033[?25h\033[?1049l")
+		out.Flush()
+	}()
+
+	buf := make([]byte, 64)
+	for !model.Quit() {
+		width, height := term.Size()
+		draw(out, model.View(width, height))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			model.HandleKey(k)
+			if model.Quit() {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func draw(out *bufio.Writer, lines []string) {
+	fmt.Fprint(out, "\033[H")
+	for i, line := range lines {
+		fmt.Fprint(out, line, "\033[0m\033[K")
+		if i < len(lines)-1 {
+			fmt.Fprint(out, "\r\n")
+		}
+	}
+	out.Flush()
+}